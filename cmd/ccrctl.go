@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"ccrctl/pkg/config"
 	"ccrctl/pkg/logger"
 	"ccrctl/pkg/migrate"
 	"os"
@@ -12,6 +13,28 @@ var (
 )
 
 func ccrctl() {
+	opts, ok := initMigrate()
+	if !ok {
+		return
+	}
+	exitCode := migrate.NewMigrator(opts).Run()
+	// 将退出码设置为全局变量，让 main 函数处理
+	setExitCode(exitCode)
+}
+
+// initMigrate 初始化日志并校验配置，失败时设置退出码并返回 false
+func initMigrate() (migrate.Options, bool) {
+	if err := config.ResolveSecrets(); err != nil {
+		logger.Logger.Errorf("解析凭证失败: %s", err)
+		setExitCode(1)
+		return migrate.Options{}, false
+	}
+	opts := migrate.OptionsFromConfig(config.Cfg)
+	if err := logger.Init(opts.Log); err != nil {
+		logger.Logger.Errorf("初始化日志失败: %v", err)
+		setExitCode(1)
+		return opts, false
+	}
 	st, err := os.Lstat(os.Args[0])
 	if err != nil {
 		logger.Logger.Errorf("os.Lastat error: %v", err)
@@ -22,9 +45,12 @@ func ccrctl() {
 	logger.Logger.Infof("|  Build Time  : %-40s |", BuildTime)
 	logger.Logger.Infof("|  Version     : %-39s |", Version)
 	logger.Logger.Infof("===========================================================")
-	exitCode := migrate.Run()
-	// 将退出码设置为全局变量，让 main 函数处理
-	setExitCode(exitCode)
+	if err := config.CheckConfig(); err != nil {
+		logger.Logger.Errorf("配置文件校验失败: %s", err)
+		setExitCode(1)
+		return opts, false
+	}
+	return opts, true
 }
 
 var globalExitCode int
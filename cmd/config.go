@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"ccrctl/pkg/config"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var redacted bool
+
+func init() {
+	configShowCmd.Flags().BoolVar(&redacted, "redacted", false, "hide tokens, passwords and private keys")
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "inspect ccrctl configuration",
+	Long:  `inspect ccrctl configuration`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "print the effective merged configuration",
+	Long: `print the effective configuration merged from defaults, config files (--config) and environment variables,
+use --redacted to hide tokens, passwords and private keys`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		content, err := config.EffectiveConfig(redacted)
+		if err != nil {
+			return err
+		}
+		if len(config.LoadedFiles) > 0 {
+			fmt.Printf("# config files: %s\n", strings.Join(config.LoadedFiles, ", "))
+		}
+		fmt.Print(content)
+		return nil
+	},
+}
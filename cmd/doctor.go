@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"ccrctl/pkg/config"
+	"ccrctl/pkg/doctor"
+	"ccrctl/pkg/logger"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorTimeout time.Duration
+
+func init() {
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", doctor.DefaultTimeout, "network reachability check timeout")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "run pre-flight checks before migration",
+	Long: `run pre-flight checks before migration: configuration, git / git-lfs versions,
+network reachability of source and CNB hosts, source token validity and scopes,
+CNB token and root organization, and disk space of the work dir against estimated repo sizes.
+exits with code 1 when any check fails`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.ResolveSecrets(); err != nil {
+			logger.Logger.Errorf("解析凭证失败: %s", err)
+			setExitCode(1)
+			return
+		}
+		opts := doctor.OptionsFromConfig(config.Cfg)
+		opts.Timeout = doctorTimeout
+		results := doctor.Run(opts)
+		doctor.Print(os.Stdout, results)
+		if doctor.Failed(results) {
+			setExitCode(1)
+		}
+	},
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"ccrctl/pkg/migrate"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "list repos to migrate without migrating",
+	Long: `list repos from the source platform and apply source.repo, source.include / source.exclude,
+repository metadata filters and repo-path.txt selection, then print whether each repo will be migrated, its CNB path
+and the rule that selected or excluded it. nothing is cloned or created`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, ok := initMigrate()
+		if !ok {
+			return
+		}
+		setExitCode(migrate.NewMigrator(opts).Plan(os.Stdout))
+	},
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/migrate"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	retryStatePath string
+	retryReasons   []string
+)
+
+func init() {
+	retryFailedCmd.Flags().StringVar(&retryStatePath, "state", "", "state file written by the last run (default is "+migrate.StateFileName+" in the log dir)")
+	retryFailedCmd.Flags().StringSliceVar(&retryReasons, "reason", nil,
+		"only retry repos failed with these reasons: auth(permission), not_found, network, size_limit, lfs, rejected, other")
+	rootCmd.AddCommand(retryFailedCmd)
+}
+
+var retryFailedCmd = &cobra.Command{
+	Use:   "retry-failed",
+	Short: "rerun only repos that failed in the last run",
+	Long: `rerun only repos that failed in the last run, read from the state file written after each run,
+without listing repos from the source platform again. the state file is updated with the new results,
+so retry-failed can be run repeatedly until all repos succeed`,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, reason := range retryReasons {
+			if !migrate.ValidReason(strings.TrimSpace(reason)) {
+				cmd.PrintErrf("invalid --reason: %s\n", reason)
+				setExitCode(1)
+				return
+			}
+		}
+		opts, ok := initMigrate()
+		if !ok {
+			return
+		}
+		m := migrate.NewMigrator(opts)
+		statePath := retryStatePath
+		if statePath == "" {
+			statePath = m.StateFilePath()
+		}
+		state, err := migrate.ReadState(statePath)
+		if err != nil {
+			logger.Logger.Errorf("%s", err)
+			setExitCode(1)
+			return
+		}
+		setExitCode(m.RunRetry(state, retryReasons))
+	},
+}
@@ -4,6 +4,8 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"ccrctl/pkg/config"
+	"fmt"
 	"github.com/spf13/cobra"
 	"os"
 )
@@ -15,6 +17,11 @@ Common actions for ccrctl:
 - ccrctl: migrate Source Code  to CNB
 - ccrctl version: print the version number of ccrctl
 - ccrctl init-config: generate config.yaml.default file
+- ccrctl config show: print the effective merged configuration
+- ccrctl doctor: run pre-flight checks before migration
+- ccrctl plan: list repos to migrate and the rules that selected them
+- ccrctl select: interactively select repos to migrate
+- ccrctl retry-failed: rerun only repos that failed in the last run
 `
 
 // rootCmd represents the base command when called without any subcommands
@@ -28,6 +35,17 @@ var rootCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		ccrctl()
 	},
+	// 执行子命令前加载 --config 指定的配置文件
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		files, err := cmd.Flags().GetStringSlice(config.ConfigFlagName)
+		if err != nil {
+			return err
+		}
+		if err := config.Load(files); err != nil {
+			return fmt.Errorf("failed to load config: %v", err)
+		}
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -49,7 +67,9 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.ccrctl.yaml)")
+	// 配置文件由 PersistentPreRunE 在执行子命令前加载
+	rootCmd.PersistentFlags().StringSlice(config.ConfigFlagName, nil,
+		"config files, merged in order, later files override earlier ones, environment variables take precedence (default is ./config.yaml if exists)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"ccrctl/pkg/migrate"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(selectCmd)
+}
+
+var selectCmd = &cobra.Command{
+	Use:   "select",
+	Short: "interactively select repos to migrate",
+	Long: `list repos from the source platform grouped by sub-group in an interactive terminal picker,
+with search, multi-select and select-by-group, and save the selection to ` + migrate.RepoPathFile + `.
+repos already listed in ` + migrate.RepoPathFile + ` are pre-selected. run the migration with
+migrate.allow_select_repos enabled to migrate only the selected repos`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, ok := initMigrate()
+		if !ok {
+			return
+		}
+		setExitCode(migrate.NewMigrator(opts).Select())
+	},
+}
@@ -1,7 +1,7 @@
 package aliyun
 
 import (
-	"ccrctl/pkg/config"
+	"ccrctl/pkg/source"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -33,6 +33,7 @@ type Repository struct {
 	UpdatedAt         string `json:"updatedAt"`
 	Visibility        string `json:"visibility"`
 	WebUrl            string `json:"webUrl"`
+	SshUrlToRepo      string `json:"sshUrlToRepo"`
 	Description string `json:"description"`
 }
 
@@ -49,9 +50,9 @@ type RepositoryListResponse struct {
 
 // GetRepositories 获取仓库列表
 // page: 页码，从1开始
-func GetRepositories(page int) ([]Repository, int, error) {
-	organizationID := config.Cfg.GetString("source.organizationId")
-	token := config.Cfg.GetString("source.token")
+func GetRepositories(opts source.Options, page int) ([]Repository, int, error) {
+	organizationID := opts.OrganizationID
+	token := opts.Token
 
 	url := fmt.Sprintf("%s/oapi/v1/codeup/organizations/%s/repositories?page=%d&perPage=%d",
 		AliyunEndpoint, organizationID, page, defaultPageSize)
@@ -93,12 +94,12 @@ func GetRepositories(page int) ([]Repository, int, error) {
 }
 
 // GetAllRepositories 获取所有仓库列表（自动处理分页）
-func GetAllRepositories() ([]Repository, error) {
+func GetAllRepositories(opts source.Options) ([]Repository, error) {
 	var allRepos []Repository
 	page := 1
 
 	for {
-		repos, totalPages, err := GetRepositories(page)
+		repos, totalPages, err := GetRepositories(opts, page)
 		if err != nil {
 			return nil, err
 		}
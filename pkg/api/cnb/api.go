@@ -2,16 +2,14 @@ package cnb
 
 import (
 	"ccrctl/pkg/http_client"
+	"ccrctl/pkg/source"
+	"ccrctl/pkg/util"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 )
 
-var (
-	c = http_client.NewCNBClient()
-)
-
 type Repos struct {
 	Id              string    `json:"id"`
 	Name            string    `json:"name"`
@@ -43,7 +41,29 @@ type Repos struct {
 	LastUpdateNickname   string      `json:"last_update_nickname"`
 }
 
-func GetUserRepoFetchPage(page int) (repos []Repos, totalRow, pageSize int, err error) {
+// newClient 使用 source.url、source.token 创建 CNB 源平台客户端
+func newClient(opts source.Options) *http_client.Client {
+	return http_client.NewCNBClient(util.ConvertToApiURL(opts.URL), opts.Token)
+}
+
+// GetCurrentUserName 获取 source.token 对应的用户名
+func GetCurrentUserName(opts source.Options) (string, error) {
+	c := newClient(opts)
+	resp, _, _, err := c.RequestV4(http.MethodGet, "/user", nil)
+	if err != nil {
+		return "", err
+	}
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err = c.Unmarshal(resp, &user); err != nil {
+		return "", err
+	}
+	return user.Username, nil
+}
+
+func GetUserRepoFetchPage(opts source.Options, page int) (repos []Repos, totalRow, pageSize int, err error) {
+	c := newClient(opts)
 	endpoint := fmt.Sprintf("/user/repos?page=%d&page_size=100&desc=false", page)
 	resp, header, _, err := c.RequestV4(http.MethodGet, endpoint, nil)
 	if err != nil {
@@ -64,10 +84,10 @@ func GetUserRepoFetchPage(page int) (repos []Repos, totalRow, pageSize int, err
 	return repos, totalRow, pageSize, nil
 }
 
-func GetUserRepos() (Repos []Repos, err error) {
+func GetUserRepos(opts source.Options) (Repos []Repos, err error) {
 	page := 1
 	for {
-		repos, totalRow, pageSize, err := GetUserRepoFetchPage(page)
+		repos, totalRow, pageSize, err := GetUserRepoFetchPage(opts, page)
 		if err != nil {
 			return nil, err
 		}
@@ -80,7 +100,8 @@ func GetUserRepos() (Repos []Repos, err error) {
 	return Repos, nil
 }
 
-func GetReposByGroupFetchPage(groupName string, page int) (repos []Repos, totalRow, pageSize int, err error) {
+func GetReposByGroupFetchPage(opts source.Options, groupName string, page int) (repos []Repos, totalRow, pageSize int, err error) {
+	c := newClient(opts)
 	endpoint := fmt.Sprintf("/%s/-/repos?page=%d&page_size=100&desc=false", groupName, page)
 	resp, header, _, err := c.RequestV4(http.MethodGet, endpoint, nil)
 	if err != nil {
@@ -101,11 +122,11 @@ func GetReposByGroupFetchPage(groupName string, page int) (repos []Repos, totalR
 	return repos, totalRow, pageSize, nil
 }
 
-func GetReposByGroup(group string) ([]Repos, error) {
+func GetReposByGroup(opts source.Options, group string) ([]Repos, error) {
 	var Repos []Repos
 	page := 1
 	for {
-		apiRepos, totalRow, pageSize, err := GetReposByGroupFetchPage(group, page)
+		apiRepos, totalRow, pageSize, err := GetReposByGroupFetchPage(opts, group, page)
 		if err != nil {
 			return nil, err
 		}
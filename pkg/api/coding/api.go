@@ -1,7 +1,6 @@
 package coding
 
 import (
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/http_client"
 	"ccrctl/pkg/logger"
 	"ccrctl/pkg/util"
@@ -24,18 +23,26 @@ const (
 )
 
 var (
-	SourceToken = config.Cfg.GetString("source.token")
-	SourceURL   = config.Cfg.GetString("source.url")
-	Projects    = config.Cfg.GetStringSlice("source.project")
-	Repos       = config.Cfg.GetStringSlice("source.repo")
-	c           = http_client.NewClient(SourceURL)
-	c2          = http_client.NewCodingClient()
+	// clients 按地址和 token 复用 OpenAPI 客户端，同一源平台的请求共享限流
+	clients sync.Map
 
 	// projectCache 项目元数据缓存,用于减少 GetProjectByName API 调用次数
 	// 键为项目名称(string),值为 Project 结构体
 	projectCache sync.Map
 )
 
+// getClient 获取指定地址和 token 的 OpenAPI 客户端
+func getClient(baseURL, token string) *http_client.Client {
+	key := baseURL + "\x00" + token
+	if c, ok := clients.Load(key); ok {
+		return c.(*http_client.Client)
+	}
+	c := http_client.NewClient(baseURL)
+	c.Token = token
+	actual, _ := clients.LoadOrStore(key, c)
+	return actual.(*http_client.Client)
+}
+
 type UserInfo struct {
 	Response struct {
 		RequestId string `json:"RequestId"`
@@ -214,6 +221,7 @@ type DescribeGitDepotResponse struct {
 }
 
 func GetDepotByRepoPath(url, token, repoPath string) (depot Depots, err error) {
+	c := getClient(url, token)
 
 	body := &DescribeGitDepotRequest{
 		Action:    "DescribeGitDepot",
@@ -236,6 +244,7 @@ func GetDepotByRepoPath(url, token, repoPath string) (depot Depots, err error) {
 }
 
 func GetCurrentUserName(url, token string) (userName string, err error) {
+	c := getClient(url, token)
 
 	body := &DescribeCodingCurrentUser{
 		Action: "DescribeCodingCurrentUser",
@@ -253,6 +262,7 @@ func GetCurrentUserName(url, token string) (userName string, err error) {
 }
 
 func GetProjectByName(url, token, projectName string) (project Project, err error) {
+	c := getClient(url, token)
 	// 先检查缓存
 	if cachedProject, ok := projectCache.Load(projectName); ok {
 		logger.Logger.Debugf("从缓存获取项目 %s 信息", projectName)
@@ -298,6 +308,7 @@ func GetProjectIdsByNames(projects []string, url, token string) (projectIds []in
 }
 
 func GetRepoByProjectIdFetchPage(url, token string, projectId, pageNumber int) (DepotInfo, error) {
+	c := getClient(url, token)
 
 	body := &DescribeProjectDepotInfoListRequest{
 		Action:     "DescribeProjectDepotInfoList",
@@ -318,6 +329,7 @@ func GetRepoByProjectIdFetchPage(url, token string, projectId, pageNumber int) (
 }
 
 func GetTeamRepoFetchPage(url, token string, pageNumber int) (DepotInfo, error) {
+	c := getClient(url, token)
 
 	body := &DescribeTeamDepotInfoListRequest{
 		Action:     "DescribeTeamDepotInfoList",
@@ -448,13 +460,14 @@ func GetDepotListByRepoPath(url, token string, repos []string) (depotList []Depo
 	return depotList, nil
 }
 
-func GetDepotList() ([]Depots, error) {
+// GetDepotList 获取仓库列表，configProjects 为 source.project 配置的项目，为空时获取团队下的所有仓库
+func GetDepotList(sourceURL, sourceToken string, configProjects []string) ([]Depots, error) {
 	logger.Logger.Infof("获取仓库列表中...")
 	var depotList []Depots
 	var err error
 
-	// 对 Projects 进行去重并输出日志
-	result := util.DeduplicateStringSlice(Projects)
+	// 对 source.project 进行去重并输出日志
+	result := util.DeduplicateStringSlice(configProjects)
 	projects := result.Deduplicated
 	if result.DuplicateCount > 0 {
 		// 输出去重日志
@@ -471,7 +484,7 @@ func GetDepotList() ([]Depots, error) {
 			}
 		}
 		logger.Logger.Infof("配置 source.project 去重完成：原始配置 %d 项，去重后 %d 项，移除重复项 %d 个",
-			len(Projects), len(projects), result.DuplicateCount)
+			len(configProjects), len(projects), result.DuplicateCount)
 	}
 
 	// 优化逻辑：根据 source.project 配置判断迁移维度
@@ -479,12 +492,12 @@ func GetDepotList() ([]Depots, error) {
 	if len(projects) > 0 && projects[0] != "" {
 		// 配置了 source.project，按项目维度获取
 		logger.Logger.Info("检测到 source.project 配置，按项目维度获取列表")
-		depotList, err = GetDepotListByProjectNames(SourceURL, SourceToken, projects)
+		depotList, err = GetDepotListByProjectNames(sourceURL, sourceToken, projects)
 	} else {
 		// 未配置 source.project，按团队维度获取所有仓库
 		// 如果配置了 source.repo，将在后续的 filterReposByConfigList 函数中进行过滤
 		logger.Logger.Info("未配置 source.project，按团队维度获取所有仓库")
-		depotList, err = GetDepotListByTeam(SourceURL, SourceToken)
+		depotList, err = GetDepotListByTeam(sourceURL, sourceToken)
 	}
 
 	if err != nil {
@@ -541,7 +554,7 @@ type GetReleasesListReq struct {
 	ShowResourceUrl bool   `json:"ShowResourceUrl"`
 }
 
-func GetReleasesFetchPage(repoID, page int) (GetReleasesListResp, error) {
+func GetReleasesFetchPage(url, token string, repoID, page int) (GetReleasesListResp, error) {
 	var getReleasesListResp GetReleasesListResp
 	body := GetReleasesListReq{
 		Action:          "DescribeGitReleases",
@@ -550,7 +563,8 @@ func GetReleasesFetchPage(repoID, page int) (GetReleasesListResp, error) {
 		PageSize:        100,
 		ShowResourceUrl: true,
 	}
-	resp, _, _, err := c2.RequestV4(http.MethodPost, "", body)
+	c := getClient(url+endpoint, token)
+	resp, _, _, err := c.RequestV4(http.MethodPost, "", body)
 	if err != nil {
 		return getReleasesListResp, err
 	}
@@ -566,11 +580,11 @@ func GetReleasesFetchPage(repoID, page int) (GetReleasesListResp, error) {
 	return getReleasesListResp, nil
 }
 
-func GetReleasesList(repoID int) ([]Releases, error) {
+func GetReleasesList(url, token string, repoID int) ([]Releases, error) {
 	var releases []Releases
 	page := 1
 	for {
-		resp, err := GetReleasesFetchPage(repoID, page)
+		resp, err := GetReleasesFetchPage(url, token, repoID, page)
 		if err != nil {
 			return releases, err
 		}
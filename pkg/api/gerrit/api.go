@@ -0,0 +1,143 @@
+package gerrit
+
+import (
+	"bytes"
+	"ccrctl/pkg/http_client"
+	"ccrctl/pkg/source"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// xssiPrefix Gerrit JSON 响应开头用于防止 XSSI 的前缀
+	xssiPrefix   = ")]}'"
+	listProjects = "/projects/?d&n=%d&S=%d"
+	listChanges  = "/changes/?q=%s&n=%d&S=%d"
+	getSSHInfo   = "/ssh_info"
+	pageSize     = 500
+	// DefaultSSHPort Gerrit 默认 SSH 端口
+	DefaultSSHPort = "29418"
+)
+
+// builtinProjects Gerrit 内置的权限及用户配置项目，不迁移
+var builtinProjects = map[string]bool{
+	"All-Projects": true,
+	"All-Users":    true,
+}
+
+// Project Gerrit 项目
+type Project struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+}
+
+// Change Gerrit 变更
+type Change struct {
+	Number      int    `json:"_number"`
+	Project     string `json:"project"`
+	Branch      string `json:"branch"`
+	Status      string `json:"status"`
+	MoreChanges bool   `json:"_more_changes"`
+}
+
+// endpointPrefix 配置了用户名时访问 /a/ 下需要认证的接口
+func endpointPrefix(opts source.Options) string {
+	if opts.Username != "" {
+		return "/a"
+	}
+	return ""
+}
+
+// newClient 使用 source.url、source.username、source.password 创建 Gerrit 客户端
+func newClient(opts source.Options) *http_client.Client {
+	return http_client.NewGerritClient(opts.URL, opts.Username, opts.Password)
+}
+
+// stripXSSI 去掉 Gerrit JSON 响应开头的 )]}' 前缀
+func stripXSSI(body []byte) []byte {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return bytes.TrimPrefix(body, []byte(xssiPrefix))
+}
+
+// get 请求 Gerrit REST 接口并解析响应
+func get(opts source.Options, endpoint string, v interface{}) error {
+	c := newClient(opts)
+	resp, respCode, err := c.GerritRequest(http.MethodGet, endpointPrefix(opts)+endpoint)
+	if err != nil {
+		return err
+	}
+	if respCode != http.StatusOK {
+		return fmt.Errorf("状态码 %d: %s", respCode, strings.TrimSpace(string(resp)))
+	}
+	return json.Unmarshal(stripXSSI(resp), v)
+}
+
+// GetProjects 获取所有项目，按名称排序，不包含 All-Projects、All-Users
+func GetProjects(opts source.Options) ([]Project, error) {
+	var projects []Project
+	for start := 0; ; start += pageSize {
+		page := make(map[string]Project)
+		if err := get(opts, fmt.Sprintf(listProjects, pageSize, start), &page); err != nil {
+			return nil, fmt.Errorf("获取项目列表失败: %v", err)
+		}
+		for name, project := range page {
+			if builtinProjects[name] {
+				continue
+			}
+			project.Name = name
+			projects = append(projects, project)
+		}
+		if len(page) < pageSize {
+			break
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+	return projects, nil
+}
+
+// GetOpenChanges 获取项目未合入的变更
+func GetOpenChanges(opts source.Options, project string) ([]Change, error) {
+	query := url.QueryEscape(fmt.Sprintf("project:\"%s\" status:open", project))
+	var changes []Change
+	for start := 0; ; {
+		var page []Change
+		if err := get(opts, fmt.Sprintf(listChanges, query, pageSize, start), &page); err != nil {
+			return nil, fmt.Errorf("获取 %s 未合入的变更失败: %v", project, err)
+		}
+		changes = append(changes, page...)
+		if len(page) == 0 || !page[len(page)-1].MoreChanges {
+			break
+		}
+		start += len(page)
+	}
+	return changes, nil
+}
+
+// GetSSHInfo 获取 Gerrit SSH 服务的主机及端口，/ssh_info 返回 "<主机> <端口>" 纯文本，未开启 SSH 时返回错误
+func GetSSHInfo(opts source.Options) (host, port string, err error) {
+	c := newClient(opts)
+	resp, respCode, err := c.GerritRequest(http.MethodGet, getSSHInfo)
+	if err != nil {
+		return "", "", err
+	}
+	if respCode != http.StatusOK {
+		return "", "", fmt.Errorf("获取 SSH 信息失败，状态码 %d", respCode)
+	}
+	fields := strings.Fields(string(resp))
+	if len(fields) != 2 {
+		return "", "", fmt.Errorf("Gerrit 未开启 SSH 服务")
+	}
+	if _, err := strconv.Atoi(fields[1]); err != nil {
+		return "", "", fmt.Errorf("SSH 端口 %s 格式错误", fields[1])
+	}
+	return fields[0], fields[1], nil
+}
@@ -0,0 +1,86 @@
+package gerrit
+
+import (
+	"ccrctl/pkg/source"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStripXSSI(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{body: ")]}'\n{\"a\":1}", want: "\n{\"a\":1}"},
+		{body: "\n)]}'\n[]", want: "\n[]"},
+		{body: "[]", want: "[]"},
+	}
+	for _, tt := range tests {
+		if got := string(stripXSSI([]byte(tt.body))); got != tt.want {
+			t.Errorf("stripXSSI(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestGerritAPI(t *testing.T) {
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte("alice:secret"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ssh_info" {
+			_, _ = w.Write([]byte("review.example.com 29418"))
+			return
+		}
+		if r.Header.Get("Authorization") != auth {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(")]}'\n"))
+		switch {
+		case r.URL.Path == "/a/projects/" && r.URL.Query().Has("d"):
+			_, _ = w.Write([]byte(`{"All-Projects":{"id":"All-Projects"},"All-Users":{"id":"All-Users"},"platform/build":{"id":"platform%2Fbuild","description":"构建脚本","state":"ACTIVE"},"tools":{"id":"tools","state":"READ_ONLY"}}`))
+		case r.URL.Path == "/a/changes/" && r.URL.Query().Get("q") == `project:"tools" status:open`:
+			if r.URL.Query().Get("S") == "0" {
+				_, _ = w.Write([]byte(`[{"_number":1,"project":"tools","status":"NEW"},{"_number":2,"project":"tools","status":"NEW","_more_changes":true}]`))
+			} else {
+				_, _ = w.Write([]byte(`[{"_number":3,"project":"tools","status":"NEW"}]`))
+			}
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	opts := source.Options{URL: server.URL, Username: "alice", Password: "secret"}
+	projects, err := GetProjects(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects[0].Name != "platform/build" || projects[0].Description != "构建脚本" || projects[1].Name != "tools" {
+		t.Errorf("GetProjects() = %+v", projects)
+	}
+
+	changes, err := GetOpenChanges(opts, "tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var numbers []string
+	for _, change := range changes {
+		numbers = append(numbers, fmt.Sprint(change.Number))
+	}
+	if got := strings.Join(numbers, ","); got != "1,2,3" {
+		t.Errorf("GetOpenChanges() = %s, want 1,2,3", got)
+	}
+
+	host, port, err := GetSSHInfo(opts)
+	if err != nil || host != "review.example.com" || port != "29418" {
+		t.Errorf("GetSSHInfo(opts) = %s, %s, %v", host, port, err)
+	}
+
+	opts.Password = "wrong"
+	if _, err := GetProjects(opts); err == nil {
+		t.Error("GetProjects() error = nil, want error for wrong password")
+	}
+}
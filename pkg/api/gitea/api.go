@@ -3,6 +3,7 @@ package gitea
 import (
 	"ccrctl/pkg/http_client"
 	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -11,14 +12,12 @@ import (
 )
 
 const (
-	apiPath     = "/api/v1"
-	getRepoList = "/user/repos"
-	getUser     = "/user"
-	getReleases = "/repos/%s/releases"
-)
-
-var (
-	c = http_client.NewGiteaClient()
+	apiPath      = "/api/v1"
+	getRepoList  = "/user/repos"
+	getOrgRepos  = "/orgs/%s/repos"
+	getUserRepos = "/users/%s/repos"
+	getUser      = "/user"
+	getReleases  = "/repos/%s/releases"
 )
 
 // Repo Gitea 仓库结构体
@@ -59,6 +58,7 @@ type Repo struct {
 	AllowSquashMerge          bool      `json:"allow_squash_merge"`
 	AvatarUrl                 string    `json:"avatar_url"`
 	Internal                  bool      `json:"internal"`
+	Archived                  bool      `json:"archived"`
 	MirrorInterval            string    `json:"mirror_interval"`
 	Size                      int       `json:"size"`
 	CreatedAt                 time.Time `json:"created_at"`
@@ -119,13 +119,24 @@ type ErrorResp struct {
 	Url     string `json:"url"`
 }
 
+// newClient 使用 source.url、source.token 创建 Gitea 客户端
+func newClient(opts source.Options) *http_client.Client {
+	return http_client.NewGiteaClient(opts.URL+"/api/v1", opts.Token)
+}
+
 // GetRepoListFetchPage 分页获取仓库列表
-func GetRepoListFetchPage(page string) ([]Repo, http.Header, error) {
+func GetRepoListFetchPage(opts source.Options, page string) ([]Repo, http.Header, error) {
+	return fetchRepoPage(opts, getRepoList, page)
+}
+
+// fetchRepoPage 获取仓库列表接口的一页
+func fetchRepoPage(opts source.Options, path, page string) ([]Repo, http.Header, error) {
+	c := newClient(opts)
 	queryParams := url.Values{}
 	queryParams.Add("page", page)
 	queryParams.Add("limit", "50") // Gitea 默认每页最多50个
 
-	endpoint := fmt.Sprintf("%s?%s", getRepoList, queryParams.Encode())
+	endpoint := fmt.Sprintf("%s?%s", path, queryParams.Encode())
 	resp, header, respCode, err := c.GiteaRequest(http.MethodGet, endpoint, nil)
 	if err != nil {
 		logger.Logger.Errorf("获取仓库列表失败: %v", err)
@@ -150,13 +161,50 @@ func GetRepoListFetchPage(page string) ([]Repo, http.Header, error) {
 	return repoList, header, nil
 }
 
-// GetRepoList 获取所有仓库列表
-func GetRepoList() ([]Repo, error) {
+// GetRepoList 获取所有仓库列表，配置了 source.organizations / source.users 时只获取这些组织及用户的仓库
+func GetRepoList(opts source.Options) ([]Repo, error) {
+	orgs, users := opts.Organizations, opts.Users
+	if len(orgs) == 0 && len(users) == 0 {
+		return fetchRepoList(opts, getRepoList)
+	}
+
+	var repoList []Repo
+	seen := make(map[string]bool)
+	for _, owner := range append(ownerPaths(getOrgRepos, orgs), ownerPaths(getUserRepos, users)...) {
+		list, err := fetchRepoList(opts, owner)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range list {
+			if !seen[repo.FullName] {
+				seen[repo.FullName] = true
+				repoList = append(repoList, repo)
+			}
+		}
+	}
+	return repoList, nil
+}
+
+// ownerPaths 生成组织或用户的仓库列表接口路径
+func ownerPaths(format string, owners []string) []string {
+	paths := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		paths = append(paths, fmt.Sprintf(format, url.PathEscape(owner)))
+	}
+	return paths
+}
+
+// fetchRepoList 逐页获取仓库列表接口的所有仓库，Gogs 不支持分页，一次返回所有仓库
+func fetchRepoList(opts source.Options, path string) ([]Repo, error) {
+	if Flavor(opts) == FlavorGogs {
+		list, _, err := fetchRepoPage(opts, path, "1")
+		return list, err
+	}
 	var repoList []Repo
 	page := 1
 
 	for {
-		list, _, err := GetRepoListFetchPage(strconv.Itoa(page))
+		list, _, err := fetchRepoPage(opts, path, strconv.Itoa(page))
 		if err != nil {
 			return nil, err
 		}
@@ -179,7 +227,8 @@ func GetRepoList() ([]Repo, error) {
 }
 
 // GetUserName 获取当前用户名
-func GetUserName() (string, error) {
+func GetUserName(opts source.Options) (string, error) {
+	c := newClient(opts)
 	resp, _, respCode, err := c.GiteaRequest(http.MethodGet, getUser, nil)
 	if err != nil {
 		logger.Logger.Errorf("获取用户信息失败: %v", err)
@@ -205,7 +254,8 @@ func GetUserName() (string, error) {
 }
 
 // GetReleasesFetchPage 分页获取 Release 列表
-func GetReleasesFetchPage(repoPath string, pageInt int) ([]Release, error) {
+func GetReleasesFetchPage(opts source.Options, repoPath string, pageInt int) ([]Release, error) {
+	c := newClient(opts)
 	page := strconv.Itoa(pageInt)
 	queryParams := url.Values{}
 	queryParams.Add("page", page)
@@ -241,13 +291,17 @@ func GetReleasesFetchPage(repoPath string, pageInt int) ([]Release, error) {
 	return releases, nil
 }
 
-// GetReleases 获取所有 Release 列表
-func GetReleases(repoPath string) ([]Release, error) {
+// GetReleases 获取所有 Release 列表，Gogs 不支持 Release，返回空列表
+func GetReleases(opts source.Options, repoPath string) ([]Release, error) {
+	if Flavor(opts) == FlavorGogs {
+		logger.Logger.Debugf("%s Gogs 不支持 Release，跳过", repoPath)
+		return nil, nil
+	}
 	page := 1
 	var releases []Release
 
 	for {
-		data, err := GetReleasesFetchPage(repoPath, page)
+		data, err := GetReleasesFetchPage(opts, repoPath, page)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,127 @@
+package gitea
+
+import (
+	"ccrctl/pkg/source"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+)
+
+func TestGetRepoListByOwners(t *testing.T) {
+	repos := map[string][]Repo{
+		"/api/v1/orgs/team/repos":   {{FullName: "team/api"}, {FullName: "team/web"}},
+		"/api/v1/users/alice/repos": {{FullName: "alice/tool"}, {FullName: "team/api"}},
+		"/api/v1/user/repos":        {{FullName: "other/repo"}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		list, ok := repos[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"not found"}`))
+			return
+		}
+		if r.URL.Query().Get("page") != "1" {
+			list = nil
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+	defer server.Close()
+
+	opts := source.Options{URL: server.URL, Organizations: []string{"team"}, Users: []string{"alice"}}
+	list, err := GetRepoList(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, repo := range list {
+		got = append(got, repo.FullName)
+	}
+	sort.Strings(got)
+	want := []string{"alice/tool", "team/api", "team/web"}
+	if len(got) != len(want) {
+		t.Fatalf("GetRepoList() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("GetRepoList() = %v, want %v", got, want)
+		}
+	}
+
+	opts.Users = []string{"nobody"}
+	if _, err := GetRepoList(opts); err == nil {
+		t.Error("GetRepoList() error = nil, want error for unknown user")
+	}
+}
+
+func TestDetectFlavor(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		status  int
+		want    string
+		wantErr bool
+	}{
+		{name: "gitea", version: "1.22.3", status: http.StatusOK, want: FlavorGitea},
+		{name: "forgejo", version: "7.0.5+gitea-1.22.0", status: http.StatusOK, want: FlavorForgejo},
+		// 版本接口 404 时无法确认为 Gogs，按 Gitea 处理
+		{name: "版本接口不存在", status: http.StatusNotFound, want: FlavorGitea, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/version" || tt.status != http.StatusOK {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(Version{Version: tt.version})
+			}))
+			defer server.Close()
+			opts := source.Options{Platform: FlavorGitea, URL: server.URL}
+
+			got, version, err := DetectFlavor(opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectFlavor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got != tt.want || version != tt.version) {
+				t.Errorf("DetectFlavor() = %s, %s, want %s, %s", got, version, tt.want, tt.version)
+			}
+			if flavor := Flavor(opts); flavor != tt.want {
+				t.Errorf("Flavor() = %s, want %s", flavor, tt.want)
+			}
+		})
+	}
+}
+
+func TestGogsWithoutPagination(t *testing.T) {
+	// Gogs 忽略分页参数，每次都返回所有仓库，也没有 Release 接口
+	var repos []Repo
+	for i := 0; i < 60; i++ {
+		repos = append(repos, Repo{FullName: fmt.Sprintf("team/repo-%d", i)})
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/v1/user/repos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(repos)
+	}))
+	defer server.Close()
+	opts := source.Options{Platform: FlavorGogs, URL: server.URL}
+
+	list, err := GetRepoList(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != len(repos) || requests != 1 {
+		t.Errorf("GetRepoList() = %d 个仓库, %d 次请求, want %d 个仓库, 1 次请求", len(list), requests, len(repos))
+	}
+	releases, err := GetReleases(opts, "team/repo-0")
+	if err != nil || len(releases) != 0 || requests != 1 {
+		t.Errorf("GetReleases() = %v, %v, %d 次请求", releases, err, requests)
+	}
+}
@@ -0,0 +1,78 @@
+package gitea
+
+import (
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// 兼容 Gitea API 的服务端类型，与 source.platform 取值相同
+// Forgejo 在 Gitea API 的基础上增加了联邦（federation）等字段，解析时忽略，其他行为与 Gitea 一致
+const (
+	FlavorGitea   = "gitea"
+	FlavorForgejo = "forgejo"
+	FlavorGogs    = "gogs"
+)
+
+const getVersion = "/version"
+
+// Version 版本接口响应，Forgejo 的版本号形如 7.0.5+gitea-1.22.0
+type Version struct {
+	Version string `json:"version"`
+}
+
+// flavors 按源平台地址缓存探测到的服务端类型
+var flavors sync.Map
+
+// Flavor 返回源平台的服务端类型，source.platform 为 forgejo、gogs 时直接使用，否则通过版本接口探测是否为 Forgejo，
+// 探测失败时按 Gitea 处理；Gogs 无法通过接口可靠识别，只在 source.platform 为 gogs 时使用
+func Flavor(opts source.Options) string {
+	switch opts.Platform {
+	case FlavorForgejo, FlavorGogs:
+		return opts.Platform
+	}
+	if flavor, ok := flavors.Load(opts.URL); ok {
+		return flavor.(string)
+	}
+	flavor, version, err := DetectFlavor(opts)
+	if err != nil {
+		logger.Logger.Warnf("探测源平台类型失败，按 Gitea 处理: %v", err)
+		flavor = FlavorGitea
+	} else if flavor != FlavorGitea {
+		logger.Logger.Infof("源平台为 %s %s，可将 source.platform 配置为 %s", flavor, version, flavor)
+	}
+	flavors.Store(opts.URL, flavor)
+	return flavor
+}
+
+// DetectFlavor 通过 /api/v1/version 探测服务端类型及版本，只能区分 Gitea 与 Forgejo
+// Gogs 没有版本接口，但版本接口返回 404 也可能是地址或反向代理配置错误，因此不判定为 Gogs，返回错误提示
+func DetectFlavor(opts source.Options) (flavor, version string, err error) {
+	c := newClient(opts)
+	resp, _, respCode, err := c.GiteaRequest(http.MethodGet, getVersion, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if respCode == http.StatusNotFound {
+		return "", "", fmt.Errorf("版本接口不存在，如果源平台为 Gogs 请将 source.platform 配置为 gogs")
+	}
+	if respCode != http.StatusOK {
+		return "", "", fmt.Errorf("获取版本失败，状态码 %d", respCode)
+	}
+	var v Version
+	if err := c.Unmarshal(resp, &v); err != nil {
+		return "", "", err
+	}
+	return flavorOfVersion(v.Version), v.Version, nil
+}
+
+func flavorOfVersion(version string) string {
+	lower := strings.ToLower(version)
+	if strings.Contains(lower, "+gitea-") || strings.Contains(lower, "forgejo") {
+		return FlavorForgejo
+	}
+	return FlavorGitea
+}
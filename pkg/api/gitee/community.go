@@ -1,27 +1,63 @@
 package gitee
 
 import (
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/http_client"
 	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
 const (
-	apiPath     = "/api/v5"
-	host        = "https://gitee.com"
-	getRepoList = "/user/repos"
-	getUser     = "/user"
-	getReleases = "/repos/%s/releases"
+	defaultAPIURL        = "https://gitee.com/api/v5"
+	getRepoList          = "/user/repos"
+	getOrgRepos          = "/orgs/%s/repos"
+	getUserRepos         = "/users/%s/repos"
+	getEnterpriseRepos   = "/enterprises/%s/repos"
+	getEnterpriseMembers = "/enterprises/%s/members"
+	getUser              = "/user"
+	getReleases          = "/repos/%s/releases"
 )
 
-var (
-	c = http_client.NewGiteeClient()
-)
+// APIURL 返回 Gitee API 地址，优先使用 source.api_url；source.url 为私有化部署的 Gitee 时为 <source.url>/api/v5，
+// 为 gitee.com 或企业版 e.gitee.com 时为 https://gitee.com/api/v5
+func APIURL(opts source.Options) string {
+	if apiURL := strings.TrimSpace(opts.APIURL); apiURL != "" {
+		return strings.TrimSuffix(apiURL, "/")
+	}
+	sourceURL := strings.TrimSuffix(strings.TrimSpace(opts.URL), "/")
+	u, err := url.Parse(sourceURL)
+	if err != nil || u.Hostname() == "" || isGiteeHost(u.Hostname()) {
+		return defaultAPIURL
+	}
+	return sourceURL + "/api/v5"
+}
+
+func isGiteeHost(host string) bool {
+	switch strings.ToLower(host) {
+	case "gitee.com", "www.gitee.com", "e.gitee.com":
+		return true
+	}
+	return false
+}
+
+// Program 企业版项目
+type Program struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Member 企业成员
+type Member struct {
+	Active bool   `json:"active"`
+	Role   string `json:"role"`
+	User   User   `json:"user"`
+}
 
 type Repo struct {
 	Id        int    `json:"id"`
@@ -137,7 +173,7 @@ type Repo struct {
 	Assignee            []interface{} `json:"assignee"`
 	Testers             []interface{} `json:"testers"`
 	Status              string        `json:"status"`
-	Programs            []interface{} `json:"programs"`
+	Programs            []Program     `json:"programs"`
 	Enterprise          interface{}   `json:"enterprise"`
 	ProjectLabels       []interface{} `json:"project_labels"`
 	IssueTemplateSource string        `json:"issue_template_source"`
@@ -179,15 +215,20 @@ type User struct {
 	Email             interface{} `json:"email"`
 }
 
-func GetRepoListFetchPage(page string) ([]Repo, http.Header, error) {
+func GetRepoListFetchPage(opts source.Options, page string) ([]Repo, http.Header, error) {
 	queryParams := url.Values{}
-	queryParams.Add("access_token", config.Cfg.GetString("source.token"))
 	queryParams.Add("affiliation", "admin")
 	queryParams.Add("sort", "full_name")
-	queryParams.Add("per_page", "100")
-	queryParams.Add("page", page)
-	endPoint := apiPath + getRepoList + "?" + queryParams.Encode()
-	c := http_client.NewClient(host)
+	return fetchRepoPage(opts, getRepoList, queryParams, page)
+}
+
+// fetchRepoPage 获取仓库列表接口的一页
+func fetchRepoPage(opts source.Options, path string, queryParams url.Values, page string) ([]Repo, http.Header, error) {
+	queryParams.Set("access_token", opts.Token)
+	queryParams.Set("per_page", "100")
+	queryParams.Set("page", page)
+	endPoint := path + "?" + queryParams.Encode()
+	c := http_client.NewClient(APIURL(opts))
 	resp, header, respCode, err := c.GiteeClient(http.MethodGet, endPoint, nil)
 	if err != nil {
 		logger.Logger.Error("Failed to get repo list", err)
@@ -209,11 +250,62 @@ func GetRepoListFetchPage(page string) ([]Repo, http.Header, error) {
 	return repoList, header, err
 }
 
-func GetRepoList() ([]Repo, error) {
+// GetRepoList 获取仓库列表，配置了 source.enterprise / source.organizations / source.users 时只获取该企业及这些组织、用户的仓库，
+// 否则获取当前用户有管理权限的仓库
+func GetRepoList(opts source.Options) ([]Repo, error) {
+	orgs, users := opts.Organizations, opts.Users
+	enterprise := strings.TrimSpace(opts.Enterprise)
+	if enterprise == "" && len(orgs) == 0 && len(users) == 0 {
+		return fetchRepoList(func(page string) ([]Repo, http.Header, error) {
+			return GetRepoListFetchPage(opts, page)
+		})
+	}
+	var repoList []Repo
+	seen := make(map[string]bool)
+	add := func(list []Repo) {
+		for _, repo := range list {
+			if !seen[repo.FullName] {
+				seen[repo.FullName] = true
+				repoList = append(repoList, repo)
+			}
+		}
+	}
+	if enterprise != "" {
+		list, err := fetchRepoList(func(page string) ([]Repo, http.Header, error) {
+			return fetchRepoPage(opts, fmt.Sprintf(getEnterpriseRepos, url.PathEscape(enterprise)), url.Values{"type": {"all"}}, page)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取企业 %s 的仓库列表失败: %v", enterprise, err)
+		}
+		add(list)
+	}
+	for _, org := range orgs {
+		list, err := fetchRepoList(func(page string) ([]Repo, http.Header, error) {
+			return fetchRepoPage(opts, fmt.Sprintf(getOrgRepos, url.PathEscape(org)), url.Values{"type": {"all"}}, page)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取组织 %s 的仓库列表失败: %v", org, err)
+		}
+		add(list)
+	}
+	for _, user := range users {
+		list, err := fetchRepoList(func(page string) ([]Repo, http.Header, error) {
+			return fetchRepoPage(opts, fmt.Sprintf(getUserRepos, url.PathEscape(user)), url.Values{"type": {"all"}}, page)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取用户 %s 的仓库列表失败: %v", user, err)
+		}
+		add(list)
+	}
+	return repoList, nil
+}
+
+// fetchRepoList 按 total_page 响应头逐页获取仓库列表
+func fetchRepoList(fetchPage func(page string) ([]Repo, http.Header, error)) ([]Repo, error) {
 	var repoList []Repo
 	page := 1 // 将page初始化为整数
 	for {
-		list, header, err := GetRepoListFetchPage(strconv.Itoa(page)) // 使用strconv.Itoa将整数转换为字符串
+		list, header, err := fetchPage(strconv.Itoa(page)) // 使用strconv.Itoa将整数转换为字符串
 		if err != nil {
 			return nil, err
 		}
@@ -229,11 +321,45 @@ func GetRepoList() ([]Repo, error) {
 	return repoList, nil
 }
 
-func GetUserName() (name string, err error) {
-	c := http_client.NewClient(host)
+// GetEnterpriseMembers 获取企业成员列表
+func GetEnterpriseMembers(opts source.Options, enterprise string) ([]Member, error) {
+	c := http_client.NewClient(APIURL(opts))
+	var members []Member
+	for page := 1; ; page++ {
+		queryParams := url.Values{}
+		queryParams.Add("access_token", opts.Token)
+		queryParams.Add("role", "all")
+		queryParams.Add("per_page", "100")
+		queryParams.Add("page", strconv.Itoa(page))
+		endPoint := fmt.Sprintf(getEnterpriseMembers, url.PathEscape(enterprise)) + "?" + queryParams.Encode()
+		resp, header, respCode, err := c.GiteeClient(http.MethodGet, endPoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		if respCode != http.StatusOK {
+			var e ErrorResp
+			if err := c.Unmarshal(resp, &e); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("获取企业 %s 成员列表失败: %s", enterprise, e.Message)
+		}
+		var list []Member
+		if err := c.Unmarshal(resp, &list); err != nil {
+			return nil, err
+		}
+		members = append(members, list...)
+		if len(list) == 0 || strconv.Itoa(page) == header.Get("total_page") {
+			break
+		}
+	}
+	return members, nil
+}
+
+func GetUserName(opts source.Options) (name string, err error) {
+	c := http_client.NewClient(APIURL(opts))
 	queryParams := url.Values{}
-	queryParams.Add("access_token", config.Cfg.GetString("source.token"))
-	endPoint := apiPath + getUser + "?" + queryParams.Encode()
+	queryParams.Add("access_token", opts.Token)
+	endPoint := getUser + "?" + queryParams.Encode()
 	resp, _, respCode, err := c.GiteeClient(http.MethodGet, endPoint, nil)
 	if err != nil {
 		logger.Logger.Error("Failed to get username", err)
@@ -289,7 +415,8 @@ type Release struct {
 	} `json:"assets"`
 }
 
-func GetReleasesFetchPage(repoPath string, pageInt int) ([]Release, string, error) {
+func GetReleasesFetchPage(opts source.Options, repoPath string, pageInt int) ([]Release, string, error) {
+	c := http_client.NewGiteeClient(APIURL(opts), opts.Token)
 	page := strconv.Itoa(pageInt)
 	queryParams := url.Values{}
 	queryParams.Add("per_page", "100")
@@ -323,11 +450,11 @@ func GetReleasesFetchPage(repoPath string, pageInt int) ([]Release, string, erro
 	return data, totalPage, nil
 }
 
-func GetReleases(repoPath string) ([]Release, error) {
+func GetReleases(opts source.Options, repoPath string) ([]Release, error) {
 	page := 1
 	releases := make([]Release, 0)
 	for {
-		data, totalPage, err := GetReleasesFetchPage(repoPath, page)
+		data, totalPage, err := GetReleasesFetchPage(opts, repoPath, page)
 		if err != nil {
 			return nil, err
 		}
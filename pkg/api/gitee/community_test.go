@@ -0,0 +1,82 @@
+package gitee
+
+import (
+	"ccrctl/pkg/source"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+)
+
+func TestAPIURL(t *testing.T) {
+	tests := []struct {
+		sourceURL string
+		apiURL    string
+		want      string
+	}{
+		{sourceURL: "https://gitee.com", want: "https://gitee.com/api/v5"},
+		{sourceURL: "https://e.gitee.com/", want: "https://gitee.com/api/v5"},
+		{sourceURL: "https://git.example.com/", want: "https://git.example.com/api/v5"},
+		{sourceURL: "https://git.example.com", apiURL: "https://api.example.com/v5/", want: "https://api.example.com/v5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sourceURL+tt.apiURL, func(t *testing.T) {
+			if got := APIURL(source.Options{URL: tt.sourceURL, APIURL: tt.apiURL}); got != tt.want {
+				t.Errorf("APIURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRepoListByEnterprise(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data interface{}
+		switch r.URL.Path {
+		case "/enterprises/ent/repos":
+			data = []Repo{{FullName: "ent/api", Programs: []Program{{Name: "backend"}}}, {FullName: "ent/web"}}
+		case "/enterprises/ent/members":
+			data = []Member{{Role: "admin", User: User{Login: "alice"}}}
+		case "/users/alice/repos":
+			data = []Repo{{FullName: "alice/tool"}, {FullName: "ent/api"}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"not found"}`))
+			return
+		}
+		w.Header().Set("total_page", "1")
+		_ = json.NewEncoder(w).Encode(data)
+	}))
+	defer server.Close()
+
+	opts := source.Options{APIURL: server.URL, Enterprise: "ent", Users: []string{"alice"}}
+	list, err := GetRepoList(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, repo := range list {
+		got = append(got, repo.FullName)
+	}
+	sort.Strings(got)
+	want := []string{"alice/tool", "ent/api", "ent/web"}
+	if len(got) != len(want) {
+		t.Fatalf("GetRepoList() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("GetRepoList() = %v, want %v", got, want)
+		}
+	}
+
+	members, err := GetEnterpriseMembers(opts, "ent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || members[0].User.Login != "alice" {
+		t.Errorf("GetEnterpriseMembers() = %+v", members)
+	}
+	if _, err := GetEnterpriseMembers(opts, "unknown"); err == nil {
+		t.Error("GetEnterpriseMembers() error = nil, want error for unknown enterprise")
+	}
+}
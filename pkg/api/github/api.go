@@ -1,12 +1,13 @@
 package github
 
 import (
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"sort"
 	"strings"
@@ -15,46 +16,93 @@ import (
 	"golang.org/x/oauth2"
 )
 
-var client *github.Client
+// defaultAPIURL github.com 的 API 地址
+const defaultAPIURL = "https://api.github.com/"
 
-func init() {
-	if config.Cfg.GetString("source.platform") == "github" {
-		// 创建一个上下文
-		ctx := context.Background()
+// newClient 使用 source.token 创建 GitHub 客户端，GitHub Enterprise Server 的 API 及附件上传均使用企业实例地址
+func newClient(opts source.Options) *github.Client {
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: opts.Token},
+	)
+	client := github.NewClient(oauth2.NewClient(context.Background(), ts))
+	if !IsEnterprise(opts) {
+		return client
+	}
+	apiURL := APIURL(opts)
+	enterpriseClient, err := client.WithEnterpriseURLs(apiURL, uploadURL(apiURL))
+	if err != nil {
+		logger.Logger.Fatalf("GitHub Enterprise Server 地址 %s 错误: %v", apiURL, err)
+	}
+	return enterpriseClient
+}
 
-		// 替换为你的GitHub访问令牌
-		token := config.Cfg.GetString("source.token")
+// APIURL 返回 source.url、source.api_url 对应的 GitHub API 地址，见 ResolveAPIURL
+func APIURL(opts source.Options) string {
+	return ResolveAPIURL(opts.URL, opts.APIURL)
+}
 
-		// 创建一个OAuth2客户端
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: token},
-		)
-		tc := oauth2.NewClient(ctx, ts)
+// ResolveAPIURL 返回 GitHub API 地址：优先使用 apiURL；sourceURL 不是 github.com 时视为 GitHub Enterprise Server，
+// API 地址为 <sourceURL>/api/v3/
+func ResolveAPIURL(sourceURL, apiURL string) string {
+	if apiURL = strings.TrimSpace(apiURL); apiURL != "" {
+		return strings.TrimSuffix(apiURL, "/") + "/"
+	}
+	sourceURL = strings.TrimSuffix(strings.TrimSpace(sourceURL), "/")
+	u, err := url.Parse(sourceURL)
+	if err != nil || u.Hostname() == "" || isGithubHost(u.Hostname()) {
+		return defaultAPIURL
+	}
+	return sourceURL + "/api/v3/"
+}
+
+// IsEnterprise 是否为 GitHub Enterprise Server
+func IsEnterprise(opts source.Options) bool {
+	u, err := url.Parse(APIURL(opts))
+	return err == nil && !isGithubHost(u.Hostname())
+}
 
-		// 创建一个GitHub客户端
-		client = github.NewClient(tc)
+func isGithubHost(host string) bool {
+	switch strings.ToLower(host) {
+	case "github.com", "www.github.com", "api.github.com":
+		return true
 	}
+	return false
 }
 
-func GetRepos() ([]*github.Repository, error) {
+// uploadURL 由 API 地址推导附件上传地址，如 https://ghe.example.com/api/v3/ → https://ghe.example.com/api/uploads/
+func uploadURL(apiURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(apiURL, "/"), "/api/v3") + "/api/uploads/"
+}
+
+// GetRepos 获取仓库列表，配置了 source.organizations / source.users 时只获取这些组织及用户的仓库，
+// 否则获取 source.token 可访问的所有仓库
+func GetRepos(opts source.Options) ([]*github.Repository, error) {
+	client := newClient(opts)
 	// 创建一个上下文
 	ctx := context.Background()
 
-	opt := &github.RepositoryListByAuthenticatedUserOptions{}
 	var allRepos []*github.Repository
-
-	for {
-		repos, resp, err := client.Repositories.ListByAuthenticatedUser(ctx, opt)
+	if len(opts.Organizations) > 0 || len(opts.Users) > 0 {
+		var err error
+		allRepos, err = listOwnerRepos(ctx, client, opts.Organizations, opts.Users)
 		if err != nil {
-			logger.Logger.Fatalf("Failed to list repositories: %v", err)
+			return nil, err
 		}
-		allRepos = append(allRepos, repos...)
-		if resp.NextPage == 0 {
-			break
+	} else {
+		opt := &github.RepositoryListByAuthenticatedUserOptions{}
+		for {
+			repos, resp, err := client.Repositories.ListByAuthenticatedUser(ctx, opt)
+			if err != nil {
+				logger.Logger.Fatalf("Failed to list repositories: %v", err)
+			}
+			allRepos = append(allRepos, repos...)
+			if resp.NextPage == 0 {
+				break
+			}
+			opt.Page = resp.NextPage
 		}
-		opt.Page = resp.NextPage
 	}
-	if config.Cfg.GetBool("migrate.exclude_github_fork") {
+	if opts.ExcludeGithubFork {
 		var filteredRepos []*github.Repository
 		for _, repo := range allRepos {
 			if !*repo.Fork {
@@ -66,7 +114,76 @@ func GetRepos() ([]*github.Repository, error) {
 	return allRepos, nil
 }
 
-func GetUserName() string {
+// listOwnerRepos 获取指定组织及用户的仓库，按仓库全名去重
+// 非成员的组织只能获取公开仓库；source.token 对应的用户可获取其私有仓库
+func listOwnerRepos(ctx context.Context, client *github.Client, orgs, users []string) ([]*github.Repository, error) {
+	var allRepos []*github.Repository
+	seen := make(map[string]bool)
+	add := func(repos []*github.Repository) {
+		for _, repo := range repos {
+			if !seen[repo.GetFullName()] {
+				seen[repo.GetFullName()] = true
+				allRepos = append(allRepos, repo)
+			}
+		}
+	}
+	for _, org := range orgs {
+		opt := &github.RepositoryListByOrgOptions{Type: "all", ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			repos, resp, err := client.Repositories.ListByOrg(ctx, org, opt)
+			if err != nil {
+				return nil, fmt.Errorf("获取组织 %s 的仓库列表失败: %v", org, err)
+			}
+			add(repos)
+			if resp.NextPage == 0 {
+				break
+			}
+			opt.Page = resp.NextPage
+		}
+	}
+	if len(users) == 0 {
+		return allRepos, nil
+	}
+	current, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("获取当前用户失败: %v", err)
+	}
+	login := current.GetLogin()
+	for _, user := range users {
+		// 其他用户的仓库接口只返回公开仓库，当前用户使用 ListByAuthenticatedUser 获取包括私有仓库在内的所有仓库
+		if strings.EqualFold(user, login) {
+			opt := &github.RepositoryListByAuthenticatedUserOptions{Affiliation: "owner", ListOptions: github.ListOptions{PerPage: 100}}
+			for {
+				repos, resp, err := client.Repositories.ListByAuthenticatedUser(ctx, opt)
+				if err != nil {
+					return nil, fmt.Errorf("获取用户 %s 的仓库列表失败: %v", user, err)
+				}
+				add(repos)
+				if resp.NextPage == 0 {
+					break
+				}
+				opt.Page = resp.NextPage
+			}
+			continue
+		}
+		opt := &github.RepositoryListByUserOptions{Type: "owner", ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			repos, resp, err := client.Repositories.ListByUser(ctx, user, opt)
+			if err != nil {
+				return nil, fmt.Errorf("获取用户 %s 的仓库列表失败: %v", user, err)
+			}
+			add(repos)
+			if resp.NextPage == 0 {
+				break
+			}
+			opt.Page = resp.NextPage
+		}
+	}
+	return allRepos, nil
+}
+
+func GetUserName(opts source.Options) string {
+	client := newClient(opts)
 	user, _, err := client.Users.Get(context.Background(), "")
 	if err != nil {
 		logger.Logger.Fatalf("Failed to get github user: %v", err)
@@ -74,16 +191,36 @@ func GetUserName() string {
 	return user.GetName()
 }
 
-func GetReleases(owner, repo string) ([]*github.RepositoryRelease, error) {
+// GetTokenScopes 获取 source.token 对应的用户名及 classic token 的授权范围
+// fine-grained token 不返回授权范围，此时 scopes 为 nil
+func GetTokenScopes(opts source.Options) (login string, scopes []string, err error) {
+	client := newClient(opts)
+	user, resp, err := client.Users.Get(context.Background(), "")
+	if err != nil {
+		return "", nil, err
+	}
+	if _, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		scopes = []string{}
+		for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return user.GetLogin(), scopes, nil
+}
+
+func GetReleases(opts source.Options, owner, repo string) ([]*github.RepositoryRelease, error) {
+	client := newClient(opts)
 	var allReleases []*github.RepositoryRelease
 	ctx := context.Background()
-	opts := &github.ListOptions{
+	listOpts := &github.ListOptions{
 		Page:    1,
 		PerPage: 100,
 	}
 
 	for {
-		releases, resp, err := client.Repositories.ListReleases(ctx, owner, repo, opts)
+		releases, resp, err := client.Repositories.ListReleases(ctx, owner, repo, listOpts)
 		if err != nil {
 			return nil, err
 		}
@@ -91,7 +228,7 @@ func GetReleases(owner, repo string) ([]*github.RepositoryRelease, error) {
 		if resp.NextPage == 0 {
 			break
 		}
-		opts.Page = resp.NextPage
+		listOpts.Page = resp.NextPage
 	}
 
 	sort.Slice(allReleases, func(i, j int) bool {
@@ -101,7 +238,8 @@ func GetReleases(owner, repo string) ([]*github.RepositoryRelease, error) {
 	return allReleases, nil
 }
 
-func DownloadReleaseAsset(owner, repo string, assetID int64) ([]byte, error) {
+func DownloadReleaseAsset(opts source.Options, owner, repo string, assetID int64) ([]byte, error) {
+	client := newClient(opts)
 	ctx := context.Background()
 	asset, _, err := client.Repositories.DownloadReleaseAsset(ctx, owner, repo, assetID, http.DefaultClient)
 	if err != nil {
@@ -116,7 +254,8 @@ type ReleaseAssetLink struct {
 	AssetID int64
 }
 
-func ExtractDownloadLinksFromRelease(owner, repo string, releaseID int64) ([]ReleaseAssetLink, error) {
+func ExtractDownloadLinksFromRelease(opts source.Options, owner, repo string, releaseID int64) ([]ReleaseAssetLink, error) {
+	client := newClient(opts)
 	ctx := context.Background()
 	assets, _, err := client.Repositories.ListReleaseAssets(ctx, owner, repo, releaseID, nil)
 	if err != nil {
@@ -150,7 +289,8 @@ func ExtractDownloadLinksFromRelease(owner, repo string, releaseID int64) ([]Rel
 }
 
 // ListUploads 获取 release 中的所有附件和图片
-func ListUploads(projectID string) (files map[string]int64, err error) {
+func ListUploads(opts source.Options, projectID string) (files map[string]int64, err error) {
+	client := newClient(opts)
 	parts := strings.Split(projectID, "/")
 	if len(parts) != 2 {
 		return nil, fmt.Errorf("无效的 projectID: %s", projectID)
@@ -159,7 +299,7 @@ func ListUploads(projectID string) (files map[string]int64, err error) {
 	repo := parts[1]
 
 	ctx := context.Background()
-	releases, err := GetReleases(owner, repo)
+	releases, err := GetReleases(opts, owner, repo)
 	if err != nil {
 		return nil, fmt.Errorf("获取 releases 失败: %v", err)
 	}
@@ -179,7 +319,7 @@ func ListUploads(projectID string) (files map[string]int64, err error) {
 }
 
 // DownloadFile 下载指定 ID 的文件
-func DownloadFile(projectID string, fileID int64) (data []byte, err error) {
+func DownloadFile(opts source.Options, projectID string, fileID int64) (data []byte, err error) {
 	parts := strings.Split(projectID, "/")
 	if len(parts) != 2 {
 		return nil, fmt.Errorf("无效的 projectID: %s", projectID)
@@ -187,5 +327,5 @@ func DownloadFile(projectID string, fileID int64) (data []byte, err error) {
 	owner := parts[0]
 	repo := parts[1]
 
-	return DownloadReleaseAsset(owner, repo, fileID)
+	return DownloadReleaseAsset(opts, owner, repo, fileID)
 }
@@ -0,0 +1,29 @@
+package github
+
+import "testing"
+
+func TestResolveAPIURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		sourceURL  string
+		apiURL     string
+		want       string
+		wantUpload string
+	}{
+		{name: "github.com", sourceURL: "https://github.com", want: "https://api.github.com/", wantUpload: "https://api.github.com/api/uploads/"},
+		{name: "未配置", want: "https://api.github.com/", wantUpload: "https://api.github.com/api/uploads/"},
+		{name: "GHES", sourceURL: "https://ghe.example.com/", want: "https://ghe.example.com/api/v3/", wantUpload: "https://ghe.example.com/api/uploads/"},
+		{name: "source.api_url", sourceURL: "https://ghe.example.com", apiURL: " https://api.ghe.example.com/api/v3 ", want: "https://api.ghe.example.com/api/v3/", wantUpload: "https://api.ghe.example.com/api/uploads/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveAPIURL(tt.sourceURL, tt.apiURL)
+			if got != tt.want {
+				t.Errorf("ResolveAPIURL() = %s, want %s", got, tt.want)
+			}
+			if upload := uploadURL(got); upload != tt.wantUpload {
+				t.Errorf("uploadURL() = %s, want %s", upload, tt.wantUpload)
+			}
+		})
+	}
+}
@@ -1,43 +1,56 @@
 package gitlab
 
 import (
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	gitlab "github.com/xanzy/go-gitlab"
 )
 
-var (
-	Git   *gitlab.Client
-	token = config.Cfg.GetString("source.token")
-	url   = config.Cfg.GetString("source.url")
-	owned = config.Cfg.GetBool("migrate.gitlab_projects_owned")
-)
+// APIURL 返回 Gitlab API 地址，优先使用 source.api_url，否则为 <source.url>/api/v4，支持部署在子路径下的实例，如 https://example.com/gitlab
+func APIURL(opts source.Options) string {
+	if apiURL := strings.TrimSpace(opts.APIURL); apiURL != "" {
+		return strings.TrimSuffix(apiURL, "/")
+	}
+	return strings.TrimSuffix(strings.TrimSpace(opts.URL), "/") + "/api/v4"
+}
 
-func init() {
-	var err error
-	Git, err = gitlab.NewClient(token, gitlab.WithBaseURL(url))
+// newClient 使用 source.url、source.api_url、source.token 创建 Gitlab 客户端
+func newClient(opts source.Options) (*gitlab.Client, error) {
+	client, err := gitlab.NewClient(opts.Token, gitlab.WithBaseURL(APIURL(opts)))
 	if err != nil {
-		logger.Logger.Fatalf("Failed to create Gitlab client: %v", err)
+		return nil, fmt.Errorf("failed to create Gitlab client: %v", err)
 	}
+	return client, nil
 }
 
-func GetProjects() ([]*gitlab.Project, error) {
+// GetProjects 获取项目列表，配置了 source.group 时只获取该群组及其子群组下的项目
+func GetProjects(opts source.Options) ([]*gitlab.Project, error) {
+	client, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	if group := strings.Trim(strings.TrimSpace(opts.Group), "/"); group != "" {
+		return getGroupProjects(client, group, opts.GitlabProjectsOwned)
+	}
 	var Projects []*gitlab.Project
 	page := 1
 	for {
-		projects, resp, err := Git.Projects.ListProjects(&gitlab.ListProjectsOptions{
+		projects, resp, err := client.Projects.ListProjects(&gitlab.ListProjectsOptions{
 			ListOptions: gitlab.ListOptions{
 				PerPage: 100,
 				Page:    page,
 			},
 			//仅限当前用户明确拥有的项目。
-			Owned: gitlab.Bool(owned),
+			Owned: gitlab.Bool(opts.GitlabProjectsOwned),
+			// 返回仓库大小，用于按仓库大小过滤，需要 Reporter 及以上权限，无权限时不返回
+			Statistics: gitlab.Bool(true),
 		})
 		if err != nil {
 			logger.Logger.Fatalf("Failed to get Projects: %v", err)
@@ -51,11 +64,67 @@ func GetProjects() ([]*gitlab.Project, error) {
 	return Projects, nil
 }
 
+// getGroupProjects 获取群组及其所有子群组下的项目，不包括共享到群组的其他项目
+func getGroupProjects(client *gitlab.Client, group string, owned bool) ([]*gitlab.Project, error) {
+	var projects []*gitlab.Project
+	opt := &gitlab.ListGroupProjectsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: 100,
+			Page:    1,
+		},
+		IncludeSubGroups: gitlab.Bool(true),
+		WithShared:       gitlab.Bool(false),
+		Owned:            gitlab.Bool(owned),
+	}
+	for {
+		list, resp, err := client.Groups.ListGroupProjects(group, opt)
+		if err != nil {
+			return nil, fmt.Errorf("获取群组 %s 的项目列表失败: %v", group, err)
+		}
+		projects = append(projects, list...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return projects, nil
+}
+
+// GetCurrentUserName 获取 source.token 对应的用户名
+func GetCurrentUserName(opts source.Options) (string, error) {
+	client, err := newClient(opts)
+	if err != nil {
+		return "", err
+	}
+	user, _, err := client.Users.CurrentUser()
+	if err != nil {
+		return "", err
+	}
+	return user.Username, nil
+}
+
+// GetTokenScopes 获取 source.token 的授权范围，需要 GitLab 15.5 及以上版本
+func GetTokenScopes(opts source.Options) ([]string, error) {
+	client, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	token, _, err := client.PersonalAccessTokens.GetSinglePersonalAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	return token.Scopes, nil
+}
+
 // GetRelease 获取指定项目的release
-func GetReleases(projectID int) (releases []*gitlab.Release, err error) {
+func GetReleases(opts source.Options, projectID int) (releases []*gitlab.Release, err error) {
+	client, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
 	page := 1
 	for {
-		release, resp, err := Git.Releases.ListReleases(projectID, &gitlab.ListReleasesOptions{
+		release, resp, err := client.Releases.ListReleases(projectID, &gitlab.ListReleasesOptions{
 			ListOptions: gitlab.ListOptions{
 				PerPage: 100,
 				Page:    page,
@@ -87,15 +156,15 @@ type ListUploadsRes struct {
 }
 
 // ListUploads https://docs.gitlab.com/ee/api/project_markdown_uploads.html
-func ListUploads(projectID string) (files map[string]int, err error) {
-	u := fmt.Sprintf("%s/api/v4/projects/%s/uploads", url, projectID)
+func ListUploads(opts source.Options, projectID string) (files map[string]int, err error) {
+	u := fmt.Sprintf("%s/projects/%s/uploads", APIURL(opts), projectID)
 	client := &http.Client{}
 	req, err := http.NewRequest(http.MethodGet, u, nil)
 
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("PRIVATE-TOKEN", token)
+	req.Header.Add("PRIVATE-TOKEN", opts.Token)
 
 	res, err := client.Do(req)
 	if err != nil {
@@ -120,15 +189,15 @@ func ListUploads(projectID string) (files map[string]int, err error) {
 	return files, nil
 }
 
-func DownloadFile(projectID string, fileID int) (data []byte, err error) {
-	u := fmt.Sprintf("%s/api/v4/projects/%s/uploads/%d", url, projectID, fileID)
+func DownloadFile(opts source.Options, projectID string, fileID int) (data []byte, err error) {
+	u := fmt.Sprintf("%s/projects/%s/uploads/%d", APIURL(opts), projectID, fileID)
 	client := &http.Client{}
 	req, err := http.NewRequest(http.MethodGet, u, nil)
 
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("PRIVATE-TOKEN", token)
+	req.Header.Add("PRIVATE-TOKEN", opts.Token)
 
 	res, err := client.Do(req)
 	if err != nil {
@@ -1,7 +1,7 @@
 package gongfeng
 
 import (
-	"ccrctl/pkg/config"
+	"ccrctl/pkg/source"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -27,6 +27,9 @@ type Project struct {
 	VisibilityLevel int    `json:"visibility_level"` // 0: 私有, 10: 内部公开
 	WebURL          string `json:"web_url"`
 	HTTPURL         string `json:"http_url_to_repo"`
+	SSHURL          string `json:"ssh_url_to_repo"`
+	Archived        bool   `json:"archived"`
+	LastActivityAt  string `json:"last_activity_at"`
 }
 
 // IsPrivate 判断项目是否为私有
@@ -36,10 +39,21 @@ func (p *Project) IsPrivate() bool {
 	return p.VisibilityLevel == VisibilityLevelPrivate || p.VisibilityLevel == VisibilityLevelInternal
 }
 
+// Visibility 项目可见性 private/internal/public
+func (p *Project) Visibility() string {
+	switch p.VisibilityLevel {
+	case VisibilityLevelPrivate:
+		return "private"
+	case VisibilityLevelInternal:
+		return "internal"
+	}
+	return "public"
+}
+
 // GetProjects 获取工蜂平台的所有项目列表
-func GetProjects() ([]Project, error) {
-	url := config.Cfg.GetString("source.url")
-	token := config.Cfg.GetString("source.token")
+func GetProjects(opts source.Options) ([]Project, error) {
+	url := opts.URL
+	token := opts.Token
 
 	var allProjects []Project
 	page := 1
@@ -1,9 +1,10 @@
 package huaweicloud
 
 import (
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
 	"fmt"
+	"sync"
 
 	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
 	codehub "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/codehub/v3"
@@ -14,21 +15,28 @@ import (
 	v4region "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/projectman/v4/region"
 )
 
+// clients、projectClients 按 AK、SK、区域复用华为云客户端，不同账号或区域使用各自的客户端
 var (
-	client        *codehub.CodeHubClient
-	projectClient *projectman.ProjectManClient
+	clients        sync.Map
+	projectClients sync.Map
 )
 
-// InitClient 初始化华为云CodeArts客户端
-func InitClient() error {
-	// 从配置获取认证信息
-	ak := config.Cfg.GetString("source.ak")
-	sk := config.Cfg.GetString("source.sk")
-	regionName := config.Cfg.GetString("source.region")
+// clientKey 客户端缓存键
+func clientKey(opts source.Options) string {
+	return opts.AK + "\x00" + opts.SK + "\x00" + opts.Region
+}
+
+// NewClient 返回 opts 对应的华为云CodeArts客户端，相同 AK、SK、区域复用同一客户端
+func NewClient(opts source.Options) (*codehub.CodeHubClient, error) {
+	key := clientKey(opts)
+	if c, ok := clients.Load(key); ok {
+		return c.(*codehub.CodeHubClient), nil
+	}
+	ak, sk, regionName := opts.AK, opts.SK, opts.Region
 
 	// 检查必需的配置
 	if ak == "" || sk == "" {
-		return fmt.Errorf("华为云AK/SK未配置")
+		return nil, fmt.Errorf("华为云AK/SK未配置")
 	}
 
 	// 创建认证信息（使用AK/SK）
@@ -38,27 +46,29 @@ func InitClient() error {
 	// 创建CodeHub客户端
 	regionValue, err := region.SafeValueOf(regionName)
 	if err != nil {
-		return fmt.Errorf("无效的区域名称: %w", err)
+		return nil, fmt.Errorf("无效的区域名称: %w", err)
 	}
 
-	client = codehub.NewCodeHubClient(
+	client := codehub.NewCodeHubClient(
 		codehub.CodeHubClientBuilder().
 			WithRegion(regionValue).
 			WithCredential(auth).
 			Build())
-
-	return nil
+	actual, _ := clients.LoadOrStore(key, client)
+	return actual.(*codehub.CodeHubClient), nil
 }
 
-func initProjectClient() error {
-	// 从配置获取认证信息
-	ak := config.Cfg.GetString("source.ak")
-	sk := config.Cfg.GetString("source.sk")
-	regionName := config.Cfg.GetString("source.region")
+// newProjectClient 返回 opts 对应的华为云项目管理客户端，相同 AK、SK、区域复用同一客户端
+func newProjectClient(opts source.Options) (*projectman.ProjectManClient, error) {
+	key := clientKey(opts)
+	if c, ok := projectClients.Load(key); ok {
+		return c.(*projectman.ProjectManClient), nil
+	}
+	ak, sk, regionName := opts.AK, opts.SK, opts.Region
 
 	// 检查必需的配置
 	if ak == "" || sk == "" {
-		return fmt.Errorf("华为云AK/SK未配置")
+		return nil, fmt.Errorf("华为云AK/SK未配置")
 	}
 
 	// 创建认证信息（使用AK/SK）
@@ -69,24 +79,23 @@ func initProjectClient() error {
 	// 创建Project客户端
 	regionValue, err := v4region.SafeValueOf(regionName)
 	if err != nil {
-		return fmt.Errorf("无效的区域名称: %w", err)
+		return nil, fmt.Errorf("无效的区域名称: %w", err)
 	}
 
-	projectClient = projectman.NewProjectManClient(
+	client := projectman.NewProjectManClient(
 		projectman.ProjectManClientBuilder().
 			WithRegion(regionValue).
 			WithCredential(auth).
 			Build())
-	return nil
+	actual, _ := projectClients.LoadOrStore(key, client)
+	return actual.(*projectman.ProjectManClient), nil
 }
 
 // GetRepositories 获取华为云CodeArts仓库列表（支持分页）
-func GetRepositories() ([]model.RepoInfoV2, error) {
-	if client == nil {
-		// 尝试初始化客户端
-		if err := InitClient(); err != nil {
-			return nil, fmt.Errorf("华为云客户端未初始化: %w", err)
-		}
+func GetRepositories(opts source.Options) ([]model.RepoInfoV2, error) {
+	client, err := NewClient(opts)
+	if err != nil {
+		return nil, fmt.Errorf("华为云客户端未初始化: %w", err)
 	}
 
 	var allRepositories []model.RepoInfoV2
@@ -145,13 +154,11 @@ func GetRepositories() ([]model.RepoInfoV2, error) {
 	return allRepositories, nil
 }
 
-func GetProjects() (map[string]string, error) {
+func GetProjects(opts source.Options) (map[string]string, error) {
 	projectsMap := make(map[string]string)
-	if projectClient == nil {
-		// 尝试初始化客户端
-		if err := initProjectClient(); err != nil {
-			return nil, fmt.Errorf("华为云客户端未初始化: %w", err)
-		}
+	projectClient, err := newProjectClient(opts)
+	if err != nil {
+		return nil, fmt.Errorf("华为云客户端未初始化: %w", err)
 	}
 
 	var projects []v4model.ListProjectsV4ResponseBodyProjects
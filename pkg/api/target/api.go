@@ -2,7 +2,6 @@ package target
 
 import (
 	"bytes"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/http_client"
 	"ccrctl/pkg/logger"
 	"ccrctl/pkg/util"
@@ -11,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"path"
@@ -29,24 +29,37 @@ const (
 	RepoDescLimitSize    = 350
 )
 
-var (
-	RootOrganizationName                    = config.Cfg.GetString("cnb.root_organization")
-	listRootSubOrganizationEndPoint         = "/" + RootOrganizationName + "/-/sub-groups?page=%s&page_size=" + PageSize
-	listSubOrganizationEndPoint             = "/" + RootOrganizationName + "/" + "%s" + "/-/sub-groups?page=%s&page_size=" + PageSize
-	createSubOrganizationEndPoint           = "/groups"
-	createRepoEndPoint                      = "/" + RootOrganizationName + "/%s/-/Repos"
-	createRepoUnderRootOrganizationEndPoint = "/" + RootOrganizationName + "/-/Repos"
-	listRepoEndPoint                        = createRepoEndPoint + "?page=%s&page_size=" + PageSize
-	getRepoInfoEndPoint                     = "/" + RootOrganizationName + "/%s"
-	c                                       = http_client.NewClientV2()
-	UploadImgEndPoint                       = "/%s/-/upload/imgs"
-	UploadFileEndPoint                      = "/%s/-/upload/files"
-	CnbURL                                  = config.Cfg.GetString("cnb.url")
-	CnbApiURL                               = config.ConvertToApiURL(CnbURL)
-	CnbToken                                = config.Cfg.GetString("cnb.token")
-	sourcePlatformURL                       = config.Cfg.GetString("source.url")
+const (
+	listRootSubOrganizationEndPoint = "/%s/-/sub-groups?page=%s&page_size=" + PageSize
+	listSubOrganizationEndPoint     = "/%s/%s/-/sub-groups?page=%s&page_size=" + PageSize
+	createSubOrganizationEndPoint   = "/groups"
+	UploadImgEndPoint               = "/%s/-/upload/imgs"
+	UploadFileEndPoint              = "/%s/-/upload/files"
 )
 
+// Client CNB OpenAPI 客户端，每个实例对应一个 CNB 地址、token 和根组织
+type Client struct {
+	URL              string
+	Token            string
+	RootOrganization string
+	SSHPort          string // SSH 端口，为空或 22 时推送地址形如 git@host:path
+	http             *http_client.Client
+}
+
+// NewClient 创建 CNB OpenAPI 客户端
+// 参数:
+//   - cnbURL: CNB 地址，如 https://cnb.cool
+//   - token: CNB 访问令牌
+//   - rootOrganization: 迁移目标根组织
+func NewClient(cnbURL, token, rootOrganization string) *Client {
+	return &Client{
+		URL:              cnbURL,
+		Token:            token,
+		RootOrganization: rootOrganization,
+		http:             http_client.NewClientV2(util.ConvertToApiURL(cnbURL), token),
+	}
+}
+
 type users struct {
 	Address          string `json:"address"`
 	AppreciateStatus int    `json:"appreciate_status"`
@@ -160,15 +173,16 @@ type CreateRepoBody struct {
 }
 
 // CreateSubOrganizationIfNotExists 创建子组织，如果不存在则创建（简化优化版本）
-func CreateSubOrganizationIfNotExists(url, token string, depotList []vcs.VCS) (err error) {
-	defer logger.Logger.Debugw(util.GetFunctionName(), "url", url, "token", token, "depotList", depotList)
+// subGroups 为目标仓库所属子组织，Name 为根组织下的路径
+func (c *Client) CreateSubOrganizationIfNotExists(subGroups []*vcs.SubGroup) (err error) {
+	defer logger.Logger.Debugw(util.GetFunctionName(), "url", c.URL, "subGroups", subGroups)
 
 	// 1. 收集所有需要创建的子组织路径并去重
-	uniqueSubGroups := collectUniqueSubGroups(depotList)
+	uniqueSubGroups := collectUniqueSubGroups(subGroups)
 	logger.Logger.Infof("预处理完成，发现 %d 个唯一子组织", len(uniqueSubGroups))
 
 	// 2. 一次性获取现有子组织列表
-	existingSubGroups, err := GetSubGroupsByRootGroup(url, token)
+	existingSubGroups, err := c.GetSubGroupsByRootGroup()
 	if err != nil {
 		return fmt.Errorf("获取子组织列表失败: %v", err)
 	}
@@ -183,15 +197,14 @@ func CreateSubOrganizationIfNotExists(url, token string, depotList []vcs.VCS) (e
 	logger.Logger.Infof("需要创建 %d 个新的子组织", len(toCreate))
 
 	// 4. 按层级深度顺序创建子组织
-	return createSubGroupsSequentially(url, token, toCreate)
+	return c.createSubGroupsSequentially(toCreate)
 }
 
 // collectUniqueSubGroups 收集所有需要创建的子组织路径并去重
-func collectUniqueSubGroups(depotList []vcs.VCS) map[string]*vcs.SubGroup {
+func collectUniqueSubGroups(subGroups []*vcs.SubGroup) map[string]*vcs.SubGroup {
 	uniqueSubGroups := make(map[string]*vcs.SubGroup)
 
-	for _, depot := range depotList {
-		subGroup := depot.GetSubGroup()
+	for _, subGroup := range subGroups {
 		subGroupName := subGroup.Name
 
 		// 如果子组织名称为空，跳过
@@ -245,7 +258,7 @@ func filterSubGroupsToCreate(uniqueSubGroups map[string]*vcs.SubGroup, existingS
 }
 
 // createSubGroupsSequentially 按层级深度顺序创建子组织
-func createSubGroupsSequentially(url, token string, toCreate map[string]*vcs.SubGroup) error {
+func (c *Client) createSubGroupsSequentially(toCreate map[string]*vcs.SubGroup) error {
 	// 按路径深度排序
 	paths := make([]string, 0, len(toCreate))
 	for path := range toCreate {
@@ -267,7 +280,7 @@ func createSubGroupsSequentially(url, token string, toCreate map[string]*vcs.Sub
 	for _, subGroupPath := range paths {
 		subGroup := toCreate[subGroupPath]
 
-		err := CreateSubOrganization(url, token, subGroupPath, *subGroup)
+		err := c.CreateSubOrganization(subGroupPath, *subGroup)
 		if err != nil {
 			// 如果是组织已存在的错误，继续处理下一个
 			if strings.Contains(err.Error(), "已存在") {
@@ -285,9 +298,9 @@ func createSubGroupsSequentially(url, token string, toCreate map[string]*vcs.Sub
 	return nil
 }
 
-func CreateSubOrganization(url, token, subGroupName string, subGroup vcs.SubGroup) (err error) {
+func (c *Client) CreateSubOrganization(subGroupName string, subGroup vcs.SubGroup) (err error) {
 	subGroupName = normalizeGroupName(subGroupName)
-	groupPath := path.Join(RootOrganizationName, subGroupName)
+	groupPath := path.Join(c.RootOrganization, subGroupName)
 	logger.Logger.Infof("开始创建子组织%s", groupPath)
 	if len(subGroup.Desc) > GroupDescLimitSize {
 		subGroup.Desc = subGroup.Desc[:GroupDescLimitSize]
@@ -300,7 +313,7 @@ func CreateSubOrganization(url, token, subGroupName string, subGroup vcs.SubGrou
 		Remark:      subGroup.Remark,
 		Description: subGroup.Desc,
 	}
-	resp, _, statusCode, err := c.RequestV3("POST", createSubOrganizationEndPoint, token, body)
+	resp, _, statusCode, err := c.http.RequestV3("POST", createSubOrganizationEndPoint, c.Token, body)
 
 	if err != nil {
 		return fmt.Errorf("创建子组织%s失败: %v", groupPath, err)
@@ -325,7 +338,7 @@ func CreateSubOrganization(url, token, subGroupName string, subGroup vcs.SubGrou
 	return fmt.Errorf("创建子组织%s失败: %s", groupPath, string(resp))
 }
 
-func CreateRepo(url, token, group, repoName, repoDesc string, private bool) (err error) {
+func (c *Client) CreateRepo(group, repoName, repoDesc string, private bool) (err error) {
 	var visibility string
 	endpoint := group + "/-/repos"
 	if private {
@@ -343,27 +356,27 @@ func CreateRepo(url, token, group, repoName, repoDesc string, private bool) (err
 		Visibility:  visibility,
 		Description: repoDesc,
 	}
-	_, err = c.Request("POST", endpoint, token, body)
+	_, err = c.http.Request("POST", endpoint, c.Token, body)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func GetCnbRepoPathAndGroup(subgroupName, repoName string, organizationMappingLevel int) (repoPath, repoGroup string) {
+func (c *Client) GetCnbRepoPathAndGroup(subgroupName, repoName string, organizationMappingLevel int) (repoPath, repoGroup string) {
 	switch organizationMappingLevel {
 	case 1:
 		// 处理当 subgroupName 为空时的情况
 		if subgroupName == "" {
-			repoPath = "/" + RootOrganizationName + "/" + repoName
-			repoGroup = "/" + RootOrganizationName
+			repoPath = "/" + c.RootOrganization + "/" + repoName
+			repoGroup = "/" + c.RootOrganization
 		} else {
-			repoPath = "/" + RootOrganizationName + "/" + subgroupName + "/" + repoName
-			repoGroup = "/" + RootOrganizationName + "/" + subgroupName
+			repoPath = "/" + c.RootOrganization + "/" + subgroupName + "/" + repoName
+			repoGroup = "/" + c.RootOrganization + "/" + subgroupName
 		}
 	case 2:
-		repoPath = "/" + RootOrganizationName + "/" + repoName
-		repoGroup = "/" + RootOrganizationName
+		repoPath = "/" + c.RootOrganization + "/" + repoName
+		repoGroup = "/" + c.RootOrganization
 	}
 	return repoPath, repoGroup
 }
@@ -377,9 +390,9 @@ func GetCnbRepoPathAndGroup(subgroupName, repoName string, organizationMappingLe
 //	return ok, nil
 //}
 
-func HasRepoV2(url, token, repoPath string) (has bool, err error) {
+func (c *Client) HasRepoV2(repoPath string) (has bool, err error) {
 	endpoint := repoPath
-	_, _, respStatusCode, err := c.RequestV3("GET", endpoint, token, nil)
+	_, _, respStatusCode, err := c.http.RequestV3("GET", endpoint, c.Token, nil)
 	if err != nil {
 		return false, fmt.Errorf("判断仓库是否存在失败: %v", err)
 	}
@@ -392,13 +405,13 @@ func HasRepoV2(url, token, repoPath string) (has bool, err error) {
 	return false, fmt.Errorf("判断仓库是否存在失败: 未知的状态码: %d", respStatusCode)
 }
 
-func GetSubGroupsByGroupFetchPage(url, token string, page int) (subGroups []subGroups, totalRow, pageSize int, err error) {
-	endpoint := fmt.Sprintf(listRootSubOrganizationEndPoint, strconv.Itoa(page))
-	resp, header, err := c.RequestV2("GET", endpoint, token, nil)
+func (c *Client) GetSubGroupsByGroupFetchPage(page int) (subGroups []subGroups, totalRow, pageSize int, err error) {
+	endpoint := fmt.Sprintf(listRootSubOrganizationEndPoint, c.RootOrganization, strconv.Itoa(page))
+	resp, header, err := c.http.RequestV2("GET", endpoint, c.Token, nil)
 	if err != nil {
 		return nil, 0, 0, err
 	}
-	err = c.Unmarshal(resp, &subGroups)
+	err = c.http.Unmarshal(resp, &subGroups)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -413,13 +426,13 @@ func GetSubGroupsByGroupFetchPage(url, token string, page int) (subGroups []subG
 	return subGroups, totalRow, pageSize, nil
 }
 
-func GetSubGroupsFetchPage(url, token, subGroupPath string, page int) (subGroups []subGroups, totalRow, pageSize int, err error) {
-	endpoint := fmt.Sprintf(listSubOrganizationEndPoint, subGroupPath, strconv.Itoa(page))
-	resp, header, err := c.RequestV2("GET", endpoint, token, nil)
+func (c *Client) GetSubGroupsFetchPage(subGroupPath string, page int) (subGroups []subGroups, totalRow, pageSize int, err error) {
+	endpoint := fmt.Sprintf(listSubOrganizationEndPoint, c.RootOrganization, subGroupPath, strconv.Itoa(page))
+	resp, header, err := c.http.RequestV2("GET", endpoint, c.Token, nil)
 	if err != nil {
 		return nil, 0, 0, err
 	}
-	err = c.Unmarshal(resp, &subGroups)
+	err = c.http.Unmarshal(resp, &subGroups)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -434,11 +447,11 @@ func GetSubGroupsFetchPage(url, token, subGroupPath string, page int) (subGroups
 	return subGroups, totalRow, pageSize, nil
 }
 
-func GetSubGroups(url, token, subGroupPath string) (Data map[string]bool, err error) {
+func (c *Client) GetSubGroups(subGroupPath string) (Data map[string]bool, err error) {
 	Data = make(map[string]bool)
 	page := 1
 	for {
-		apiSubGroups, totalRow, pageSize, err := GetSubGroupsFetchPage(url, token, subGroupPath, page)
+		apiSubGroups, totalRow, pageSize, err := c.GetSubGroupsFetchPage(subGroupPath, page)
 		if err != nil {
 			return nil, err
 		}
@@ -453,11 +466,11 @@ func GetSubGroups(url, token, subGroupPath string) (Data map[string]bool, err er
 	return Data, nil
 }
 
-func GetSubGroupsByRootGroup(url, token string) (Data map[string]bool, err error) {
+func (c *Client) GetSubGroupsByRootGroup() (Data map[string]bool, err error) {
 	Data = make(map[string]bool)
 	page := 1
 	for {
-		apiSubGroups, totalRow, pageSize, err := GetSubGroupsByGroupFetchPage(url, token, page)
+		apiSubGroups, totalRow, pageSize, err := c.GetSubGroupsByGroupFetchPage(page)
 		if err != nil {
 			return nil, err
 		}
@@ -472,33 +485,33 @@ func GetSubGroupsByRootGroup(url, token string) (Data map[string]bool, err error
 	return Data, nil
 }
 
-func CreateRootOrganizationIfNotExists(url, token string) (err error) {
-	defer logger.Logger.Debugw(util.GetFunctionName(), "url", url, "token", token)
-	endpoint := "/" + RootOrganizationName
-	_, _, respStatusCode, err := c.RequestV3("GET", endpoint, token, nil)
+func (c *Client) CreateRootOrganizationIfNotExists() (err error) {
+	defer logger.Logger.Debugw(util.GetFunctionName(), "url", c.URL)
+	endpoint := "/" + c.RootOrganization
+	_, _, respStatusCode, err := c.http.RequestV3("GET", endpoint, c.Token, nil)
 	if err != nil {
 		return fmt.Errorf("判断根组织是否存在失败%s", err)
 	}
 	if respStatusCode == 404 {
 		// 创建根组织
-		logger.Logger.Infof("根组织不存在:%s", RootOrganizationName)
-		err = CreateRootOrganization(url, token)
+		logger.Logger.Infof("根组织不存在:%s", c.RootOrganization)
+		err = c.CreateRootOrganization()
 		if err != nil {
 			return err
 		}
 		return nil
 	}
 	if respStatusCode == 200 {
-		logger.Logger.Infof("根组织%s已存在", RootOrganizationName)
+		logger.Logger.Infof("根组织%s已存在", c.RootOrganization)
 		return nil
 	}
 	return fmt.Errorf("判断根组织是否存在错误的状态码:%d", respStatusCode)
 }
 
-func RootOrganizationExists(url, token string) (exists bool, err error) {
-	defer logger.Logger.Debugw(util.GetFunctionName(), "url", url, "token", token)
-	endpoint := "/" + RootOrganizationName
-	body, _, respStatusCode, err := c.RequestV3("GET", endpoint, token, nil)
+func (c *Client) RootOrganizationExists() (exists bool, err error) {
+	defer logger.Logger.Debugw(util.GetFunctionName(), "url", c.URL)
+	endpoint := "/" + c.RootOrganization
+	body, _, respStatusCode, err := c.http.RequestV3("GET", endpoint, c.Token, nil)
 	if err != nil {
 		return false, err
 	}
@@ -511,32 +524,91 @@ func RootOrganizationExists(url, token string) (exists bool, err error) {
 	return false, fmt.Errorf("判断根组织是否存在错误的状态码:%d, 错误详情:%s", respStatusCode, string(body))
 }
 
-func CreateRootOrganization(url, token string) (err error) {
-	logger.Logger.Infof("开始创建根组织%s", RootOrganizationName)
-	path := RootOrganizationName
+// CurrentUserName 获取 CNB 令牌对应的用户名，用于校验令牌是否有效
+func (c *Client) CurrentUserName() (string, error) {
+	body, _, respStatusCode, err := c.http.RequestV3("GET", "/user", c.Token, nil)
+	if err != nil {
+		return "", err
+	}
+	if respStatusCode != 200 {
+		return "", fmt.Errorf("获取当前用户错误的状态码:%d, 错误详情:%s", respStatusCode, string(body))
+	}
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err = c.http.Unmarshal(body, &user); err != nil {
+		return "", err
+	}
+	return user.Username, nil
+}
+
+// RootOrganizationAccessRole 获取当前用户在根组织中的角色，如 Owner、Master、Developer，接口未返回时为空
+func (c *Client) RootOrganizationAccessRole() (string, error) {
+	body, _, respStatusCode, err := c.http.RequestV3("GET", "/"+c.RootOrganization, c.Token, nil)
+	if err != nil {
+		return "", err
+	}
+	if respStatusCode != 200 {
+		return "", fmt.Errorf("获取根组织信息错误的状态码:%d, 错误详情:%s", respStatusCode, string(body))
+	}
+	var organization struct {
+		AccessRole string `json:"access_role"`
+	}
+	if err = c.http.Unmarshal(body, &organization); err != nil {
+		return "", err
+	}
+	return organization.AccessRole, nil
+}
+
+func (c *Client) CreateRootOrganization() (err error) {
+	logger.Logger.Infof("开始创建根组织%s", c.RootOrganization)
+	path := c.RootOrganization
 	body := &CreateOrganization{
 		Path: path,
 	}
-	_, err = c.Request("POST", createSubOrganizationEndPoint, token, body)
+	_, err = c.http.Request("POST", createSubOrganizationEndPoint, c.Token, body)
 	if err != nil {
 		return fmt.Errorf("创建根组织失败%s", err)
 	}
-	logger.Logger.Infof("创建根组织%s成功", RootOrganizationName)
+	logger.Logger.Infof("创建根组织%s成功", c.RootOrganization)
 	return nil
 }
 
-func GetPushUrl(organizationMappingLevel int, cnbURL, userName, token, projectName, repoName string) string {
+func (c *Client) GetPushUrl(organizationMappingLevel int, userName, projectName, repoName string) string {
 	var pushURL string
-	u, _ := url.Parse(cnbURL)
+	u, _ := url.Parse(c.URL)
 	switch organizationMappingLevel {
 	case 1:
-		pushURL = fmt.Sprintf("%s://%s", u.Scheme, path.Join(fmt.Sprintf("%s:%s@%s", userName, token, u.Host), RootOrganizationName, projectName, repoName))
+		pushURL = fmt.Sprintf("%s://%s", u.Scheme, path.Join(fmt.Sprintf("%s:%s@%s", userName, c.Token, u.Host), c.RootOrganization, projectName, repoName))
 	case 2:
-		pushURL = fmt.Sprintf("%s://%s", u.Scheme, path.Join(fmt.Sprintf("%s:%s@%s", userName, token, u.Host), RootOrganizationName, repoName))
+		pushURL = fmt.Sprintf("%s://%s", u.Scheme, path.Join(fmt.Sprintf("%s:%s@%s", userName, c.Token, u.Host), c.RootOrganization, repoName))
 	}
 	return pushURL
 }
 
+// GetSSHPushUrl 获取 SSH 推送地址，如 git@cnb.cool:org/group/repo.git，配置了非 22 的 SSH 端口时形如 ssh://git@host:port/org/group/repo.git
+func (c *Client) GetSSHPushUrl(organizationMappingLevel int, projectName, repoName string) string {
+	u, _ := url.Parse(c.URL)
+	repoPath, _ := c.GetCnbRepoPathAndGroup(projectName, repoName, organizationMappingLevel)
+	repoPath = strings.TrimPrefix(repoPath, "/")
+	if c.SSHPort != "" && c.SSHPort != "22" {
+		return fmt.Sprintf("ssh://git@%s/%s.git", net.JoinHostPort(u.Hostname(), c.SSHPort), repoPath)
+	}
+	return fmt.Sprintf("git@%s:%s.git", u.Hostname(), repoPath)
+}
+
+// SSHHost 返回 CNB SSH 服务地址，配置了非 22 的 SSH 端口时为 host:port
+func (c *Client) SSHHost() string {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return ""
+	}
+	if c.SSHPort != "" && c.SSHPort != "22" {
+		return net.JoinHostPort(u.Hostname(), c.SSHPort)
+	}
+	return u.Hostname()
+}
+
 type CreateReleaseReq struct {
 	Body            string `json:"body"`
 	Draft           bool   `json:"draft"`
@@ -581,7 +653,7 @@ func normalizeRepoPath(repoPath string) string {
 	return strings.Trim(strings.TrimSpace(repoPath), "/")
 }
 
-func CreateRelease(targetRepoPath, sourceRepoPath, projectID string, release vcs.Releases, vcs vcs.VCS) (releaseID string, exist bool, err error) {
+func (c *Client) CreateRelease(targetRepoPath, sourceRepoPath, projectID string, release vcs.Releases, vcs vcs.VCS) (releaseID string, exist bool, err error) {
 	// 记录函数调用信息
 	defer logger.Logger.Debugw(util.GetFunctionName(),
 		"targetRepoPath", targetRepoPath,
@@ -606,7 +678,7 @@ func CreateRelease(targetRepoPath, sourceRepoPath, projectID string, release vcs
 	for _, attachment := range attachments {
 		attachment.RepoPath = normalizedTargetRepoPath
 		// 上传附件到CNB平台
-		path, err := UploadReleaseDescImgAndAttachments(attachment)
+		path, err := c.UploadReleaseDescImgAndAttachments(attachment)
 		if err != nil {
 			logger.Logger.Errorf("上传发布版本附件失败 [%s:%s]: %v", sourceRepoPath, release.Name, err)
 			return "", false, fmt.Errorf("上传发布版本附件失败: %w", err)
@@ -625,7 +697,7 @@ func CreateRelease(targetRepoPath, sourceRepoPath, projectID string, release vcs
 	}
 
 	// 发送创建发布版本的请求
-	res, _, statusCode, err := c.RequestV4(http.MethodPost, endpoint, body)
+	res, _, statusCode, err := c.http.RequestV4(http.MethodPost, endpoint, body)
 	if err != nil {
 		if statusCode == http.StatusConflict {
 			logger.Logger.Warnf("发布版本已存在 [%s:%s]", sourceRepoPath, release.Name)
@@ -673,13 +745,13 @@ type UploadImgsReq struct {
 	Size int    `json:"size"`
 }
 
-func UploadReleaseDescImgAndAttachments(attachment vcs.Attachment) (path string, err error) {
-	res, err := GetCosUploadUrlAndForm(attachment)
+func (c *Client) UploadReleaseDescImgAndAttachments(attachment vcs.Attachment) (path string, err error) {
+	res, err := c.GetCosUploadUrlAndForm(attachment)
 	if err != nil {
 		logger.Logger.Errorf("Get cos  upload form error: %v", err)
 		return "", err
 	}
-	err = UploadFileToCos(res.UploadUrl, res.Form, attachment)
+	err = c.UploadFileToCos(res.UploadUrl, res.Form, attachment)
 	if err != nil {
 		logger.Logger.Errorf("Upload file to cos error: %v", err)
 		return "", err
@@ -687,30 +759,30 @@ func UploadReleaseDescImgAndAttachments(attachment vcs.Attachment) (path string,
 	return res.Assets.Path, nil
 }
 
-func UploadReleaseAsset(repoPath, releaseID, assetName string, data []byte) (err error) {
+func (c *Client) UploadReleaseAsset(repoPath, releaseID, assetName string, data []byte) (err error) {
 	if len(data) > ReleaseAssetMaxSize {
 		logger.Logger.Warnf("%s附件大小超过5GiB，跳过上传", assetName)
 		return nil
 	}
-	uploadURL, err := GetReleaseAssetUploadUrl(repoPath, releaseID, assetName, len(data))
+	uploadURL, err := c.GetReleaseAssetUploadUrl(repoPath, releaseID, assetName, len(data))
 	if err != nil {
 		logger.Logger.Errorf("Get upload url error: %v", err)
 		return err
 	}
-	err = c.UploadData(uploadURL.UploadUrl, data)
+	err = c.http.UploadData(uploadURL.UploadUrl, data)
 	if err != nil {
 		logger.Logger.Errorf("Upload data error: %v", err)
 		return err
 	}
-	err = ConfirmUpload(uploadURL.VerifyUrl)
+	err = c.ConfirmUpload(uploadURL.VerifyUrl)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func ConfirmUpload(verifyUrl string) (err error) {
-	_, _, _, err = c.RequestWithURL(http.MethodPost, verifyUrl, nil)
+func (c *Client) ConfirmUpload(verifyUrl string) (err error) {
+	_, _, _, err = c.http.RequestWithURL(http.MethodPost, verifyUrl, nil)
 	if err != nil {
 		logger.Logger.Errorf("Confirm  upload error: %v", err)
 		return err
@@ -729,13 +801,13 @@ type GetReleaseUploadUrlReq struct {
 	Size      int    `json:"size"`
 }
 
-func GetReleaseAssetUploadUrl(repoPath, releaseID, assetName string, size int) (uploadURL UploadUrl, err error) {
+func (c *Client) GetReleaseAssetUploadUrl(repoPath, releaseID, assetName string, size int) (uploadURL UploadUrl, err error) {
 	reqPath := fmt.Sprintf("/%s/-/releases/%s/asset-upload-url", normalizeRepoPath(repoPath), releaseID)
 	body := &GetReleaseUploadUrlReq{
 		AssetName: assetName,
 		Size:      size,
 	}
-	res, _, _, err := c.RequestV4(http.MethodPost, reqPath, body)
+	res, _, _, err := c.http.RequestV4(http.MethodPost, reqPath, body)
 	if err != nil {
 		logger.Logger.Errorf("Get upload url error: %v", err)
 		return uploadURL, err
@@ -748,7 +820,7 @@ func GetReleaseAssetUploadUrl(repoPath, releaseID, assetName string, size int) (
 	return uploadURL, nil
 }
 
-func UploadFileToCos(reqUrl string, form UploadImgForm, attachment vcs.Attachment) (err error) {
+func (c *Client) UploadFileToCos(reqUrl string, form UploadImgForm, attachment vcs.Attachment) (err error) {
 	// 创建一个缓冲区以写入我们的表单数据
 	var b bytes.Buffer
 	w := multipart.NewWriter(&b)
@@ -781,16 +853,16 @@ func UploadFileToCos(reqUrl string, form UploadImgForm, attachment vcs.Attachmen
 		return err
 	}
 
-	_, err = c.SendUploadRequest(reqUrl, w.FormDataContentType(), &b)
+	_, err = c.http.SendUploadRequest(reqUrl, w.FormDataContentType(), &b)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func PlatformConfirmUpload(repoPath, token string) (err error) {
-	reqPath := fmt.Sprintf("%s?token=%s", repoPath, token)
-	_, _, _, err = c.RequestV4(http.MethodPut, reqPath, nil)
+func (c *Client) PlatformConfirmUpload(repoPath, uploadToken string) (err error) {
+	reqPath := fmt.Sprintf("%s?token=%s", repoPath, uploadToken)
+	_, _, _, err = c.http.RequestV4(http.MethodPut, reqPath, nil)
 	if err != nil {
 		logger.Logger.Errorf("Confirm  upload error: %v", err)
 		return err
@@ -798,11 +870,11 @@ func PlatformConfirmUpload(repoPath, token string) (err error) {
 	return nil
 }
 
-func GetCosUploadUrlAndForm(attachment vcs.Attachment) (form UploadImgOrFileRes, err error) {
+func (c *Client) GetCosUploadUrlAndForm(attachment vcs.Attachment) (form UploadImgOrFileRes, err error) {
 	var reqPath string
 	repoPath := normalizeRepoPath(attachment.RepoPath)
-	if !strings.HasPrefix(repoPath, RootOrganizationName+"/") {
-		repoPath = normalizeRepoPath(path.Join(RootOrganizationName, repoPath))
+	if !strings.HasPrefix(repoPath, c.RootOrganization+"/") {
+		repoPath = normalizeRepoPath(path.Join(c.RootOrganization, repoPath))
 	}
 	if attachment.Type == "img" {
 		reqPath = fmt.Sprintf(UploadImgEndPoint, repoPath)
@@ -814,7 +886,7 @@ func GetCosUploadUrlAndForm(attachment vcs.Attachment) (form UploadImgOrFileRes,
 		Name: attachment.Name,
 		Size: attachment.Size,
 	}
-	res, _, _, err := c.RequestV4(http.MethodPost, reqPath, body)
+	res, _, _, err := c.http.RequestV4(http.MethodPost, reqPath, body)
 	if err != nil {
 		logger.Logger.Errorf("Get upload url and form error: %v", err)
 		return form, err
@@ -870,6 +942,25 @@ func normalizeGroupName(input string) string {
 	return result
 }
 
+// CheckRepoPath 检查根组织下的目标仓库路径是否符合 CNB 命名规则，各级名称经 normalizeGroupName 规范化后应保持不变，
+// 且只能包含字母、数字、点(.)、下划线(_)和连字符(-)
+func CheckRepoPath(repoPath string) error {
+	if repoPath == "" {
+		return fmt.Errorf("目标仓库路径不能为空")
+	}
+	for _, name := range strings.Split(repoPath, "/") {
+		if name == "" || normalizeGroupName(name) != name {
+			return fmt.Errorf("目标仓库路径 %s 中的 %q 不符合 CNB 命名规则：只能以字母或数字开头和结尾，长度1-50个字符，不能以.git或.svn结尾", repoPath, name)
+		}
+		for _, r := range name {
+			if !isAlphanumeric(r) && r != '.' && r != '_' && r != '-' {
+				return fmt.Errorf("目标仓库路径 %s 中的 %q 不符合 CNB 命名规则：只能包含字母、数字、点(.)、下划线(_)和连字符(-)", repoPath, name)
+			}
+		}
+	}
+	return nil
+}
+
 // isAlphanumeric 检查字符是否为字母或数字
 func isAlphanumeric(r rune) bool {
 	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
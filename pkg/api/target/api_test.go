@@ -197,6 +197,31 @@ func TestNormalizeGroupName(t *testing.T) {
 	}
 }
 
+func TestCheckRepoPath(t *testing.T) {
+	tests := []struct {
+		repoPath string
+		wantErr  bool
+	}{
+		{repoPath: "team/backend/api", wantErr: false},
+		{repoPath: "api.v2_x-1", wantErr: false},
+		{repoPath: "", wantErr: true},
+		{repoPath: "team//api", wantErr: true},
+		{repoPath: "team/-api", wantErr: true},
+		{repoPath: "team/api.git", wantErr: true},
+		{repoPath: "team/../api", wantErr: true},
+		{repoPath: "team/my api", wantErr: true},
+		{repoPath: "团队/api", wantErr: true},
+		{repoPath: strings.Repeat("a", 51), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repoPath, func(t *testing.T) {
+			if err := CheckRepoPath(tt.repoPath); (err != nil) != tt.wantErr {
+				t.Errorf("CheckRepoPath(%q) error = %v, wantErr %v", tt.repoPath, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // TestCreateSubOrganization 测试完整的CreateSubOrganization函数
 // 注意：这是一个集成测试，需要模拟HTTP请求，这里只是示例框架
 func TestCreateSubOrganization(t *testing.T) {
@@ -230,7 +255,7 @@ func TestCreateSubOrganization(t *testing.T) {
 
 		for _, tc := range testCases {
 			t.Run(tc.name, func(t *testing.T) {
-				err := CreateSubOrganization(tc.url, tc.token, tc.subGroupName, tc.subGroup)
+				err := NewClient(tc.url, tc.token, "org").CreateSubOrganization(tc.subGroupName, tc.subGroup)
 				if tc.expectedErr && err == nil {
 					t.Error("期望错误但没有发生")
 				}
@@ -241,3 +266,33 @@ func TestCreateSubOrganization(t *testing.T) {
 		}
 	*/
 }
+
+func TestGetSSHPushUrl(t *testing.T) {
+	client := NewClient("https://cnb.cool", "token", "org")
+
+	tests := []struct {
+		name     string
+		level    int
+		project  string
+		expected string
+	}{
+		{name: "映射级别1", level: 1, project: "project", expected: "git@cnb.cool:org/project/repo.git"},
+		{name: "映射级别1无项目", level: 1, project: "", expected: "git@cnb.cool:org/repo.git"},
+		{name: "映射级别2", level: 2, project: "project", expected: "git@cnb.cool:org/repo.git"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := client.GetSSHPushUrl(tt.level, tt.project, "repo"); result != tt.expected {
+				t.Errorf("GetSSHPushUrl() = %s, 期望 %s", result, tt.expected)
+			}
+		})
+	}
+
+	client.SSHPort = "2222"
+	if result := client.GetSSHPushUrl(1, "project", "repo"); result != "ssh://git@cnb.cool:2222/org/project/repo.git" {
+		t.Errorf("GetSSHPushUrl() = %s, 期望使用 ssh:// 地址及端口 2222", result)
+	}
+	if result := client.SSHHost(); result != "cnb.cool:2222" {
+		t.Errorf("SSHHost() = %s, 期望 cnb.cool:2222", result)
+	}
+}
@@ -2,13 +2,17 @@ package config
 
 import (
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/naming"
+	"ccrctl/pkg/redact"
+	"ccrctl/pkg/selector"
 	"ccrctl/pkg/util"
 
 	"github.com/spf13/viper"
@@ -46,10 +50,17 @@ const (
   #CODING与CNB组织映射关系，1表示CODING团队映射为CNB根组织，2表示CODING项目映射为CNB根组织
   organization_mapping_level: 1`
 	codingTokenLength = 40
+	// ConfigFlagName 指定配置文件的命令行参数名
+	ConfigFlagName = "config"
+	// RedactedValue 敏感配置脱敏后的显示值
+	RedactedValue = redact.Mask
 )
 
 var Cfg *viper.Viper
 
+// LoadedFiles 已加载的配置文件，按合并顺序排列
+var LoadedFiles []string
+
 type Config struct {
 	Source  Source  `yaml:"source"`
 	CNB     CNB     `yaml:"cnb"`
@@ -61,18 +72,34 @@ type Source struct {
 	Token          string   `yaml:"token"`
 	Project        []string `yaml:"project"`
 	Repo           []string `yaml:"repo"`
+	Include        []string `yaml:"include"`
+	Exclude        []string `yaml:"exclude"`
 	Platform       string   `yaml:"platform"`
 	UserName       string   `yaml:"username"`
 	Password       string   `yaml:"password"`
 	SshPrivateKey  string   `yaml:"ssh_private_key"`
+	SshPassphrase  string   `yaml:"ssh_passphrase"`
+	SshKnownHosts  string   `yaml:"ssh_known_hosts"`
 	Group          string   `yaml:"group"`
 	OrganizationId string   `yaml:"organizationid"`
+	//只迁移指定组织、用户的仓库，支持 github、gitee、gitea、forgejo、gogs
+	Organizations []string `yaml:"organizations"`
+	Users         []string `yaml:"users"`
+	//GitHub Enterprise Server、GitLab 等平台的 API 地址，未配置时由 source.url 推导
+	APIURL string `yaml:"api_url"`
+	//Gitee 企业版企业路径，配置后迁移企业下的仓库，仓库所属项目映射为 CNB 子组织
+	Enterprise string `yaml:"enterprise"`
 }
 
 type CNB struct {
 	URL              string `yaml:"url"`
 	Token            string `yaml:"token"`
 	RootOrganization string `yaml:"root_organization"`
+	Ssh              bool   `yaml:"ssh"`
+	SshPrivateKey    string `yaml:"ssh_private_key"`
+	SshPassphrase    string `yaml:"ssh_passphrase"`
+	SshPort          string `yaml:"ssh_port"`
+	SshKnownHosts    string `yaml:"ssh_known_hosts"`
 }
 
 type Migrate struct {
@@ -86,6 +113,8 @@ type Migrate struct {
 	//针对LFS源文件丢失的仓库，LFS推送时，允许本地缓存中丢失对象，而无需停止 Git 推送。https://github.com/git-lfs/git-lfs/blob/main/docs/man/git-lfs-config.adoc
 	allowIncompletePush  bool   `yaml:"allow_incomplete_push"`
 	LogLevel             string `yaml:"log_level"`
+	LogFormat            string `yaml:"log_format"`
+	RepoLog              bool   `yaml:"repo_log"`
 	fileLimitSize        int64  `yaml:"file_limit_size"`
 	SkipExistsRepo       bool   `yaml:"skip_exists_repo"`
 	Ssh                  bool   `yaml:"ssh"`
@@ -93,15 +122,45 @@ type Migrate struct {
 	DownloadOnly         bool   `yaml:"download_only"`
 	MapCodingDisplayName bool   `yaml:"map_coding_display_name"`
 	MapCodingDescription bool   `yaml:"map_coding_description"`
+	LargeFileScan        bool   `yaml:"large_file_scan"`
+	LargeFileStrategy    string `yaml:"large_file_strategy"`
+	//按仓库指定大文件处理策略，格式 <仓库路径>=<策略>
+	LargeFileRepoStrategy []string `yaml:"large_file_repo_strategy"`
+	ChunkPush             bool     `yaml:"chunk_push"`
+	ChunkPushStep         int      `yaml:"chunk_push_step"`
+	ChunkPushRefBatch     int      `yaml:"chunk_push_ref_batch"`
+	IncludeRefs           []string `yaml:"include_refs"`
+	ExcludeRefs           []string `yaml:"exclude_refs"`
+	DropPlatformRefs      bool     `yaml:"drop_platform_refs"`
+	Rebase                bool     `yaml:"rebase"`
+	PruneRefs             bool     `yaml:"prune_refs"`
+	PruneMaxPercent       int      `yaml:"prune_max_percent"`
+	SubmoduleRewrite      string   `yaml:"submodule_rewrite"`
+	SubmoduleBranchPrefix string   `yaml:"submodule_branch_prefix"`
+	//迁移报告格式，支持 json、junit、html，off 表示不生成
+	Report []string `yaml:"report"`
+	//按仓库元数据过滤，平台未返回的元数据不参与过滤
+	SkipArchived bool   `yaml:"skip_archived"`
+	SkipFork     bool   `yaml:"skip_fork"`
+	MaxSizeMB    int64  `yaml:"max_size_mb"`
+	ActiveSince  string `yaml:"active_since"`
+	OnlyPrivate  bool   `yaml:"only_private"`
+	//CNB 目标路径映射，优先级为映射文件 > 重写规则 > 模板 > organization_mapping_level
+	TargetRules       []string `yaml:"target_rules"`
+	TargetTemplate    string   `yaml:"target_template"`
+	TargetMappingFile string   `yaml:"target_mapping_file"`
+	CollisionStrategy string   `yaml:"collision_strategy"`
+	//Gerrit 未合入变更的补丁集以分支形式迁移到该命名空间下，为空时不迁移
+	GerritChangesNamespace string `yaml:"gerrit_changes_namespace"`
 }
 
 func CheckConfig() error {
-	yamlFile, err := ioutil.ReadFile("config.yaml")
+	// 由合并后的生效配置解析
+	yamlFile, err := yaml.Marshal(Cfg.AllSettings())
 	if err != nil {
-		log.Fatalf("Failed to read YAML file: %v", err)
+		log.Fatalf("Failed to marshal config: %v", err)
 	}
 
-	// 解析 YAML 文件
 	var config Config
 	err = yaml.Unmarshal(yamlFile, &config)
 	if err != nil {
@@ -118,8 +177,8 @@ func CheckConfig() error {
 		}
 	}
 
-	//非通用第三方平台迁移，检查 source.token 参数（local 不需要）
-	if platform != "common" && platform != "local" {
+	//非通用第三方平台迁移，检查 source.token 参数（local 不需要，gerrit 使用 source.username、password）
+	if platform != "common" && platform != "local" && platform != "gerrit" {
 		if err := checkTokenValid(config.Source.Token, platform); err != nil {
 			return err
 		}
@@ -132,10 +191,27 @@ func CheckConfig() error {
 		}
 	}
 
-	//common http迁移（local 不需要）
-	if platform == "common" && !config.Migrate.Ssh {
+	if config.Source.APIURL != "" {
+		if err := checkURL(config.Source.APIURL); err != nil {
+			return fmt.Errorf("source.api_url %v", err)
+		}
+	}
+
+	if (hasItems(config.Source.Organizations) || hasItems(config.Source.Users)) && platform != "github" && platform != "gitee" && platform != "gitea" && platform != "forgejo" && platform != "gogs" {
+		return fmt.Errorf("source.organizations and source.users only support github or gitee or gitea or forgejo or gogs")
+	}
+	if config.Source.Enterprise != "" && platform != "gitee" {
+		return fmt.Errorf("source.enterprise only support gitee")
+	}
+	// source.group 只对 gitlab、cnb 生效，其他平台忽略，不报错以兼容已有配置
+	if config.Source.Group != "" && platform != "gitlab" && platform != "cnb" {
+		logger.Logger.Warnf("source.group 只支持 gitlab 或 cnb，%s 平台将忽略该配置", platform)
+	}
+
+	//common、gerrit http迁移（local 不需要）
+	if (platform == "common" || platform == "gerrit") && !config.Migrate.Ssh {
 		if config.Source.UserName == "" || config.Source.Password == "" {
-			return fmt.Errorf("when platform is common, source.username、password is required")
+			return fmt.Errorf("when platform is %s, source.username、password is required", platform)
 		}
 		if platform == "common" {
 			if len(config.Source.Repo) == 0 || config.Source.Repo[0] == "" {
@@ -144,6 +220,15 @@ func CheckConfig() error {
 		}
 	}
 
+	if config.Migrate.GerritChangesNamespace != "" {
+		if platform != "gerrit" {
+			return fmt.Errorf("migrate.gerrit_changes_namespace only support gerrit")
+		}
+		if err := checkRefNamespace(config.Migrate.GerritChangesNamespace); err != nil {
+			return fmt.Errorf("migrate.gerrit_changes_namespace %v", err)
+		}
+	}
+
 	// 检查 migrate 参数
 	// migrate.type 不再是必填项，如果未配置则默认为 team
 	if config.Migrate.Type == "" {
@@ -181,6 +266,12 @@ func CheckConfig() error {
 		if config.Migrate.organizationMappingLevel > 2 {
 			return fmt.Errorf("organization_mapping_level error only support 1 or 2 ")
 		}
+
+		if port := config.CNB.SshPort; port != "" {
+			if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+				return fmt.Errorf("cnb.ssh_port %s 不是合法的端口号", port)
+			}
+		}
 	}
 
 	if strings.HasPrefix(config.CNB.RootOrganization, "/") {
@@ -191,35 +282,116 @@ func CheckConfig() error {
 		return fmt.Errorf("migrate.concurrency must be greater than 0")
 	}
 
+	if err := checkLargeFileStrategy(config.Migrate.LargeFileStrategy, config.Migrate.LargeFileRepoStrategy); err != nil {
+		return err
+	}
+
+	if config.Migrate.ChunkPush && (config.Migrate.ChunkPushStep < 1 || config.Migrate.ChunkPushRefBatch < 1) {
+		return fmt.Errorf("migrate.chunk_push_step and migrate.chunk_push_ref_batch must be greater than 0")
+	}
+
+	if _, err := selector.New(config.Source.Include, config.Source.Exclude); err != nil {
+		return err
+	}
+
+	if _, err := naming.New(config.Migrate.TargetRules, config.Migrate.TargetTemplate, config.Migrate.TargetMappingFile); err != nil {
+		return err
+	}
+
+	switch strings.TrimSpace(config.Migrate.CollisionStrategy) {
+	case "", "fail", "suffix", "mapping":
+	default:
+		return fmt.Errorf("migrate.collision_strategy error only support fail or suffix or mapping")
+	}
+
+	if config.Migrate.MaxSizeMB < 0 {
+		return fmt.Errorf("migrate.max_size_mb must be greater than or equal to 0")
+	}
+	if config.Migrate.ActiveSince != "" {
+		if _, err := time.Parse(time.DateOnly, config.Migrate.ActiveSince); err != nil {
+			return fmt.Errorf("migrate.active_since %s 格式错误，应为 YYYY-MM-DD", config.Migrate.ActiveSince)
+		}
+	}
+
+	if err := checkRefPatterns("migrate.include_refs", config.Migrate.IncludeRefs); err != nil {
+		return err
+	}
+	if err := checkRefPatterns("migrate.exclude_refs", config.Migrate.ExcludeRefs); err != nil {
+		return err
+	}
+
+	switch config.Migrate.LogFormat {
+	case "console", "json":
+	default:
+		return fmt.Errorf("migrate.log_format error only support console or json")
+	}
+
+	if err := checkReportFormats(config.Migrate.Report); err != nil {
+		return err
+	}
+
+	switch config.Migrate.SubmoduleRewrite {
+	case "off", "report", "branch":
+	default:
+		return fmt.Errorf("migrate.submodule_rewrite error only support off or report or branch")
+	}
+	if config.Migrate.SubmoduleRewrite == "branch" {
+		if err := checkRefNamespace(strings.TrimSuffix(config.Migrate.SubmoduleBranchPrefix, "/")); err != nil {
+			return fmt.Errorf("migrate.submodule_branch_prefix %v", err)
+		}
+	}
+
+	if config.Migrate.PruneRefs {
+		if config.Migrate.Rebase {
+			return fmt.Errorf("migrate.prune_refs 与 migrate.rebase 不能同时开启，rebase 会保留 CNB 侧独有的分支")
+		}
+		if config.Migrate.PruneMaxPercent < 0 || config.Migrate.PruneMaxPercent > 100 {
+			return fmt.Errorf("migrate.prune_max_percent must be between 0 and 100")
+		}
+	}
+
 	return nil
 }
 
-func init() {
-	Cfg = viper.New()
-	Cfg.SetConfigName("config")
-	Cfg.SetConfigType("yaml")
-	Cfg.AddConfigPath(".")
+// Load 加载配置到 Cfg，files 为 --config 指定的配置文件，由命令行入口在执行子命令前调用
+func Load(files []string) error {
+	v, err := newConfig(configFiles(files))
+	if err != nil {
+		return err
+	}
+	Cfg = v
+	return nil
+}
 
-	// 读取环境变量
-	Cfg.AutomaticEnv()
+// newConfig 加载配置，优先级从低到高为 默认值、配置文件（按顺序合并，后者覆盖前者）、环境变量
+// 只读取配置文件，不会回写
+func newConfig(files []string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
 
-	//envVariables := os.Environ()
-	//println(envVariables)
+	// 读取环境变量
+	v.AutomaticEnv()
 
 	// 设置环境变量前缀
-	Cfg.SetEnvPrefix("PLUGIN")
-	Cfg.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	v.SetEnvPrefix("PLUGIN")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
 
 	// 将环境变量绑定到配置项
-	err := bindEnvVariables(Cfg)
+	err := bindEnvVariables(v)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	// 设置默认值
-	setDefaultValues(Cfg)
+	setDefaultValues(v)
 
-	stringCovertToListAndSetConfigValue(Cfg, "source.project", "source.repo")
+	// 读取配置文件
+	err = readConfigFiles(v, files)
+	if err != nil {
+		return nil, err
+	}
+
+	stringCovertToListAndSetConfigValue(v, "source.project", "source.repo", "source.include", "source.exclude", "source.organizations", "source.users", "migrate.large_file_repo_strategy", "migrate.include_refs", "migrate.exclude_refs", "migrate.report", "migrate.target_rules")
 
 	// 需要转换为布尔值的配置项
 	boolKeys := []string{
@@ -236,41 +408,105 @@ func init() {
 		"migrate.download_only",
 		"migrate.map_coding_display_name",
 		"migrate.map_coding_description",
-	}
-
-	err = parseStringEnvValueToBool(Cfg, boolKeys...)
+		"migrate.large_file_scan",
+		"migrate.chunk_push",
+		"migrate.drop_platform_refs",
+		"migrate.prune_refs",
+		"migrate.repo_log",
+		"migrate.skip_archived",
+		"migrate.skip_fork",
+		"migrate.only_private",
+		"cnb.ssh",
+	}
+
+	err = parseStringEnvValueToBool(v, boolKeys...)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	err = parseStringEnvValueToInt(Cfg, "migrate.concurrency", "migrate.organization_mapping_level")
+	err = parseStringEnvValueToInt(v, "migrate.concurrency", "migrate.organization_mapping_level", "migrate.chunk_push_step", "migrate.chunk_push_ref_batch", "migrate.prune_max_percent", "migrate.max_size_mb")
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	// 处理 cnb.root_organization，使用 TrimSlash 函数清理前后的斜杠和空格
-	processCNBRootOrganization(Cfg)
+	processCNBRootOrganization(v)
+	return v, nil
+}
 
-	// 保存配置到文件
-	err = Cfg.WriteConfigAs(configName)
-	if err != nil {
-		log.Fatalf("Failed to write config file: %v", err)
+// configFiles 整理 --config 参数值，支持多次指定及逗号分隔，如 --config base.yaml --config=github.yaml，去掉空白及空值
+func configFiles(values []string) []string {
+	var files []string
+	for _, value := range values {
+		for _, file := range strings.Split(value, ",") {
+			if file = strings.TrimSpace(file); file != "" {
+				files = append(files, file)
+			}
+		}
 	}
+	return files
+}
 
-	// 读取配置文件
-	err = Cfg.ReadInConfig()
+// readConfigFiles 按顺序合并配置文件，未指定配置文件时读取当前目录下的 config.yaml（不存在则只使用环境变量）
+func readConfigFiles(v *viper.Viper, files []string) error {
+	if len(files) == 0 {
+		if _, err := os.Stat(configName); err != nil {
+			return nil
+		}
+		files = []string{configName}
+	}
+	for _, file := range files {
+		v.SetConfigFile(file)
+		if err := v.MergeInConfig(); err != nil {
+			return fmt.Errorf("failed to read config file %s: %v", file, err)
+		}
+	}
+	LoadedFiles = files
+	return nil
+}
+
+// EffectiveConfig 返回合并后的生效配置（YAML），redacted 为 true 时隐藏 token、密码、私钥等敏感配置
+func EffectiveConfig(redacted bool) (string, error) {
+	settings := Cfg.AllSettings()
+	if redacted {
+		redactSettings(settings)
+	}
+	data, err := yaml.Marshal(settings)
 	if err != nil {
-		log.Fatalf("Failed to read config file: %v", err)
+		return "", err
+	}
+	return string(data), nil
+}
+
+// redactSettings 将敏感配置项的非空值替换为 RedactedValue
+func redactSettings(settings map[string]interface{}) {
+	for key, value := range settings {
+		if nested, ok := value.(map[string]interface{}); ok {
+			redactSettings(nested)
+			continue
+		}
+		if !isSensitiveKey(key) {
+			continue
+		}
+		if s, ok := value.(string); ok && s == "" {
+			continue
+		}
+		settings[key] = RedactedValue
 	}
 }
 
-func ConvertToApiURL(baseUrl string) (apiUrl string) {
-	parts := strings.Split(baseUrl, "://")
-	if len(parts) == 2 {
-		apiUrl = parts[0] + "://" + "api." + parts[1]
-	} else {
-		apiUrl = baseUrl
+// isSensitiveKey 判断配置项是否为凭证类敏感配置
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	// 华为云 source.ak、source.as / source.sk
+	if key == "ak" || key == "as" || key == "sk" {
+		return true
+	}
+	for _, word := range []string{"token", "password", "passphrase", "private_key", "secret"} {
+		if strings.Contains(key, word) {
+			return true
+		}
 	}
-	return apiUrl
+	return false
 }
 
 func InitConfig() error {
@@ -330,6 +566,12 @@ func processCNBRootOrganization(v *viper.Viper) {
 
 func stringCovertToListAndSetConfigValue(v *viper.Viper, keys ...string) {
 	for _, key := range keys {
+		// 配置文件中的 YAML 列表无需转换
+		switch v.Get(key).(type) {
+		case []interface{}, []string:
+			v.Set(key, v.GetStringSlice(key))
+			continue
+		}
 		value := v.GetString(key)
 		// 去除前后空格和末尾的逗号
 		value = strings.TrimSpace(value)
@@ -348,10 +590,16 @@ func bindEnvVariables(config *viper.Viper) error {
 	envKeys := []string{
 		"source.group",
 		"source.url",
+		"source.api_url",
+		"source.enterprise",
 		"source.token",
 		"source.platform",
 		"source.project",
 		"source.repo",
+		"source.include",
+		"source.exclude",
+		"source.organizations",
+		"source.users",
 		"source.username",
 		"source.password",
 		"source.region",
@@ -367,6 +615,9 @@ func bindEnvVariables(config *viper.Viper) error {
 		"migrate.organization_mapping_level",
 		"migrate.allow_incomplete_push",
 		"migrate.log_level",
+		"migrate.log_format",
+		"migrate.repo_log",
+		"migrate.report",
 		"migrate.file_limit_size",
 		"migrate.skip_exists_repo",
 		"migrate.release",
@@ -377,12 +628,42 @@ func bindEnvVariables(config *viper.Viper) error {
 		"source.endpoint",
 		"migrate.ssh",
 		"source.ssh_private_key",
+		"source.ssh_passphrase",
+		"source.ssh_known_hosts",
+		"cnb.ssh",
+		"cnb.ssh_private_key",
+		"cnb.ssh_passphrase",
+		"cnb.ssh_port",
+		"cnb.ssh_known_hosts",
 		"migrate.allow_select_repos",
 		"migrate.download_only",
 		"migrate.include_github_fork",
 		"migrate.map_coding_display_name",
 		"migrate.map_coding_description",
 		"migrate.gitlab_projects_owned",
+		"migrate.large_file_scan",
+		"migrate.large_file_strategy",
+		"migrate.large_file_repo_strategy",
+		"migrate.chunk_push",
+		"migrate.chunk_push_step",
+		"migrate.chunk_push_ref_batch",
+		"migrate.include_refs",
+		"migrate.skip_archived",
+		"migrate.skip_fork",
+		"migrate.max_size_mb",
+		"migrate.active_since",
+		"migrate.only_private",
+		"migrate.target_rules",
+		"migrate.target_template",
+		"migrate.target_mapping_file",
+		"migrate.collision_strategy",
+		"migrate.gerrit_changes_namespace",
+		"migrate.exclude_refs",
+		"migrate.drop_platform_refs",
+		"migrate.prune_refs",
+		"migrate.prune_max_percent",
+		"migrate.submodule_rewrite",
+		"migrate.submodule_branch_prefix",
 	}
 	for _, key := range envKeys {
 		err := config.BindEnv(key)
@@ -404,6 +685,9 @@ func setDefaultValues(config *viper.Viper) {
 		"migrate.type":                       "team",
 		"migrate.allow_incomplete_push":      "false",
 		"migrate.log_level":                  "info",
+		"migrate.log_format":                 "console",
+		"migrate.repo_log":                   "false",
+		"migrate.report":                     "json,junit,html",
 		"source.platform":                    "coding",
 		"migrate.file_limit_size":            "256",
 		"migrate.skip_exists_repo":           "false",
@@ -414,13 +698,43 @@ func setDefaultValues(config *viper.Viper) {
 		"migrate.rebase":                     "false",
 		"cnb.url":                            "https://cnb.cool",
 		"source.url":                         "https://e.coding.net",
+		"source.api_url":                     "",
+		"source.enterprise":                  "",
 		"migrate.allow_select_repos":         "false",
 		"migrate.download_only":              "false",
 		"migrate.include_github_fork":        "true",
 		"migrate.map_coding_display_name":    "true",
 		"migrate.map_coding_description":     "true",
 		"source.region":                      "cn-north-4",
+		"source.include":                     "",
+		"source.exclude":                     "",
+		"source.organizations":               "",
+		"source.users":                       "",
 		"migrate.gitlab_projects_owned":      "false",
+		"migrate.large_file_scan":            "false",
+		"migrate.large_file_strategy":        "lfs",
+		"migrate.large_file_repo_strategy":   "",
+		"migrate.chunk_push":                 "false",
+		"migrate.chunk_push_step":            "1000",
+		"migrate.chunk_push_ref_batch":       "100",
+		"migrate.include_refs":               "",
+		"migrate.skip_archived":              "false",
+		"migrate.skip_fork":                  "false",
+		"migrate.max_size_mb":                "0",
+		"migrate.active_since":               "",
+		"migrate.only_private":               "false",
+		"migrate.target_rules":               "",
+		"migrate.target_template":            "",
+		"migrate.target_mapping_file":        "",
+		"migrate.collision_strategy":         "fail",
+		"migrate.gerrit_changes_namespace":   "",
+		"migrate.exclude_refs":               "",
+		"migrate.drop_platform_refs":         "false",
+		"migrate.prune_refs":                 "false",
+		"migrate.prune_max_percent":          "20",
+		"migrate.submodule_rewrite":          "off",
+		"cnb.ssh":                            "false",
+		"migrate.submodule_branch_prefix":    "cnb-submodule/",
 	}
 
 	// 使用循环来设置默认值
@@ -429,6 +743,60 @@ func setDefaultValues(config *viper.Viper) {
 	}
 }
 
+// hasItems 列表配置中是否有非空项
+func hasItems(items []string) bool {
+	for _, item := range items {
+		if strings.TrimSpace(item) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReportFormats 检查迁移报告格式，只支持 json/junit/html，off 不能与其他格式同时配置
+func checkReportFormats(formats []string) error {
+	for _, format := range formats {
+		switch strings.TrimSpace(format) {
+		case "json", "junit", "html", "":
+		case "off":
+			if len(formats) > 1 {
+				return fmt.Errorf("migrate.report off 不能与其他格式同时配置")
+			}
+		default:
+			return fmt.Errorf("migrate.report error only support json or junit or html or off")
+		}
+	}
+	return nil
+}
+
+// checkLargeFileStrategy 检查大文件处理策略，只支持 skip/lfs/fail
+func checkLargeFileStrategy(strategy string, repoStrategies []string) error {
+	validStrategies := map[string]bool{"skip": true, "lfs": true, "fail": true}
+	if strategy != "" && !validStrategies[strategy] {
+		return fmt.Errorf("migrate.large_file_strategy error only support skip or lfs or fail")
+	}
+	for _, item := range repoStrategies {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || !validStrategies[strings.TrimSpace(parts[1])] {
+			return fmt.Errorf("migrate.large_file_repo_strategy %s 格式错误，应为 <仓库路径>=<skip|lfs|fail>", item)
+		}
+	}
+	return nil
+}
+
+// checkRefPatterns 校验引用过滤规则必须是以 refs/ 开头的完整引用名
+func checkRefPatterns(key string, patterns []string) error {
+	for _, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "refs/") {
+			return fmt.Errorf("%s %s 格式错误，应为以 refs/ 开头的完整引用名，如 refs/heads/archive/*、refs/tags/v*", key, pattern)
+		}
+	}
+	return nil
+}
+
 // token 合规性检查函数
 func checkTokenValid(token string, platform string) error {
 	if err := checkCommonToken(token); err != nil {
@@ -459,6 +827,17 @@ func checkCommonToken(token string) error {
 	return nil
 }
 
+// refNamespacePattern 分支命名空间，以 / 分隔的多级名称，如 gerrit/changes
+var refNamespacePattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*(/[A-Za-z0-9_-][A-Za-z0-9._-]*)*$`)
+
+// checkRefNamespace 检查分支命名空间是否为合法的 git 引用名
+func checkRefNamespace(namespace string) error {
+	if !refNamespacePattern.MatchString(namespace) || strings.Contains(namespace, "..") || strings.HasSuffix(namespace, ".lock") {
+		return fmt.Errorf("%s 不是合法的分支命名空间，应为以 / 分隔的字母、数字、. _ -，如 gerrit/changes", namespace)
+	}
+	return nil
+}
+
 func checkURL(url string) error {
 	// 允许 http:// 或 https:// 开头，后跟域名或IP地址，支持端口号，支持路径
 	// 支持域名格式：example.com, api.example.com
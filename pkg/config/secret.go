@@ -0,0 +1,100 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"ccrctl/pkg/redact"
+
+	"github.com/spf13/viper"
+)
+
+// 凭证引用前缀，凭证类配置项的值以这些前缀开头时，从文件、环境变量或命令输出中读取实际值
+const (
+	SecretFilePrefix = "file:"
+	SecretEnvPrefix  = "env:"
+	SecretExecPrefix = "exec:"
+)
+
+// SecretKeys 凭证类配置项，值支持凭证引用，解析后的值会登记到 redact 以便在日志中隐藏
+var SecretKeys = []string{
+	"source.token",
+	"source.password",
+	"source.ak",
+	"source.as",
+	"source.sk",
+	"source.ssh_private_key",
+	"source.ssh_passphrase",
+	"cnb.token",
+	"cnb.ssh_private_key",
+	"cnb.ssh_passphrase",
+}
+
+// ResolveSecret 解析凭证引用，不以引用前缀开头的值原样返回
+//   - file:/run/secrets/cnb_token 读取文件内容
+//   - env:OTHER_VAR 读取环境变量
+//   - exec:vault kv get -field=token secret/cnb 执行命令（sh -c）并读取标准输出
+//
+// 读取结果会去掉首尾空白
+func ResolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, SecretFilePrefix):
+		path := strings.TrimSpace(strings.TrimPrefix(value, SecretFilePrefix))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("读取凭证文件失败: %v", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case strings.HasPrefix(value, SecretEnvPrefix):
+		name := strings.TrimSpace(strings.TrimPrefix(value, SecretEnvPrefix))
+		secret, ok := os.LookupEnv(name)
+		if !ok || strings.TrimSpace(secret) == "" {
+			return "", fmt.Errorf("环境变量 %s 未设置", name)
+		}
+		return strings.TrimSpace(secret), nil
+	case strings.HasPrefix(value, SecretExecPrefix):
+		command := strings.TrimSpace(strings.TrimPrefix(value, SecretExecPrefix))
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("执行凭证命令失败: %v %s", err, strings.TrimSpace(stderr.String()))
+		}
+		secret := strings.TrimSpace(stdout.String())
+		if secret == "" {
+			return "", fmt.Errorf("凭证命令输出为空")
+		}
+		return secret, nil
+	default:
+		return value, nil
+	}
+}
+
+// ResolveSecrets 解析 Cfg 中凭证类配置项的引用（file:/env:/exec:），并将凭证登记到 redact
+// 加载配置时不解析，只由需要凭证的命令（migrate、plan、select、retry-failed、doctor）在加载配置后调用
+func ResolveSecrets() error {
+	return resolveSecrets(Cfg)
+}
+
+// resolveSecrets 解析凭证类配置项中的引用，并将凭证登记到 redact
+func resolveSecrets(v *viper.Viper) error {
+	for _, key := range SecretKeys {
+		value := v.GetString(key)
+		if value == "" {
+			continue
+		}
+		secret, err := ResolveSecret(value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		if secret != value {
+			v.Set(key, secret)
+		}
+		redact.Register(secret)
+	}
+	return nil
+}
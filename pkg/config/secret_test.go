@@ -0,0 +1,88 @@
+package config
+
+import (
+	"ccrctl/pkg/redact"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestResolveSecret 测试解析文件、环境变量、命令输出形式的凭证引用
+func TestResolveSecret(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cnb_token")
+	if err := os.WriteFile(file, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CCRCTL_TEST_SECRET", "env-secret")
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "明文", value: "plain-secret", expected: "plain-secret"},
+		{name: "文件", value: "file:" + file, expected: "file-secret"},
+		{name: "文件不存在", value: "file:" + file + ".missing", wantErr: true},
+		{name: "环境变量", value: "env:CCRCTL_TEST_SECRET", expected: "env-secret"},
+		{name: "环境变量未设置", value: "env:CCRCTL_TEST_SECRET_MISSING", wantErr: true},
+		{name: "命令", value: "exec:echo exec-secret", expected: "exec-secret"},
+		{name: "命令失败", value: "exec:exit 1", wantErr: true},
+		{name: "命令输出为空", value: "exec:true", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveSecret(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveSecret(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ResolveSecret(%q) = %q, 期望 %q", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestResolveSecrets 测试加载配置时不解析凭证引用，调用 ResolveSecrets 后解析并登记到 redact
+func TestResolveSecrets(t *testing.T) {
+	defer redact.Reset()
+	old := Cfg
+	defer func() { Cfg = old }()
+	file := filepath.Join(t.TempDir(), "cnb_token")
+	if err := os.WriteFile(file, []byte("cnb-file-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PLUGIN_CNB_TOKEN", "file:"+file)
+	t.Setenv("PLUGIN_SOURCE_TOKEN", "exec:echo executed >> "+file+".exec; echo source-token")
+
+	if err := Load(nil); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := Cfg.GetString("cnb.token"); got != "file:"+file {
+		t.Errorf("加载配置后 cnb.token = %q, 期望保留凭证引用", got)
+	}
+	if _, err := os.Stat(file + ".exec"); err == nil {
+		t.Error("加载配置时不应执行 exec: 凭证命令")
+	}
+
+	if err := ResolveSecrets(); err != nil {
+		t.Fatalf("ResolveSecrets() error = %v", err)
+	}
+	if got := Cfg.GetString("cnb.token"); got != "cnb-file-token" {
+		t.Errorf("cnb.token = %q, 期望 %q", got, "cnb-file-token")
+	}
+	if got := Cfg.GetString("source.token"); got != "source-token" {
+		t.Errorf("source.token = %q, 期望 %q", got, "source-token")
+	}
+	if got := redact.String("token=cnb-file-token"); got != "token="+redact.Mask {
+		t.Errorf("解析后的凭证未登记到 redact: %q", got)
+	}
+
+	t.Setenv("PLUGIN_CNB_TOKEN", "env:CCRCTL_TEST_SECRET_MISSING")
+	if err := Load(nil); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := ResolveSecrets(); err == nil {
+		t.Error("凭证引用解析失败时期望返回错误")
+	}
+}
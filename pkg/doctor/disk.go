@@ -0,0 +1,85 @@
+package doctor
+
+import (
+	"ccrctl/pkg/vcs"
+	"fmt"
+	"os"
+	"sort"
+	"syscall"
+)
+
+const diskSpaceName = "磁盘空间"
+
+// availableSpace 获取目录所在文件系统的可用空间，单位字节
+func availableSpace(dir string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
+
+// estimateRequiredSpace 估算迁移所需磁盘空间
+// 迁移完成的仓库会立即删除，同时存在的仓库数不超过并发数，因此取最大的 concurrency 个仓库之和；只下载模式保留所有仓库
+func estimateRequiredSpace(sizes []int64, concurrency int, downloadOnly bool) int64 {
+	sorted := append([]int64(nil), sizes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+	if !downloadOnly && concurrency > 0 && concurrency < len(sorted) {
+		sorted = sorted[:concurrency]
+	}
+	var total int64
+	for _, size := range sorted {
+		total += size
+	}
+	return total
+}
+
+func checkDiskSpace(opts Options, repos []vcs.VCS) Result {
+	dir := opts.WorkDir
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return warn(diskSpaceName, "", "获取当前工作目录失败: %s", err)
+		}
+	}
+	available, err := availableSpace(dir)
+	if err != nil {
+		return warn(diskSpaceName, "", "获取 %s 可用空间失败: %s", dir, err)
+	}
+
+	var sizes []int64
+	for _, repo := range repos {
+		// 与 migrate.max_size_mb 过滤使用同一仓库大小，为平台统计的估算值
+		if sizeKB := repo.GetMetadata().SizeKB; sizeKB > 0 {
+			sizes = append(sizes, sizeKB*1024)
+		}
+	}
+	if len(sizes) == 0 {
+		return pass(diskSpaceName, "%s 可用 %s，源平台未提供仓库大小，无法估算所需空间", dir, formatBytes(int64(available)))
+	}
+	required := estimateRequiredSpace(sizes, opts.Concurrency, opts.DownloadOnly)
+	message := fmt.Sprintf("%s 可用 %s，预计需要 %s（%d 个仓库，并发数 %d）", dir, formatBytes(int64(available)), formatBytes(required), len(sizes), opts.Concurrency)
+	switch {
+	case uint64(required) > available:
+		return fail(diskSpaceName, "清理磁盘、降低 migrate.concurrency，或在空间更大的目录运行", "%s", message)
+	case uint64(required)*2 > available:
+		// 克隆过程中的临时文件、LFS 对象及 rebase 备份会占用额外空间
+		return warn(diskSpaceName, "预留至少两倍于预计大小的空间，LFS 对象不计入平台统计的仓库大小", "%s", message)
+	default:
+		return pass(diskSpaceName, "%s", message)
+	}
+}
+
+// formatBytes 格式化字节数，如 1536 → 1.5KiB
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
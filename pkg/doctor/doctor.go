@@ -0,0 +1,144 @@
+package doctor
+
+import (
+	"ccrctl/pkg/config"
+	"ccrctl/pkg/migrate"
+	"ccrctl/pkg/vcs"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Status 检查结果状态
+type Status string
+
+const (
+	StatusPass Status = "PASS"
+	StatusWarn Status = "WARN"
+	StatusFail Status = "FAIL"
+	StatusSkip Status = "SKIP"
+)
+
+// DefaultTimeout 网络及 API 检查的默认超时时间
+const DefaultTimeout = 10 * time.Second
+
+// Result 单项检查结果
+type Result struct {
+	Name    string
+	Status  Status
+	Message string
+	// Suggestion 检查未通过时的处理建议
+	Suggestion string
+}
+
+// Options 检查选项
+type Options struct {
+	migrate.Options
+	// Timeout 网络连通性检查超时时间
+	Timeout time.Duration
+}
+
+// OptionsFromConfig 从配置中读取检查选项
+func OptionsFromConfig(v *viper.Viper) Options {
+	return Options{
+		Options: migrate.OptionsFromConfig(v),
+		Timeout: DefaultTimeout,
+	}
+}
+
+func pass(name, format string, a ...interface{}) Result {
+	return Result{Name: name, Status: StatusPass, Message: fmt.Sprintf(format, a...)}
+}
+
+func warn(name, suggestion, format string, a ...interface{}) Result {
+	return Result{Name: name, Status: StatusWarn, Message: fmt.Sprintf(format, a...), Suggestion: suggestion}
+}
+
+func fail(name, suggestion, format string, a ...interface{}) Result {
+	return Result{Name: name, Status: StatusFail, Message: fmt.Sprintf(format, a...), Suggestion: suggestion}
+}
+
+func skip(name, format string, a ...interface{}) Result {
+	return Result{Name: name, Status: StatusSkip, Message: fmt.Sprintf(format, a...)}
+}
+
+// Run 执行迁移前检查，各项检查互不影响，前置检查失败时跳过依赖它的检查
+func Run(opts Options) []Result {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	var results []Result
+	configResult := checkConfig()
+	results = append(results, configResult)
+	results = append(results, checkTools(opts)...)
+	results = append(results, checkNetwork(opts)...)
+
+	if configResult.Status == StatusFail {
+		return append(results, skip("源平台 token", "配置校验未通过"), skip("CNB 令牌", "配置校验未通过"))
+	}
+	sourceResults := checkSourceToken(opts)
+	results = append(results, sourceResults...)
+	results = append(results, checkCnb(opts)...)
+
+	var repos []vcs.VCS
+	if hasFailure(sourceResults) {
+		results = append(results, skip("源平台仓库列表", "源平台 token 校验未通过"))
+	} else {
+		var listResult Result
+		repos, listResult = listRepos(opts)
+		results = append(results, listResult)
+	}
+	return append(results, checkDiskSpace(opts, repos))
+}
+
+func checkConfig() Result {
+	if err := config.CheckConfig(); err != nil {
+		return fail("配置", "参考 doc/parameters.md 修改配置文件或环境变量", "%s", err)
+	}
+	return pass("配置", "配置校验通过")
+}
+
+// listRepos 获取源平台仓库列表，同时校验 token 是否有列出仓库的权限
+func listRepos(opts Options) ([]vcs.VCS, Result) {
+	const name = "源平台仓库列表"
+	repos, err := vcs.New(opts.SourcePlatform, opts.Vcs)
+	if err != nil {
+		return nil, fail(name, "检查 source.token 权限及 source.url、source.project 等配置", "获取仓库列表失败: %s", err)
+	}
+	if len(repos) == 0 {
+		return nil, warn(name, "检查 token 所属用户是否有仓库的访问权限", "源平台仓库列表为空")
+	}
+	return repos, pass(name, "获取到 %d 个仓库", len(repos))
+}
+
+func hasFailure(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Failed 是否存在未通过的检查
+func Failed(results []Result) bool {
+	return hasFailure(results)
+}
+
+// Print 输出检查结果及汇总
+func Print(w io.Writer, results []Result) {
+	counts := make(map[Status]int)
+	for _, r := range results {
+		counts[r.Status]++
+		fmt.Fprintf(w, "[%s] %s: %s\n", r.Status, r.Name, r.Message)
+		if r.Suggestion != "" && (r.Status == StatusFail || r.Status == StatusWarn) {
+			fmt.Fprintf(w, "       建议: %s\n", r.Suggestion)
+		}
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	fmt.Fprintf(w, "通过 %d，警告 %d，失败 %d，跳过 %d\n",
+		counts[StatusPass], counts[StatusWarn], counts[StatusFail], counts[StatusSkip])
+}
@@ -0,0 +1,143 @@
+package doctor
+
+import (
+	"ccrctl/pkg/migrate"
+	"ccrctl/pkg/vcs"
+	"reflect"
+	"testing"
+)
+
+// TestVersionAtLeast 测试从命令输出中解析并比较版本号
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		output     string
+		minVersion string
+		expected   bool
+		wantErr    bool
+	}{
+		{output: "git version 2.39.2", minVersion: MinGitVersion, expected: true},
+		{output: "git version 2.37.1 (Apple Git-137.1)", minVersion: MinGitVersion, expected: true},
+		{output: "git version 2.16", minVersion: MinGitVersion, expected: true},
+		{output: "git version 1.8.3.1", minVersion: MinGitVersion, expected: false},
+		{output: "git-lfs/3.3.0 (GitHub; linux amd64; go 1.19.8)", minVersion: MinGitLFSVersion, expected: true},
+		{output: "git-lfs/2.4.2 (GitHub; linux amd64; go 1.10.3)", minVersion: MinGitLFSVersion, expected: false},
+		{output: "unknown", minVersion: MinGitVersion, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			got, err := versionAtLeast(tt.output, tt.minVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("versionAtLeast() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("versionAtLeast(%q, %q) = %v, 期望 %v", tt.output, tt.minVersion, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEstimateRequiredSpace 测试按并发数估算所需磁盘空间
+func TestEstimateRequiredSpace(t *testing.T) {
+	sizes := []int64{10, 300, 20, 200}
+	if got := estimateRequiredSpace(sizes, 2, false); got != 500 {
+		t.Errorf("并发数 2 期望取最大的两个仓库之和 500，实际 %d", got)
+	}
+	if got := estimateRequiredSpace(sizes, 10, false); got != 530 {
+		t.Errorf("并发数大于仓库数期望 530，实际 %d", got)
+	}
+	if got := estimateRequiredSpace(sizes, 2, true); got != 530 {
+		t.Errorf("只下载模式期望所有仓库之和 530，实际 %d", got)
+	}
+	if !reflect.DeepEqual(sizes, []int64{10, 300, 20, 200}) {
+		t.Errorf("不应修改传入的切片: %v", sizes)
+	}
+}
+
+// TestCheckGithubScopes 测试 GitHub classic token 授权范围检查
+func TestCheckGithubScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		expected []Status
+	}{
+		{name: "fine-grained token", scopes: nil, expected: []Status{StatusWarn}},
+		{name: "权限完整", scopes: []string{"repo", "read:org"}, expected: []Status{StatusPass}},
+		{name: "admin:org 包含 read:org", scopes: []string{"repo", "admin:org"}, expected: []Status{StatusPass}},
+		{name: "缺少 read:org", scopes: []string{"repo"}, expected: []Status{StatusWarn}},
+		{name: "无权限", scopes: []string{}, expected: []Status{StatusFail, StatusWarn}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Status
+			for _, r := range checkGithubScopes(tt.scopes) {
+				got = append(got, r.Status)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("checkGithubScopes(%v) = %v, 期望 %v", tt.scopes, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCheckGitlabScopes 测试 GitLab token 授权范围检查
+func TestCheckGitlabScopes(t *testing.T) {
+	tests := []struct {
+		scopes   []string
+		expected Status
+	}{
+		{scopes: []string{"api"}, expected: StatusPass},
+		{scopes: []string{"read_api", "read_repository"}, expected: StatusPass},
+		{scopes: []string{"read_api"}, expected: StatusFail},
+		{scopes: []string{"read_user"}, expected: StatusFail},
+	}
+	for _, tt := range tests {
+		if got := checkGitlabScopes(tt.scopes).Status; got != tt.expected {
+			t.Errorf("checkGitlabScopes(%v) = %s, 期望 %s", tt.scopes, got, tt.expected)
+		}
+	}
+}
+
+// TestEndpoints 测试根据配置生成需要检查连通性的地址
+func TestEndpoints(t *testing.T) {
+	opts := Options{Options: migrate.Options{
+		SourcePlatform: "github",
+		SourceURL:      "https://github.com",
+		CnbURL:         "https://cnb.cool",
+		CnbSSH:         true,
+		Vcs:            vcs.Options{SSH: true},
+	}}
+	var got []string
+	for _, e := range endpoints(opts) {
+		got = append(got, e.addr)
+	}
+	expected := []string{"github.com:443", "api.github.com:443", "github.com:22", "cnb.cool:443", "api.cnb.cool:443", "cnb.cool:22"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("endpoints() = %v, 期望 %v", got, expected)
+	}
+
+	opts.DownloadOnly = true
+	opts.SourcePlatform = "gitlab"
+	opts.SourceURL = "http://gitlab.example.com:8080"
+	opts.Vcs.SSH = false
+	got = nil
+	for _, e := range endpoints(opts) {
+		got = append(got, e.addr)
+	}
+	if !reflect.DeepEqual(got, []string{"gitlab.example.com:8080"}) {
+		t.Errorf("只下载模式不检查 CNB，实际 %v", got)
+	}
+}
+
+// TestFormatBytes 测试字节数格式化
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:                    "512B",
+		1536:                   "1.5KiB",
+		5 * 1024 * 1024 * 1024: "5.0GiB",
+	}
+	for size, expected := range tests {
+		if got := formatBytes(size); got != expected {
+			t.Errorf("formatBytes(%d) = %s, 期望 %s", size, got, expected)
+		}
+	}
+}
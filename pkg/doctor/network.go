@@ -0,0 +1,90 @@
+package doctor
+
+import (
+	"ccrctl/pkg/api/aliyun"
+	"ccrctl/pkg/api/github"
+	"ccrctl/pkg/util"
+	"ccrctl/pkg/vcs"
+	"net"
+	"net/url"
+	"time"
+)
+
+const sshPort = "22"
+
+// endpoint 需要检查连通性的地址
+type endpoint struct {
+	name string
+	addr string
+}
+
+// endpoints 根据源平台及 CNB 配置生成需要检查连通性的地址，已去重
+func endpoints(opts Options) []endpoint {
+	var result []endpoint
+	seen := make(map[string]bool)
+	add := func(name, rawURL, port string) {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Hostname() == "" {
+			return
+		}
+		if port == "" {
+			port = u.Port()
+		}
+		if port == "" {
+			port = "443"
+			if u.Scheme == "http" {
+				port = "80"
+			}
+		}
+		addr := net.JoinHostPort(u.Hostname(), port)
+		if !seen[addr] {
+			seen[addr] = true
+			result = append(result, endpoint{name: name, addr: addr})
+		}
+	}
+
+	switch opts.SourcePlatform {
+	case "local":
+	case "aliyun":
+		add("源平台 API", aliyun.AliyunEndpoint, "")
+	case "github":
+		add("源平台", opts.SourceURL, "")
+		add("源平台 API", github.ResolveAPIURL(opts.SourceURL, opts.SourceAPIURL), "")
+	default:
+		add("源平台", opts.SourceURL, "")
+	}
+	if opts.Vcs.SSH && opts.SourcePlatform == "gerrit" {
+		// 与克隆地址相同，使用 /ssh_info 返回的主机及端口
+		add("源平台 SSH", "ssh://"+vcs.GerritSSHAddress(opts.Vcs.Source), "")
+	} else if opts.Vcs.SSH && opts.SourcePlatform != "local" {
+		add("源平台 SSH", opts.SourceURL, sshPort)
+	}
+	if !opts.DownloadOnly {
+		add("CNB", opts.CnbURL, "")
+		add("CNB API", util.ConvertToApiURL(opts.CnbURL), "")
+		if opts.CnbSSH {
+			port := opts.CnbSSHPort
+			if port == "" {
+				port = sshPort
+			}
+			add("CNB SSH", opts.CnbURL, port)
+		}
+	}
+	return result
+}
+
+func checkNetwork(opts Options) []Result {
+	var results []Result
+	for _, e := range endpoints(opts) {
+		name := "网络 " + e.name
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", e.addr, opts.Timeout)
+		if err != nil {
+			results = append(results, fail(name, "检查 DNS、防火墙及代理设置，确认本机可以访问该地址", "%s 无法连接: %s", e.addr, err))
+			continue
+		}
+		conn.Close()
+		results = append(results, pass(name, "%s 连接成功，耗时 %s", e.addr, time.Since(start).Round(time.Millisecond)))
+	}
+	return results
+}
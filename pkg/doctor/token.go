@@ -0,0 +1,183 @@
+package doctor
+
+import (
+	"ccrctl/pkg/api/cnb"
+	"ccrctl/pkg/api/coding"
+	"ccrctl/pkg/api/gitea"
+	"ccrctl/pkg/api/gitee"
+	"ccrctl/pkg/api/github"
+	"ccrctl/pkg/api/gitlab"
+	"ccrctl/pkg/api/target"
+	"ccrctl/pkg/source"
+	"strings"
+)
+
+const sourceTokenName = "源平台 token"
+
+// checkSourceToken 校验源平台 token 是否有效，GitHub、GitLab 额外检查授权范围
+// 没有用户信息接口的平台通过获取仓库列表校验
+func checkSourceToken(opts Options) []Result {
+	src := opts.Vcs.Source
+	switch opts.SourcePlatform {
+	case "github":
+		login, scopes, err := github.GetTokenScopes(src)
+		if err != nil {
+			return []Result{fail(sourceTokenName, "在 https://github.com/settings/tokens 重新生成 token", "token 校验失败: %s", err)}
+		}
+		return append([]Result{pass(sourceTokenName, "token 有效，用户 %s", login)}, checkGithubScopes(scopes)...)
+	case "gitlab":
+		name, err := gitlab.GetCurrentUserName(src)
+		if err != nil {
+			return []Result{fail(sourceTokenName, "检查 source.url 并重新生成 Personal Access Token", "token 校验失败: %s", err)}
+		}
+		results := []Result{pass(sourceTokenName, "token 有效，用户 %s", name)}
+		scopes, err := gitlab.GetTokenScopes(src)
+		if err != nil {
+			return append(results, warn("源平台 token 授权范围", "确认 token 包含 read_api 与 read_repository 权限", "无法获取 token 授权范围（需要 GitLab 15.5 及以上版本）: %s", err))
+		}
+		return append(results, checkGitlabScopes(scopes))
+	case "gitee", "gitea", "forgejo", "gogs", "coding", "cnb":
+		name, err := sourceUserName(opts)
+		if err != nil {
+			return []Result{fail(sourceTokenName, "检查 source.token 是否过期，权限要求见 doc/parameters.md 中的 PLUGIN_SOURCE_TOKEN", "token 校验失败: %s", err)}
+		}
+		results := []Result{pass(sourceTokenName, "token 有效，用户 %s", name)}
+		if opts.SourcePlatform == "gitee" && strings.TrimSpace(src.Enterprise) != "" {
+			results = append(results, checkGiteeEnterprise(src, strings.TrimSpace(src.Enterprise), name))
+		}
+		if opts.SourcePlatform == "gitea" || opts.SourcePlatform == "forgejo" || opts.SourcePlatform == "gogs" {
+			results = append(results, checkGiteaFlavor(src))
+		}
+		return results
+	case "common", "local":
+		return []Result{skip(sourceTokenName, "%s 平台不使用 token", opts.SourcePlatform)}
+	default:
+		return []Result{skip(sourceTokenName, "%s 平台通过获取仓库列表校验 token", opts.SourcePlatform)}
+	}
+}
+
+func sourceUserName(opts Options) (string, error) {
+	src := opts.Vcs.Source
+	switch opts.SourcePlatform {
+	case "gitee":
+		return gitee.GetUserName(src)
+	case "gitea", "forgejo", "gogs":
+		return gitea.GetUserName(src)
+	case "coding":
+		return coding.GetCurrentUserName(src.URL, src.Token)
+	default:
+		return cnb.GetCurrentUserName(src)
+	}
+}
+
+// checkGiteeEnterprise 检查 token 用户是否为企业成员，非企业成员无法列出企业仓库
+func checkGiteeEnterprise(src source.Options, enterprise, login string) Result {
+	const name = "Gitee 企业成员"
+	members, err := gitee.GetEnterpriseMembers(src, enterprise)
+	if err != nil {
+		return fail(name, "确认 source.enterprise 为企业路径（e.gitee.com/<企业路径>）且 token 用户已加入该企业", "获取企业 %s 成员失败: %s", enterprise, err)
+	}
+	for _, member := range members {
+		if strings.EqualFold(member.User.Login, login) {
+			return pass(name, "用户 %s 是企业 %s 的成员，角色 %s", login, enterprise, member.Role)
+		}
+	}
+	return fail(name, "使用企业成员的 token", "用户 %s 不是企业 %s 的成员", login, enterprise)
+}
+
+// checkGiteaFlavor 通过版本接口检查 source.platform 与源平台实际类型是否一致
+func checkGiteaFlavor(src source.Options) Result {
+	const name = "源平台类型"
+	platform := src.Platform
+	flavor, version, err := gitea.DetectFlavor(src)
+	if err != nil && platform == gitea.FlavorGogs {
+		// Gogs 没有版本接口
+		return pass(name, "%s", platform)
+	}
+	if err != nil {
+		return warn(name, "确认 source.url 为 Gitea、Forgejo 或 Gogs 的访问地址", "探测源平台类型失败: %s", err)
+	}
+	if flavor != platform {
+		return warn(name, "将 source.platform 配置为 "+flavor, "源平台为 %s %s，与 source.platform %s 不一致", flavor, version, platform)
+	}
+	return pass(name, "%s %s", flavor, version)
+}
+
+// checkGithubScopes 检查 classic token 的授权范围，scopes 为 nil 表示 fine-grained token 无法检查
+func checkGithubScopes(scopes []string) []Result {
+	const name = "源平台 token 授权范围"
+	if scopes == nil {
+		return []Result{warn(name, "确认 fine-grained token 对需要迁移的仓库有 Contents、Metadata 只读权限", "fine-grained token 不返回授权范围，无法检查")}
+	}
+	var results []Result
+	if !hasAnyScope(scopes, "repo") {
+		results = append(results, fail(name, "重新生成 token 并勾选 repo", "缺少 repo 权限，无法克隆私有仓库及读取 release，当前授权范围: %s", formatScopes(scopes)))
+	}
+	if !hasAnyScope(scopes, "read:org", "write:org", "admin:org") {
+		results = append(results, warn(name, "需要迁移组织仓库时勾选 read:org", "缺少 read:org 权限，可能无法列出组织仓库，当前授权范围: %s", formatScopes(scopes)))
+	}
+	if len(results) == 0 {
+		results = append(results, pass(name, "%s", formatScopes(scopes)))
+	}
+	return results
+}
+
+// checkGitlabScopes 列出项目需要 read_api，克隆需要 read_repository，api 包含两者
+func checkGitlabScopes(scopes []string) Result {
+	const name = "源平台 token 授权范围"
+	if hasAnyScope(scopes, "api") || (hasAnyScope(scopes, "read_api") && hasAnyScope(scopes, "read_repository")) {
+		return pass(name, "%s", formatScopes(scopes))
+	}
+	return fail(name, "重新生成 token 并勾选 read_api 与 read_repository", "缺少 read_api 或 read_repository 权限，当前授权范围: %s", formatScopes(scopes))
+}
+
+func hasAnyScope(scopes []string, want ...string) bool {
+	for _, scope := range scopes {
+		for _, w := range want {
+			if scope == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func formatScopes(scopes []string) string {
+	if len(scopes) == 0 {
+		return "无"
+	}
+	return strings.Join(scopes, ", ")
+}
+
+// checkCnb 校验 CNB 令牌、根组织是否存在及令牌用户在根组织中的角色
+func checkCnb(opts Options) []Result {
+	if opts.DownloadOnly {
+		return []Result{skip("CNB 令牌", "只下载模式不访问 CNB")}
+	}
+	client := target.NewClient(opts.CnbURL, opts.CnbToken, opts.RootOrganization)
+	name, err := client.CurrentUserName()
+	if err != nil {
+		return []Result{fail("CNB 令牌", "在 https://cnb.cool/profile/token 重新创建令牌，常见场景勾选「迁移工具凭据」", "令牌校验失败: %s", err)}
+	}
+	results := []Result{pass("CNB 令牌", "令牌有效，用户 %s", name)}
+
+	exists, err := client.RootOrganizationExists()
+	if err != nil {
+		return append(results, fail("CNB 根组织", "确认令牌使用范围包含根组织", "判断根组织 %s 是否存在失败: %s", opts.RootOrganization, err))
+	}
+	if !exists {
+		return append(results, fail("CNB 根组织", "先在 CNB 创建根组织，或修改 cnb.root_organization", "根组织 %s 不存在", opts.RootOrganization))
+	}
+	role, err := client.RootOrganizationAccessRole()
+	switch {
+	case err != nil:
+		results = append(results, warn("CNB 根组织", "确认令牌用户是根组织的负责人或管理员", "根组织 %s 存在，获取角色失败: %s", opts.RootOrganization, err))
+	case role == "":
+		results = append(results, warn("CNB 根组织", "确认令牌用户是根组织的负责人或管理员", "根组织 %s 存在，接口未返回当前用户角色", opts.RootOrganization))
+	case role == "Owner" || role == "Master":
+		results = append(results, pass("CNB 根组织", "根组织 %s 存在，当前用户角色 %s", opts.RootOrganization, role))
+	default:
+		results = append(results, fail("CNB 根组织", "使用根组织负责人（Owner）或管理员（Master）的令牌", "当前用户在根组织 %s 中的角色为 %s，无法创建子组织和仓库", opts.RootOrganization, role))
+	}
+	return results
+}
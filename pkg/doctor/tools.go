@@ -0,0 +1,102 @@
+package doctor
+
+import (
+	"ccrctl/pkg/system"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MinGitVersion 大文件扫描使用的 git log --find-object 需要 2.16 及以上版本
+	MinGitVersion = "2.16.0"
+	// MinGitLFSVersion git lfs migrate import --everything 需要 2.5 及以上版本
+	MinGitLFSVersion = "2.5.0"
+)
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseVersion 从命令输出中解析第一个版本号，如 "git version 2.39.2" → [2 39 2]
+func parseVersion(output string) ([3]int, bool) {
+	var version [3]int
+	match := versionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return version, false
+	}
+	for i := 0; i < 3; i++ {
+		if match[i+1] != "" {
+			version[i], _ = strconv.Atoi(match[i+1])
+		}
+	}
+	return version, true
+}
+
+// versionAtLeast 比较命令输出中的版本号是否不低于 minVersion
+func versionAtLeast(output, minVersion string) (bool, error) {
+	version, ok := parseVersion(output)
+	if !ok {
+		return false, fmt.Errorf("无法解析版本号: %s", output)
+	}
+	min, _ := parseVersion(minVersion)
+	for i := 0; i < 3; i++ {
+		if version[i] != min[i] {
+			return version[i] > min[i], nil
+		}
+	}
+	return true, nil
+}
+
+func checkTools(opts Options) []Result {
+	results := []Result{
+		checkVersion("git", "安装或升级 git: https://git-scm.com/downloads", MinGitVersion, "git", "--version"),
+		checkVersion("git-lfs", "安装 git-lfs 并执行 git lfs install: https://git-lfs.com", MinGitLFSVersion, "git", "lfs", "version"),
+	}
+	// CODING 平台存在 SVN 仓库，迁移时会忽略，这里只做提示
+	if opts.SourcePlatform == "coding" {
+		output, err := system.RunCommand("git", "", "svn", "--version")
+		if err != nil {
+			results = append(results, warn("git-svn", "如需另行迁移 SVN 仓库，请安装 git-svn", "未安装 git-svn，SVN 仓库将被忽略迁移"))
+		} else {
+			results = append(results, pass("git-svn", "%s，SVN 仓库将被忽略迁移", firstLine(output)))
+		}
+	}
+	if opts.Vcs.SSH || opts.CnbSSH {
+		for _, tool := range []string{"ssh", "ssh-agent", "ssh-add", "ssh-keyscan"} {
+			if _, err := exec.LookPath(tool); err != nil {
+				results = append(results, fail(tool, "安装 OpenSSH 客户端，或关闭 migrate.ssh、cnb.ssh", "未找到 %s", tool))
+			}
+		}
+	}
+	return results
+}
+
+// checkVersion 执行命令获取版本，未安装或低于 minVersion 时检查失败
+func checkVersion(name, suggestion, minVersion, command string, args ...string) Result {
+	output, err := system.RunCommand(command, "", args...)
+	if err != nil {
+		detail := firstLine(output)
+		if detail == "" {
+			detail = err.Error()
+		}
+		return fail(name, suggestion, "未安装或无法执行: %s", detail)
+	}
+	output = firstLine(output)
+	ok, err := versionAtLeast(output, minVersion)
+	if err != nil {
+		return warn(name, suggestion, "%s", err)
+	}
+	if !ok {
+		return fail(name, suggestion, "%s 低于最低版本 %s", output, minVersion)
+	}
+	return pass(name, "%s", output)
+}
+
+func firstLine(output string) string {
+	output = strings.TrimSpace(output)
+	if i := strings.IndexByte(output, '\n'); i >= 0 {
+		return strings.TrimSpace(output[:i])
+	}
+	return output
+}
@@ -0,0 +1,235 @@
+package git
+
+import (
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/system"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ChunkPushTempRef 分批推送历史提交时使用的临时引用，不使用 refs/heads 以免触发目标仓库流水线
+	ChunkPushTempRef = "refs/ccrctl/chunk-push"
+)
+
+// ChunkPushOptions 分批推送配置
+type ChunkPushOptions struct {
+	StepSize     int       // 每批推送的 first-parent 提交数
+	RefBatchSize int       // 每批推送的分支/标签数
+	Force        bool      // 是否强制推送分支和标签
+	Filter       RefFilter // 分支/标签过滤规则
+	Env          []string  // 追加到 git push 的环境变量，如 SSH 认证使用的 GIT_SSH_COMMAND
+}
+
+// ChunkPushStep 分批推送中的一个步骤
+type ChunkPushStep struct {
+	Commit   string   // 历史提交检查点，推送到临时引用；为空表示推送分支/标签
+	Refspecs []string // 分支/标签 refspec
+}
+
+// ChunkPushProgress 分批推送进度，Step 为最后一个已确认完成的步骤序号（从 1 开始），Digest 为前 Step 个步骤的摘要
+type ChunkPushProgress struct {
+	Step   int    `json:"step"`
+	Commit string `json:"commit,omitempty"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// ChunkedPush 分批推送裸仓库到目标仓库，适用于单次推送因包过大或超时失败的超大仓库，推送成功后同 Push 一样推送LFS文件
+// 先沿每个分支的 first-parent 历史每隔 StepSize 个提交推送一次临时引用，再分组推送分支和标签
+// 参数:
+//   - repoPath: 本地裸仓库路径
+//   - pushURL: 推送目标 URL
+//   - opts: 分批推送配置
+//   - resume: 上次运行已确认完成的进度，从其后的步骤继续推送
+//   - ack: 每个步骤推送成功后的回调，用于记录进度
+//
+// 返回值:
+//   - string: 失败步骤的命令输出（已屏蔽敏感信息）
+//   - error: 推送失败时返回错误信息
+func ChunkedPush(repoPath, pushURL string, opts ChunkPushOptions, resume ChunkPushProgress, ack func(ChunkPushProgress)) (string, error) {
+	logger.Repo(repoPath).Infof("%s 开始分批push", repoPath)
+	out, err := chunkedCodePush(repoPath, pushURL, opts, resume, ack)
+	return finishPush(repoPath, pushURL, out, err, opts.Env)
+}
+
+// chunkedCodePush 按步骤分批推送代码，不包含LFS文件
+func chunkedCodePush(repoPath, pushURL string, opts ChunkPushOptions, resume ChunkPushProgress, ack func(ChunkPushProgress)) (string, error) {
+	if opts.Force {
+		logger.Repo(repoPath).Warnf("%s 即将执行强制推送(git push -f),此操作将覆盖目标仓库的历史记录,请确保您了解此操作的风险", repoPath)
+	}
+	steps, err := planChunkPush(repoPath, opts)
+	if err != nil {
+		return "", err
+	}
+	start := resumeStep(steps, resume)
+	if start > 0 {
+		logger.Repo(repoPath).Infof("%s 从第 %d/%d 步继续分批推送", repoPath, start+1, len(steps))
+	}
+	for i := start; i < len(steps); i++ {
+		step := steps[i]
+		refspecs := step.Refspecs
+		force := opts.Force
+		if step.Commit != "" {
+			// 临时引用在不同分支的检查点之间移动，需要强制更新
+			refspecs = []string{"+" + step.Commit + ":" + ChunkPushTempRef}
+			force = false
+		}
+		logger.Repo(repoPath).Infof("%s 分批推送第 %d/%d 步", repoPath, i+1, len(steps))
+		output, err := pushRefspecs(repoPath, pushURL, force, refspecs, opts.Env)
+		if err != nil {
+			return output, fmt.Errorf("分批推送第 %d/%d 步失败: %w", i+1, len(steps), err)
+		}
+		if ack != nil {
+			ack(ChunkPushProgress{Step: i + 1, Commit: step.Commit, Digest: stepsDigest(steps[:i+1])})
+		}
+	}
+	// 清理临时引用，失败不影响迁移结果
+	if output, err := system.RunCommandWithEnv(opts.Env, "git", repoPath, "push", pushURL, ":"+ChunkPushTempRef); err != nil {
+		logger.Repo(repoPath).Debugf("%s 删除临时引用 %s 失败: %s\n%s", repoPath, ChunkPushTempRef, err, removeCredentialsFromURL(output))
+	}
+	logger.Repo(repoPath).Infof("%s 分批推送完成，共 %d 步", repoPath, len(steps))
+	return "", nil
+}
+
+// resumeStep 根据上次进度计算起始步骤下标，步骤数不足或已完成步骤发生变化（历史提交或分支/标签分组不一致）时从头开始
+func resumeStep(steps []ChunkPushStep, resume ChunkPushProgress) int {
+	if resume.Step <= 0 || resume.Step > len(steps) {
+		return 0
+	}
+	if steps[resume.Step-1].Commit != resume.Commit || stepsDigest(steps[:resume.Step]) != resume.Digest {
+		return 0
+	}
+	return resume.Step
+}
+
+// stepsDigest 计算步骤列表的摘要，用于判断上次已完成的步骤与本次计划是否一致
+func stepsDigest(steps []ChunkPushStep) string {
+	h := sha1.New()
+	for _, step := range steps {
+		fmt.Fprintf(h, "%s\n%s\n\n", step.Commit, strings.Join(step.Refspecs, "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// planChunkPush 生成分批推送步骤：历史提交检查点、分支分组、标签分组
+func planChunkPush(repoPath string, opts ChunkPushOptions) ([]ChunkPushStep, error) {
+	branches, tags, err := selectedRefs(repoPath, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	branches = sortBranchesDefaultFirst(branches, defaultBranchRef(repoPath))
+
+	var steps []ChunkPushStep
+	seen := make(map[string]bool)
+	for _, branch := range branches {
+		output, err := system.RunCommand("git", repoPath, "rev-list", "--first-parent", "--reverse", branch)
+		if err != nil {
+			return nil, fmt.Errorf("%s 获取分支 %s 提交列表失败: %s\n%s", repoPath, branch, err, output)
+		}
+		for _, commit := range chunkCheckpoints(strings.Fields(output), opts.StepSize) {
+			if seen[commit] {
+				continue
+			}
+			seen[commit] = true
+			steps = append(steps, ChunkPushStep{Commit: commit})
+		}
+	}
+	for _, group := range groupRefspecs(branches, opts.RefBatchSize) {
+		steps = append(steps, ChunkPushStep{Refspecs: group})
+	}
+	for _, group := range groupRefspecs(tags, opts.RefBatchSize) {
+		steps = append(steps, ChunkPushStep{Refspecs: group})
+	}
+	return steps, nil
+}
+
+// chunkCheckpoints 每隔 stepSize 个提交取一个检查点，最后一个提交由分支推送覆盖，不作为检查点
+func chunkCheckpoints(commits []string, stepSize int) []string {
+	if stepSize <= 0 {
+		return nil
+	}
+	var checkpoints []string
+	for i := stepSize - 1; i < len(commits)-1; i += stepSize {
+		checkpoints = append(checkpoints, commits[i])
+	}
+	return checkpoints
+}
+
+// groupRefspecs 将引用按 batchSize 分组，生成 refs/x:refs/x 形式的 refspec
+func groupRefspecs(refs []string, batchSize int) [][]string {
+	if batchSize <= 0 {
+		batchSize = len(refs)
+	}
+	var groups [][]string
+	for i := 0; i < len(refs); i += batchSize {
+		end := i + batchSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+		var group []string
+		for _, ref := range refs[i:end] {
+			group = append(group, ref+":"+ref)
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// sortBranchesDefaultFirst 默认分支优先，其余按名称排序，保证每次生成的步骤顺序一致
+func sortBranchesDefaultFirst(branches []string, defaultBranch string) []string {
+	sorted := append([]string(nil), branches...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i] == defaultBranch || sorted[j] == defaultBranch {
+			return sorted[i] == defaultBranch && sorted[j] != defaultBranch
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+// defaultBranchRef 获取裸仓库 HEAD 指向的分支，获取失败返回空字符串
+func defaultBranchRef(repoPath string) string {
+	output, err := system.RunCommand("git", repoPath, "symbolic-ref", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// listRefs 列出指定前缀下的全部引用
+func listRefs(repoPath, prefix string) ([]string, error) {
+	output, err := system.RunCommand("git", repoPath, "for-each-ref", "--format=%(refname)", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("%s 获取引用列表失败: %s\n%s", repoPath, err, output)
+	}
+	return strings.Fields(output), nil
+}
+
+// pushRefspecs 推送指定 refspec（带重试机制），重试间隔分别为1秒、5秒、10秒
+func pushRefspecs(repoPath, pushURL string, force bool, refspecs []string, env []string) (output string, err error) {
+	retryIntervals := []time.Duration{1 * time.Second, 5 * time.Second, 10 * time.Second}
+	args := []string{"push"}
+	if force {
+		args = append(args, "-f")
+	}
+	args = append(args, pushURL)
+	args = append(args, refspecs...)
+	for i, interval := range retryIntervals {
+		log := logger.Repo(repoPath).With("attempt", i+1)
+		log.Debugf("%s git push %s (尝试 %d/%d)", repoPath, strings.Join(refspecs, " "), i+1, len(retryIntervals))
+		output, err = system.RunCommandWithEnv(env, "git", repoPath, args...)
+		if err == nil {
+			return output, nil
+		}
+		output = removeCredentialsFromURL(output)
+		log.Warnf("%s git push 失败 (尝试 %d/%d): %v \n %s", repoPath, i+1, len(retryIntervals), err, output)
+		if i < len(retryIntervals)-1 {
+			time.Sleep(interval)
+		}
+	}
+	return output, err
+}
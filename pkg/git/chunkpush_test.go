@@ -0,0 +1,87 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChunkCheckpoints(t *testing.T) {
+	commits := []string{"c1", "c2", "c3", "c4", "c5", "c6", "c7"}
+	tests := []struct {
+		name     string
+		stepSize int
+		expected []string
+	}{
+		{name: "每2个提交", stepSize: 2, expected: []string{"c2", "c4", "c6"}},
+		{name: "最后一个提交不作为检查点", stepSize: 7, expected: nil},
+		{name: "步长大于提交数", stepSize: 10, expected: nil},
+		{name: "步长为0", stepSize: 0, expected: nil},
+		{name: "每3个提交", stepSize: 3, expected: []string{"c3", "c6"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := chunkCheckpoints(commits, tt.stepSize); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("chunkCheckpoints() = %v, 期望 %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGroupRefspecs(t *testing.T) {
+	refs := []string{"refs/heads/a", "refs/heads/b", "refs/heads/c"}
+	expected := [][]string{
+		{"refs/heads/a:refs/heads/a", "refs/heads/b:refs/heads/b"},
+		{"refs/heads/c:refs/heads/c"},
+	}
+	if result := groupRefspecs(refs, 2); !reflect.DeepEqual(result, expected) {
+		t.Errorf("groupRefspecs() = %v, 期望 %v", result, expected)
+	}
+	if result := groupRefspecs(refs, 0); len(result) != 1 || len(result[0]) != 3 {
+		t.Errorf("batchSize 为0时应放在同一组: %v", result)
+	}
+	if result := groupRefspecs(nil, 2); len(result) != 0 {
+		t.Errorf("无引用时不应生成分组: %v", result)
+	}
+}
+
+func TestSortBranchesDefaultFirst(t *testing.T) {
+	branches := []string{"refs/heads/dev", "refs/heads/main", "refs/heads/a"}
+	expected := []string{"refs/heads/main", "refs/heads/a", "refs/heads/dev"}
+	if result := sortBranchesDefaultFirst(branches, "refs/heads/main"); !reflect.DeepEqual(result, expected) {
+		t.Errorf("sortBranchesDefaultFirst() = %v, 期望 %v", result, expected)
+	}
+}
+
+func TestResumeStep(t *testing.T) {
+	steps := []ChunkPushStep{
+		{Commit: "c1"},
+		{Commit: "c2"},
+		{Refspecs: []string{"refs/heads/main:refs/heads/main"}},
+	}
+	changedSteps := []ChunkPushStep{
+		{Commit: "c1"},
+		{Commit: "c2"},
+		{Refspecs: []string{"refs/heads/dev:refs/heads/dev"}},
+	}
+	tests := []struct {
+		name     string
+		resume   ChunkPushProgress
+		expected int
+	}{
+		{name: "无进度", resume: ChunkPushProgress{}, expected: 0},
+		{name: "检查点一致", resume: ChunkPushProgress{Step: 2, Commit: "c2", Digest: stepsDigest(steps[:2])}, expected: 2},
+		{name: "检查点不一致", resume: ChunkPushProgress{Step: 2, Commit: "cx", Digest: stepsDigest(steps[:2])}, expected: 0},
+		{name: "分支步骤", resume: ChunkPushProgress{Step: 3, Digest: stepsDigest(steps)}, expected: 3},
+		{name: "分支分组变化", resume: ChunkPushProgress{Step: 3, Digest: stepsDigest(changedSteps)}, expected: 0},
+		{name: "之前的检查点变化", resume: ChunkPushProgress{Step: 2, Commit: "c2", Digest: stepsDigest([]ChunkPushStep{{Commit: "cx"}, {Commit: "c2"}})}, expected: 0},
+		{name: "缺少摘要", resume: ChunkPushProgress{Step: 3}, expected: 0},
+		{name: "超出步骤数", resume: ChunkPushProgress{Step: 4}, expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := resumeStep(steps, tt.resume); result != tt.expected {
+				t.Errorf("resumeStep() = %d, 期望 %d", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package git
+
+import (
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/system"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// gerritChangesPrefix Gerrit 变更补丁集引用前缀，格式为 refs/changes/<变更号后两位>/<变更号>/<补丁集号>
+const gerritChangesPrefix = "refs/changes/"
+
+// MirrorChangeRefs 将未合入变更的补丁集引用复制为 refs/heads/<namespace>/<变更号>/<补丁集号> 分支，随分支一起推送到 CNB
+// 返回值:
+//   - int: 创建的分支数
+//   - error: 创建失败时返回错误信息
+func MirrorChangeRefs(repoPath, namespace string, openChanges []int) (int, error) {
+	if len(openChanges) == 0 {
+		return 0, nil
+	}
+	open := make(map[int]bool, len(openChanges))
+	for _, number := range openChanges {
+		open[number] = true
+	}
+	refs, err := listRefs(repoPath, gerritChangesPrefix)
+	if err != nil {
+		return 0, err
+	}
+	mirrored := 0
+	for _, ref := range refs {
+		branch, ok := changeBranch(ref, namespace, open)
+		if !ok {
+			continue
+		}
+		output, err := system.RunCommand("git", repoPath, "update-ref", branch, ref)
+		if err != nil {
+			return mirrored, fmt.Errorf("%s 创建分支 %s 失败: %s\n%s", repoPath, branch, err, output)
+		}
+		mirrored++
+	}
+	logger.Repo(repoPath).Infof("%s 已将 %d 个未合入变更的 %d 个补丁集复制到 refs/heads/%s/", repoPath, len(openChanges), mirrored, namespace)
+	return mirrored, nil
+}
+
+// changeBranch 返回补丁集引用对应的分支，忽略已合入或放弃的变更及 NoteDb 的 refs/changes/xx/<变更号>/meta 等非补丁集引用
+func changeBranch(ref, namespace string, open map[int]bool) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(ref, gerritChangesPrefix), "/")
+	if !strings.HasPrefix(ref, gerritChangesPrefix) || len(parts) != 3 {
+		return "", false
+	}
+	number, err := strconv.Atoi(parts[1])
+	if err != nil || !open[number] {
+		return "", false
+	}
+	if _, err := strconv.Atoi(parts[2]); err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("refs/heads/%s/%d/%s", strings.Trim(namespace, "/"), number, parts[2]), true
+}
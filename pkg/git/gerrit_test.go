@@ -0,0 +1,26 @@
+package git
+
+import "testing"
+
+func TestChangeBranch(t *testing.T) {
+	open := map[int]bool{1234: true}
+	tests := []struct {
+		ref    string
+		want   string
+		wantOk bool
+	}{
+		{ref: "refs/changes/34/1234/5", want: "refs/heads/gerrit/changes/1234/5", wantOk: true},
+		{ref: "refs/changes/34/1234/meta"},
+		{ref: "refs/changes/35/1235/1"},
+		{ref: "refs/heads/main"},
+		{ref: "refs/changes/34/1234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, ok := changeBranch(tt.ref, "gerrit/changes/", open)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("changeBranch() = %q, %v, 期望 %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -2,13 +2,12 @@ package git
 
 import (
 	"ccrctl/pkg/api/coding"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/logger"
 	"ccrctl/pkg/system"
 	"fmt"
+	"io/fs"
 	"net/url"
-	"os"
-	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -32,20 +31,19 @@ const (
 	lfsLsFilesAllCMD                = "git lfs ls-files --all"
 )
 
-var FileLimitSize = config.Cfg.GetString("migrate.file_limit_size")
-
 // Clone 镜像克隆Git仓库（带重试机制）
 // 参数:
 //   - cloneURL: Git仓库克隆地址
 //   - repoPath: 本地仓库路径
 //   - allowIncompletePush: 是否允许不完整的LFS推送
+//   - env: 追加的环境变量，如 SSH 认证使用的 GIT_SSH_COMMAND
 //
 // 返回值:
 //   - error: 克隆失败时返回错误信息
 //
 // 重试机制: 失败时会自动重试3次，重试间隔分别为1秒、5秒、10秒
-func Clone(cloneURL, repoPath string, allowIncompletePush bool) error {
-	logger.Logger.Infof("%s 开始clone", repoPath)
+func Clone(cloneURL, repoPath string, allowIncompletePush bool, env []string) error {
+	logger.Repo(repoPath).Infof("%s 开始clone", repoPath)
 	// 重试间隔配置：第1次失败后等1秒，第2次失败后等5秒，第3次失败后等10秒
 	retryIntervals := []time.Duration{1 * time.Second, 5 * time.Second, 10 * time.Second}
 	var out string
@@ -53,17 +51,18 @@ func Clone(cloneURL, repoPath string, allowIncompletePush bool) error {
 
 	// 重试循环：最多尝试3次
 	for i, interval := range retryIntervals {
+		log := logger.Repo(repoPath).With("attempt", i+1)
 		cmd := fmt.Sprintf("git clone --mirror %s %s", cloneURL, repoPath)
-		logger.Logger.Debugf(cmd)
-		logger.Logger.Infof("%s git 克隆中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
-		out, err = system.ExecCommand(cmd, "./")
+		log.Debugf(cmd)
+		log.Infof("%s git 克隆中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
+		out, err = system.RunCommandWithEnv(env, "git", "", "clone", "--mirror", cloneURL, repoPath)
 		if err == nil {
 			// 克隆成功，跳出重试循环
 			break
 		}
 		// 克隆失败，屏蔽错误日志中的敏感信息（如Git凭证）
 		maskedOutput := removeCredentialsFromURL(out)
-		logger.Logger.Warnf("%s git clone 失败 (尝试 %d/%d): %v \n %s", repoPath, i+1, len(retryIntervals), err, maskedOutput)
+		log.Warnf("%s git clone 失败 (尝试 %d/%d): %v \n %s", repoPath, i+1, len(retryIntervals), err, maskedOutput)
 		// 如果不是最后一次尝试，则等待指定时间后重试
 		if i < len(retryIntervals)-1 {
 			time.Sleep(interval)
@@ -77,11 +76,11 @@ func Clone(cloneURL, repoPath string, allowIncompletePush bool) error {
 	}
 
 	// 克隆成功后，下载LFS文件
-	out, err = FetchLFS(repoPath, allowIncompletePush)
+	out, err = FetchLFS(repoPath, allowIncompletePush, env)
 	if err != nil {
 		return fmt.Errorf("%s 下载LFS文件失败: %s\n %s", repoPath, err, out)
 	}
-	logger.Logger.Infof("%s clone成功", repoPath)
+	logger.Repo(repoPath).Infof("%s clone成功", repoPath)
 	return nil
 }
 
@@ -95,7 +94,7 @@ func Clone(cloneURL, repoPath string, allowIncompletePush bool) error {
 //
 // 重试机制: 失败时会自动重试3次，重试间隔分别为1秒、5秒、10秒
 func NormalClone(cloneURL, repoPath string) error {
-	logger.Logger.Infof("%s 开始clone", repoPath)
+	logger.Repo(repoPath).Infof("%s 开始clone", repoPath)
 	// 重试间隔配置：第1次失败后等1秒，第2次失败后等5秒，第3次失败后等10秒
 	retryIntervals := []time.Duration{1 * time.Second, 5 * time.Second, 10 * time.Second}
 	var out string
@@ -103,18 +102,19 @@ func NormalClone(cloneURL, repoPath string) error {
 
 	// 重试循环：最多尝试3次
 	for i, interval := range retryIntervals {
+		log := logger.Repo(repoPath).With("attempt", i+1)
 		cmd := fmt.Sprintf("git clone %s %s", cloneURL, repoPath)
-		logger.Logger.Debugf(cmd)
-		logger.Logger.Infof("%s git 克隆中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
-		out, err = system.ExecCommand(cmd, "./")
+		log.Debugf(cmd)
+		log.Infof("%s git 克隆中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
+		out, err = system.RunCommand("git", "", "clone", cloneURL, repoPath)
 		if err == nil {
 			// 克隆成功，直接返回
-			logger.Logger.Infof("%s clone成功", repoPath)
+			log.Infof("%s clone成功", repoPath)
 			return nil
 		}
 		// 克隆失败，屏蔽错误日志中的敏感信息（如Git凭证）
 		maskedOutput := removeCredentialsFromURL(out)
-		logger.Logger.Warnf("%s git clone 失败 (尝试 %d/%d): %v \n %s", repoPath, i+1, len(retryIntervals), err, maskedOutput)
+		log.Warnf("%s git clone 失败 (尝试 %d/%d): %v \n %s", repoPath, i+1, len(retryIntervals), err, maskedOutput)
 		// 如果不是最后一次尝试，则等待指定时间后重试
 		if i < len(retryIntervals)-1 {
 			time.Sleep(interval)
@@ -132,14 +132,15 @@ func NormalClone(cloneURL, repoPath string) error {
 // 参数:
 //   - cloneURL: Git仓库克隆地址
 //   - repoPath: 本地仓库路径
+//   - env: 追加的环境变量，如 SSH 认证使用的 GIT_SSH_COMMAND
 //
 // 返回值:
 //   - string: Git命令的输出内容（成功或失败时都会返回，失败时已屏蔽敏感信息）
 //   - error: 克隆失败时返回错误信息
 //
 // 重试机制: 失败时会自动重试3次，重试间隔分别为1秒、5秒、10秒
-func NormalCloneWithOutput(cloneURL, repoPath string) (string, error) {
-	logger.Logger.Infof("%s 开始clone", repoPath)
+func NormalCloneWithOutput(cloneURL, repoPath string, env []string) (string, error) {
+	logger.Repo(repoPath).Infof("%s 开始clone", repoPath)
 	// 重试间隔配置：第1次失败后等1秒，第2次失败后等5秒，第3次失败后等10秒
 	retryIntervals := []time.Duration{1 * time.Second, 5 * time.Second, 10 * time.Second}
 	var out string
@@ -147,18 +148,19 @@ func NormalCloneWithOutput(cloneURL, repoPath string) (string, error) {
 
 	// 重试循环：最多尝试3次
 	for i, interval := range retryIntervals {
+		log := logger.Repo(repoPath).With("attempt", i+1)
 		cmd := fmt.Sprintf("git clone %s %s", cloneURL, repoPath)
-		logger.Logger.Debugf(cmd)
-		logger.Logger.Infof("%s git 克隆中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
-		out, err = system.ExecCommand(cmd, "./")
+		log.Debugf(cmd)
+		log.Infof("%s git 克隆中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
+		out, err = system.RunCommandWithEnv(env, "git", "", "clone", cloneURL, repoPath)
 		if err == nil {
 			// 克隆成功，返回原始输出内容（可能包含空仓库警告等信息）
-			logger.Logger.Infof("%s clone成功", repoPath)
+			log.Infof("%s clone成功", repoPath)
 			return out, nil
 		}
 		// 克隆失败，屏蔽错误日志中的敏感信息（如Git凭证）
 		maskedOutput := removeCredentialsFromURL(out)
-		logger.Logger.Warnf("%s git clone 失败 (尝试 %d/%d): %v \n %s", repoPath, i+1, len(retryIntervals), err, maskedOutput)
+		log.Warnf("%s git clone 失败 (尝试 %d/%d): %v \n %s", repoPath, i+1, len(retryIntervals), err, maskedOutput)
 		// 如果不是最后一次尝试，则等待指定时间后重试
 		if i < len(retryIntervals)-1 {
 			time.Sleep(interval)
@@ -227,25 +229,19 @@ func RebasePush(rebaseRepoPath string, rebaseSuccessBranches []string) error {
 	return nil
 }
 
-func Rebase(rebaseRepoPath, repoPath string) error {
-	logger.Logger.Infof("%s 开始rebase", rebaseRepoPath)
-	pwdDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("%s 获取当前目录失败: %s", rebaseRepoPath, err)
-	}
-	logger.Logger.Debugf("pwd: %s", pwdDir)
-	bareRepoPath := path.Join(pwdDir, repoPath)
-	logger.Logger.Debugf("bareRepoPath: %s", bareRepoPath)
-	out, err := system.RunCommand("git", rebaseRepoPath, "remote", "add", SourceOriginName, bareRepoPath)
+// Rebase 将 CNB 侧仓库 rebaseRepoPath 的各分支 rebase 到源仓库对应分支上，repoPath 为源仓库镜像克隆的本地目录
+func Rebase(rebaseRepoPath, repoPath string, filter RefFilter) error {
+	logger.Repo(repoPath).Infof("%s 开始rebase", rebaseRepoPath)
+	out, err := system.RunCommand("git", rebaseRepoPath, "remote", "add", SourceOriginName, repoPath)
 	if err != nil {
 		return fmt.Errorf("%s 添加source远程仓库失败: %s\n %s", rebaseRepoPath, err, out)
 	}
-	logger.Logger.Infof("%s 添加source远程仓库成功", rebaseRepoPath)
+	logger.Repo(repoPath).Infof("%s 添加source远程仓库成功", rebaseRepoPath)
 	out, err = system.RunCommand("git", rebaseRepoPath, "fetch", SourceOriginName)
 	if err != nil {
 		return fmt.Errorf("%s 拉取souce远程仓库失败: %s\n %s", rebaseRepoPath, err, out)
 	}
-	logger.Logger.Infof("%s 拉取souce远程仓库成功", rebaseRepoPath)
+	logger.Repo(repoPath).Infof("%s 拉取souce远程仓库成功", rebaseRepoPath)
 	// 如果没有指定rebaseBranches, 则遍历所有分支进行处理
 	// 获取所有远程分支
 	out, err = system.ExecCommand(GetOriginBranchesCommand, rebaseRepoPath)
@@ -265,10 +261,14 @@ func Rebase(rebaseRepoPath, repoPath string) error {
 		branch = strings.TrimPrefix(branch, "origin/")
 		branches = append(branches, branch)
 	}
-	logger.Logger.Infof("%s 分支列表: %s", rebaseRepoPath, branches)
+	logger.Repo(repoPath).Infof("%s 分支列表: %s", rebaseRepoPath, branches)
 
 	// 遍历所有分支进行rebase
 	for _, branch := range branches {
+		if !filter.Match("refs/heads/" + branch) {
+			logger.Repo(repoPath).Infof("%s 分支 %s 不符合引用过滤规则，跳过 rebase", rebaseRepoPath, branch)
+			continue
+		}
 		// 切换到指定分支
 		checkBranchErr := checkoutBranch(rebaseRepoPath, branch)
 		if checkBranchErr != nil {
@@ -278,7 +278,7 @@ func Rebase(rebaseRepoPath, repoPath string) error {
 		// CNBYamlFileAbsPath := path.Join(rebaseRepoPath, CNBYamlFileName)
 		// exist := system.FileExists(CNBYamlFileAbsPath)
 		// if !exist {
-		// 	logger.Logger.Infof("%s分支%s .cnb.yml文件不存在,跳过 rebease", rebaseRepoPath, branch)
+		// 	logger.Repo(repoPath).Infof("%s分支%s .cnb.yml文件不存在,跳过 rebease", rebaseRepoPath, branch)
 		// 	continue
 		// }
 		rebaseBranch := SourceOriginName + "/" + branch
@@ -287,51 +287,62 @@ func Rebase(rebaseRepoPath, repoPath string) error {
 		if rebaseErr != nil {
 			// 检查是否是分支不存在的情况
 			if isInvalidUpstreamError(rebaseOut) {
-				logger.Logger.Warnf("%s 分支 %s 在源仓库中不存在，跳过 rebase", rebaseRepoPath, branch)
+				logger.Repo(repoPath).Warnf("%s 分支 %s 在源仓库中不存在，跳过 rebase", rebaseRepoPath, branch)
 				continue
 			}
 			return fmt.Errorf("仓库 %s 分支 %s rebase失败: %s\n %s", repoPath, branch, rebaseErr.Error(), rebaseOut)
 		}
-		logger.Logger.Infof("%s %s rebase成功", rebaseRepoPath, rebaseBranch)
+		logger.Repo(repoPath).Infof("%s %s rebase成功", rebaseRepoPath, rebaseBranch)
 		rebasePushOut, rebasePushErr := system.ExecCommand(GitPushToLocalBareRepo, rebaseRepoPath)
 		if rebasePushErr != nil {
 			return fmt.Errorf("分支 %s rebase后push失败: %s\n %s", branch, rebasePushErr.Error(), rebasePushOut)
 		}
-		logger.Logger.Infof("%s %s rebase后push成功", rebaseRepoPath, rebaseBranch)
+		logger.Repo(repoPath).Infof("%s %s rebase后push成功", rebaseRepoPath, rebaseBranch)
 	}
 	return nil
 }
 
-func Push(repoPath, pushURL string, forcePush bool) (output string, err error) {
-	logger.Logger.Infof("%s 开始push", repoPath)
-	out, err := codePush(repoPath, pushURL, repoPath, forcePush)
+// Push 推送裸仓库的分支和标签，配置了引用过滤规则时只推送匹配的引用，env 为追加的环境变量
+func Push(repoPath, pushURL string, forcePush bool, filter RefFilter, env []string) (output string, err error) {
+	logger.Repo(repoPath).Infof("%s 开始push", repoPath)
+	var out string
+	if filter.Active() {
+		out, err = filteredCodePush(repoPath, pushURL, forcePush, filter, env)
+	} else {
+		out, err = codePush(repoPath, pushURL, repoPath, forcePush, env)
+	}
+	return finishPush(repoPath, pushURL, out, err, env)
+}
+
+// finishPush 处理代码推送结果，推送成功后检查并推送LFS文件
+func finishPush(repoPath, pushURL, out string, err error, env []string) (string, error) {
 	if err != nil {
 		// 如果是大文件超限错误，使用 WARN 级别（系统会自动处理）
 		if IsExceededLimitError(out) {
-			logger.Logger.Warnf("%s 裸仓push失败(文件超过大小限制，系统将自动处理): %s", repoPath, err)
+			logger.Repo(repoPath).Warnf("%s 裸仓push失败(文件超过大小限制，系统将自动处理): %s", repoPath, err)
 		} else {
 			// 其他错误仍使用 ERROR 级别
-			logger.Logger.Errorf("%s 裸仓push失败: %s", repoPath, err)
+			logger.Repo(repoPath).Errorf("%s 裸仓push失败: %s", repoPath, err)
 		}
 		return out, err
 	}
-	logger.Logger.Infof("%s 裸仓push成功", repoPath)
+	logger.Repo(repoPath).Infof("%s 裸仓push成功", repoPath)
 
 	// 检查是否有LFS文件，如有则推送LFS
 	hasLFSFiles, lfsCheckErr := hasLFSFiles(repoPath)
 	if lfsCheckErr != nil {
-		logger.Logger.Warnf("%s 检查LFS文件失败: %s，跳过LFS推送", repoPath, lfsCheckErr)
+		logger.Repo(repoPath).Warnf("%s 检查LFS文件失败: %s，跳过LFS推送", repoPath, lfsCheckErr)
 		return out, nil
 	}
 
 	if hasLFSFiles {
-		logger.Logger.Infof("%s 检测到LFS文件", repoPath)
-		out, err = PushLFS(repoPath, pushURL)
+		logger.Repo(repoPath).Infof("%s 检测到LFS文件", repoPath)
+		out, err = PushLFS(repoPath, pushURL, env)
 		if err != nil {
 			return out, err
 		}
 	} else {
-		logger.Logger.Infof("%s 未检测到LFS文件，跳过LFS推送", repoPath)
+		logger.Repo(repoPath).Infof("%s 未检测到LFS文件，跳过LFS推送", repoPath)
 	}
 
 	return out, nil
@@ -395,29 +406,30 @@ func buildURLString(u *url.URL) string {
 	return result.String()
 }
 
-func codePush(workDir, pushURL, repoPath string, force bool) (output string, err error) {
+func codePush(workDir, pushURL, repoPath string, force bool, env []string) (output string, err error) {
 	// 强制推送警告:提醒用户此操作的风险性
 	if force {
-		logger.Logger.Warnf("%s 即将执行强制推送(git push -f),此操作将覆盖目标仓库的历史记录,请确保您了解此操作的风险", repoPath)
+		logger.Repo(repoPath).Warnf("%s 即将执行强制推送(git push -f),此操作将覆盖目标仓库的历史记录,请确保您了解此操作的风险", repoPath)
 	}
 
 	retryIntervals := []time.Duration{1 * time.Second, 5 * time.Second, 10 * time.Second}
 	var cmd string
 	for i, interval := range retryIntervals {
+		log := logger.Repo(repoPath).With("attempt", i+1)
 		if force {
 			cmd = fmt.Sprintf(pushForceCMD, pushURL)
 		} else {
 			cmd = fmt.Sprintf(pushCMD, pushURL)
 		}
-		logger.Logger.Debugf(cmd)
-		logger.Logger.Infof("%s git 推送中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
-		output, err = system.ExecCommand(cmd, workDir)
+		log.Debugf(cmd)
+		log.Infof("%s git 推送中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
+		output, err = system.ExecCommandWithEnv(env, cmd, workDir)
 		if err == nil {
 			return output, nil
 		}
 		// 屏蔽错误日志中的敏感信息
 		output = removeCredentialsFromURL(output)
-		logger.Logger.Warnf("%s git push 失败 (尝试 %d/%d): %v \n %s", repoPath, i+1, len(retryIntervals), err, output)
+		log.Warnf("%s git push 失败 (尝试 %d/%d): %v \n %s", repoPath, i+1, len(retryIntervals), err, output)
 		if i < len(retryIntervals)-1 {
 			time.Sleep(interval)
 		}
@@ -428,21 +440,38 @@ func codePush(workDir, pushURL, repoPath string, force bool) (output string, err
 func IsLFSRepo(repoPath string) (error, bool) {
 	workDir := repoPath
 	output, err := system.RunCommand("git", workDir, "lfs", "ls-files", "--all")
-	logger.Logger.Debugf("%s 检查是否是LFS仓库\n%s", repoPath, output)
+	logger.Repo(repoPath).Debugf("%s 检查是否是LFS仓库\n%s", repoPath, output)
 	if err != nil {
 		return err, false
 	}
 	return nil, len(output) > 0
 }
 
+// CountLFSObjects 统计仓库本地缓存的 LFS 对象数，裸仓库位于 lfs/objects，普通仓库位于 .git/lfs/objects
+func CountLFSObjects(repoPath string) int {
+	count := 0
+	for _, dir := range []string{filepath.Join(repoPath, "lfs", "objects"), filepath.Join(repoPath, ".git", "lfs", "objects")} {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.Type().IsRegular() {
+				count++
+			}
+			return nil
+		})
+	}
+	return count
+}
+
 // hasLFSFiles 检查仓库是否有LFS文件
 func hasLFSFiles(repoPath string) (bool, error) {
-	logger.Logger.Debugf("%s 检查是否有LFS文件", repoPath)
+	logger.Repo(repoPath).Debugf("%s 检查是否有LFS文件", repoPath)
 
 	// 使用 git lfs ls-files --all 检查是否有实际的LFS文件
 	output, err := system.ExecCommand(lfsLsFilesAllCMD, repoPath)
 	if err != nil {
-		logger.Logger.Errorf("%s 执行git lfs ls-files --all失败: %s", repoPath, err)
+		logger.Repo(repoPath).Errorf("%s 执行git lfs ls-files --all失败: %s", repoPath, err)
 		return false, err
 	}
 
@@ -451,9 +480,9 @@ func hasLFSFiles(repoPath string) (bool, error) {
 	hasFiles := len(trimmedOutput) > 0
 
 	if hasFiles {
-		logger.Logger.Debugf("%s 检测到LFS文件:\n%s", repoPath, trimmedOutput)
+		logger.Repo(repoPath).Debugf("%s 检测到LFS文件:\n%s", repoPath, trimmedOutput)
 	} else {
-		logger.Logger.Debugf("%s 未检测到LFS文件", repoPath)
+		logger.Repo(repoPath).Debugf("%s 未检测到LFS文件", repoPath)
 	}
 
 	return hasFiles, nil
@@ -463,15 +492,17 @@ func hasLFSFiles(repoPath string) (bool, error) {
 // 参数:
 //   - repoPath: 本地仓库路径
 //   - allowIncompletePush: 是否允许不完整的 LFS 推送
+//   - env: 追加的环境变量，如 SSH 认证使用的 GIT_SSH_COMMAND
 //
 // 返回值:
 //   - string: 命令输出（已屏蔽敏感信息）
 //   - error: 错误信息
 //
 // 重试机制: 失败时会自动重试 3 次，重试间隔分别为 2 秒、5 秒、10 秒
-func FetchLFS(repoPath string, allowIncompletePush bool) (string, error) {
+func FetchLFS(repoPath string, allowIncompletePush bool, env []string) (string, error) {
 	workDir := repoPath
-	logger.Logger.Infof("%s 开始下载 LFS 文件", repoPath)
+	logger.SetPhase(repoPath, logger.PhaseLFS)
+	logger.Repo(repoPath).Infof("%s 开始下载 LFS 文件", repoPath)
 
 	// 重试间隔配置：第 1 次失败后等 2 秒，第 2 次失败后等 5 秒，第 3 次失败后等 10 秒
 	retryIntervals := []time.Duration{2 * time.Second, 5 * time.Second, 10 * time.Second}
@@ -480,24 +511,25 @@ func FetchLFS(repoPath string, allowIncompletePush bool) (string, error) {
 
 	// 重试循环：最多尝试 3 次
 	for i, interval := range retryIntervals {
-		logger.Logger.Infof("%s 下载 LFS 文件中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
-		output, err = system.RunCommand("git", workDir, "lfs", "fetch", "--all", "origin")
+		log := logger.Repo(repoPath).With("attempt", i+1)
+		log.Infof("%s 下载 LFS 文件中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
+		output, err = system.RunCommandWithEnv(env, "git", workDir, "lfs", "fetch", "--all", "origin")
 
 		if err == nil {
 			// 下载成功
-			logger.Logger.Infof("%s LFS 文件下载成功", repoPath)
+			log.Infof("%s LFS 文件下载成功", repoPath)
 			maskedOutput := removeCredentialsFromURL(output)
-			logger.Logger.Debugf("%s LFS 文件下载输出:\n%s", repoPath, maskedOutput)
+			log.Debugf("%s LFS 文件下载输出:\n%s", repoPath, maskedOutput)
 			return maskedOutput, nil
 		}
 
 		// 下载失败，屏蔽日志输出中的敏感信息
 		maskedOutput := removeCredentialsFromURL(output)
-		logger.Logger.Warnf("%s LFS 文件下载失败 (尝试 %d/%d): %v\n%s", repoPath, i+1, len(retryIntervals), err, maskedOutput)
+		log.Warnf("%s LFS 文件下载失败 (尝试 %d/%d): %v\n%s", repoPath, i+1, len(retryIntervals), err, maskedOutput)
 
 		// 如果不是最后一次尝试，则等待指定时间后重试
 		if i < len(retryIntervals)-1 {
-			logger.Logger.Infof("%s 等待 %v 后重试...", repoPath, interval)
+			log.Infof("%s 等待 %v 后重试...", repoPath, interval)
 			time.Sleep(interval)
 		}
 	}
@@ -509,24 +541,24 @@ func FetchLFS(repoPath string, allowIncompletePush bool) (string, error) {
 	if allowIncompletePush {
 		// 检查是否是 LFS 源文件损坏/丢失的错误
 		if IsLFSObjectNotFoundError(output) {
-			logger.Logger.Warnf("%s 检测到 LFS 源文件损坏/丢失错误（重试 %d 次后），由于开启了 allow_incomplete_push，将忽略错误继续迁移", repoPath, len(retryIntervals))
-			logger.Logger.Infof("%s 正在设置 lfs.allowincompletepush=true", repoPath)
+			logger.Repo(repoPath).Warnf("%s 检测到 LFS 源文件损坏/丢失错误（重试 %d 次后），由于开启了 allow_incomplete_push，将忽略错误继续迁移", repoPath, len(retryIntervals))
+			logger.Repo(repoPath).Infof("%s 正在设置 lfs.allowincompletepush=true", repoPath)
 			configOutput, configErr := system.RunCommand("git", workDir, "config", "lfs.allowincompletepush", "true")
 			if configErr != nil {
 				return removeCredentialsFromURL(configOutput), fmt.Errorf("设置 lfs.allowincompletepush 失败: %w", configErr)
 			}
-			logger.Logger.Warnf("%s 已设置 lfs.allowincompletepush=true，将尝试推送已下载的 LFS 文件", repoPath)
+			logger.Repo(repoPath).Warnf("%s 已设置 lfs.allowincompletepush=true，将尝试推送已下载的 LFS 文件", repoPath)
 			return maskedOutput, nil
 		} else {
 			// 不是源文件损坏错误（可能是网络、权限等可恢复的问题）
-			logger.Logger.Errorf("%s LFS 文件下载失败（重试 %d 次后），错误类型不是源文件损坏，建议检查网络、权限等问题后重试", repoPath, len(retryIntervals))
-			logger.Logger.Errorf("%s 错误详情: %v\n%s", repoPath, err, maskedOutput)
+			logger.Repo(repoPath).Errorf("%s LFS 文件下载失败（重试 %d 次后），错误类型不是源文件损坏，建议检查网络、权限等问题后重试", repoPath, len(retryIntervals))
+			logger.Repo(repoPath).Errorf("%s 错误详情: %v\n%s", repoPath, err, maskedOutput)
 			return maskedOutput, fmt.Errorf("LFS 文件下载失败（非源文件损坏错误）: %w", err)
 		}
 	}
 
 	// 不允许不完整推送，返回错误
-	logger.Logger.Errorf("%s LFS 文件下载失败（重试 %d 次后）", repoPath, len(retryIntervals))
+	logger.Repo(repoPath).Errorf("%s LFS 文件下载失败（重试 %d 次后）", repoPath, len(retryIntervals))
 	return maskedOutput, fmt.Errorf("LFS 文件下载失败: %w", err)
 }
 
@@ -534,14 +566,16 @@ func FetchLFS(repoPath string, allowIncompletePush bool) (string, error) {
 // 参数:
 //   - repoPath: 本地仓库路径
 //   - pushUrl: 推送目标 URL
+//   - env: 追加的环境变量，如 SSH 认证使用的 GIT_SSH_COMMAND
 //
 // 返回值:
 //   - string: 命令输出（已屏蔽敏感信息）
 //   - error: 错误信息
 //
 // 重试机制: 失败时会自动重试 3 次，重试间隔分别为 2 秒、5 秒、10 秒
-func PushLFS(repoPath, pushUrl string) (string, error) {
-	logger.Logger.Infof("%s 开始推送 LFS 文件", repoPath)
+func PushLFS(repoPath, pushUrl string, env []string) (string, error) {
+	logger.SetPhase(repoPath, logger.PhaseLFS)
+	logger.Repo(repoPath).Infof("%s 开始推送 LFS 文件", repoPath)
 	workDir := repoPath
 
 	// 重试间隔配置：第 1 次失败后等 2 秒，第 2 次失败后等 5 秒，第 3 次失败后等 10 秒
@@ -551,44 +585,46 @@ func PushLFS(repoPath, pushUrl string) (string, error) {
 
 	// 重试循环：最多尝试 3 次
 	for i, interval := range retryIntervals {
-		logger.Logger.Infof("%s 推送 LFS 文件中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
-		output, err = system.ExecCommand(fmt.Sprintf(lfsPushCMD, pushUrl), workDir)
+		log := logger.Repo(repoPath).With("attempt", i+1)
+		log.Infof("%s 推送 LFS 文件中... (尝试 %d/%d)", repoPath, i+1, len(retryIntervals))
+		output, err = system.ExecCommandWithEnv(env, fmt.Sprintf(lfsPushCMD, pushUrl), workDir)
 
 		if err == nil {
 			// 推送成功
-			logger.Logger.Infof("%s LFS 文件推送成功", repoPath)
+			log.Infof("%s LFS 文件推送成功", repoPath)
 			return output, nil
 		}
 
 		// 推送失败，屏蔽输出中的敏感信息
 		maskedOutput := removeCredentialsFromURL(output)
-		logger.Logger.Warnf("%s LFS 文件推送失败 (尝试 %d/%d): %v\n%s", repoPath, i+1, len(retryIntervals), err, maskedOutput)
+		log.Warnf("%s LFS 文件推送失败 (尝试 %d/%d): %v\n%s", repoPath, i+1, len(retryIntervals), err, maskedOutput)
 
 		// 如果不是最后一次尝试，则等待指定时间后重试
 		if i < len(retryIntervals)-1 {
-			logger.Logger.Infof("%s 等待 %v 后重试...", repoPath, interval)
+			log.Infof("%s 等待 %v 后重试...", repoPath, interval)
 			time.Sleep(interval)
 		}
 	}
 
 	// 所有重试都失败
 	maskedOutput := removeCredentialsFromURL(output)
-	logger.Logger.Errorf("%s LFS 文件推送失败（重试 %d 次后）", repoPath, len(retryIntervals))
+	logger.Repo(repoPath).Errorf("%s LFS 文件推送失败（重试 %d 次后）", repoPath, len(retryIntervals))
 	return maskedOutput, fmt.Errorf("LFS 文件推送失败: %w", err)
 }
 
-func FixExceededLimitError(repoPath string) error {
+// FixExceededLimitError 使用 git lfs migrate 将历史提交中超过 fileLimitSize(MB) 的文件转换为 LFS 文件
+func FixExceededLimitError(repoPath, fileLimitSize string) error {
 	workDir := repoPath
-	above := "--above=" + FileLimitSize + "Mb"
-	logger.Logger.Infof("%s 使用git lfs migrate 处理历史提交中的大文件", repoPath)
+	above := "--above=" + fileLimitSize + "Mb"
+	logger.Repo(repoPath).Infof("%s 使用git lfs migrate 处理历史提交中的大文件", repoPath)
 	output, err := system.RunCommand("git", workDir, "lfs", "migrate", "import", "--everything", above)
 	if err != nil {
 		// 屏蔽输出中的敏感信息
 		maskedOutput := removeCredentialsFromURL(output)
 		return fmt.Errorf("git lfs migrate import 失败: %s\n%s", err, maskedOutput)
 	}
-	logger.Logger.Infof("%s 使用git lfs migrate 处理历史提交中的大文件成功", repoPath)
-	logger.Logger.Debugf("%s 使用git lfs migrate 处理历史提交中的大文件成功\n%s", repoPath, output)
+	logger.Repo(repoPath).Infof("%s 使用git lfs migrate 处理历史提交中的大文件成功", repoPath)
+	logger.Repo(repoPath).Debugf("%s 使用git lfs migrate 处理历史提交中的大文件成功\n%s", repoPath, output)
 	return nil
 }
 
@@ -623,12 +659,12 @@ func SetCheckOutDefaultRemote() error {
 func checkoutBranch(repoPath, branch string) error {
 	_, err := system.ExecCommand(fmt.Sprintf(CheckoutBranch, branch), repoPath)
 	if err != nil {
-		logger.Logger.Warnf(fmt.Sprintf("%s 切换分支 %s 失败,尝试指定ref切换", repoPath, branch))
+		logger.Repo(repoPath).Warnf("%s 切换分支 %s 失败,尝试指定ref切换", repoPath, branch)
 		refBranch := "refs/remotes/origin/" + branch
 		// 兼容分支名匹配到 tree object 问题
 		out, refErr := system.ExecCommand(fmt.Sprintf(CheckoutBranch, refBranch), repoPath)
 		if refErr != nil {
-			logger.Logger.Errorf(fmt.Sprintf("%s 指定ref切换分支 %s 失败: %s\n%s", repoPath, refBranch, refErr, out))
+			logger.Repo(repoPath).Errorf("%s 指定ref切换分支 %s 失败: %s\n%s", repoPath, refBranch, refErr, out)
 			return refErr
 		}
 	}
@@ -0,0 +1,161 @@
+package git
+
+import (
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/system"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// listObjectsWithSizeCMD 遍历所有引用可达的对象，输出 类型 对象ID 大小 路径
+	listObjectsWithSizeCMD = "git rev-list --objects --all | git cat-file --batch-check='%(objecttype) %(objectname) %(objectsize) %(rest)'"
+	// maxIntroducingCommits 每个大文件最多记录的引入提交数量，避免报告过长
+	maxIntroducingCommits = 5
+	bytesPerMiB           = 1024 * 1024
+)
+
+// LargeFile 超过大小限制的 blob 对象
+type LargeFile struct {
+	Oid     string   // blob 对象ID
+	Size    int64    // 大小，单位字节
+	Paths   []string // 出现过的文件路径
+	Commits []string // 引入该对象的提交
+}
+
+// SizeMiB 返回以 MiB 为单位的大小
+func (f LargeFile) SizeMiB() float64 {
+	return float64(f.Size) / bytesPerMiB
+}
+
+// ScanLargeFiles 扫描裸仓库全部历史，找出超过 limitMiB 的 blob 对象及其路径和引入提交
+// 参数:
+//   - repoPath: 本地裸仓库路径
+//   - limitMiB: 单文件大小限制，单位 MiB
+//
+// 返回值:
+//   - []LargeFile: 超过限制的文件列表，按大小降序排列
+//   - error: 扫描失败时返回错误信息
+func ScanLargeFiles(repoPath string, limitMiB int64) ([]LargeFile, error) {
+	logger.Repo(repoPath).Infof("%s 开始扫描超过%dM的历史大文件", repoPath, limitMiB)
+	output, err := system.ExecCommand(listObjectsWithSizeCMD, repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s 扫描大文件失败: %s\n%s", repoPath, err, output)
+	}
+	largeFiles := parseLargeBlobs(output, limitMiB*bytesPerMiB)
+	for i := range largeFiles {
+		commits, err := findIntroducingCommits(repoPath, largeFiles[i].Oid)
+		if err != nil {
+			logger.Repo(repoPath).Warnf("%s 查询大文件 %s 的引入提交失败: %s", repoPath, largeFiles[i].Oid, err)
+			continue
+		}
+		largeFiles[i].Commits = commits
+	}
+	logger.Repo(repoPath).Infof("%s 扫描完成，发现 %d 个超过%dM的大文件", repoPath, len(largeFiles), limitMiB)
+	return largeFiles, nil
+}
+
+// parseLargeBlobs 解析 cat-file --batch-check 输出，过滤出超过 limitBytes 的 blob
+// 同一个 blob 可能出现在多个路径下，按对象ID合并路径
+func parseLargeBlobs(output string, limitBytes int64) []LargeFile {
+	index := make(map[string]int)
+	var largeFiles []LargeFile
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 4)
+		if len(fields) < 3 || fields[0] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil || size <= limitBytes {
+			continue
+		}
+		filePath := ""
+		if len(fields) == 4 {
+			filePath = fields[3]
+		}
+		i, ok := index[fields[1]]
+		if !ok {
+			largeFiles = append(largeFiles, LargeFile{Oid: fields[1], Size: size})
+			i = len(largeFiles) - 1
+			index[fields[1]] = i
+		}
+		if filePath != "" && !containsString(largeFiles[i].Paths, filePath) {
+			largeFiles[i].Paths = append(largeFiles[i].Paths, filePath)
+		}
+	}
+	sort.SliceStable(largeFiles, func(i, j int) bool {
+		return largeFiles[i].Size > largeFiles[j].Size
+	})
+	return largeFiles
+}
+
+// findIntroducingCommits 查询引入指定 blob 对象的提交（按时间正序，最多 maxIntroducingCommits 个）
+func findIntroducingCommits(repoPath, oid string) ([]string, error) {
+	output, err := system.RunCommand("git", repoPath, "log", "--all", "--reverse", "--format=%H", "--find-object="+oid)
+	if err != nil {
+		return nil, fmt.Errorf("%s\n%s", err, output)
+	}
+	var commits []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		commits = append(commits, line)
+		if len(commits) >= maxIntroducingCommits {
+			break
+		}
+	}
+	return commits, nil
+}
+
+// LargeFilePaths 汇总大文件涉及的全部路径（去重，保持顺序）
+func LargeFilePaths(largeFiles []LargeFile) []string {
+	var paths []string
+	for _, f := range largeFiles {
+		for _, p := range f.Paths {
+			if !containsString(paths, p) {
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
+
+// MigratePathsToLFS 仅将指定路径转换为 LFS 对象，会重写包含这些路径的历史提交
+func MigratePathsToLFS(repoPath string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	include := "--include=" + strings.Join(escapeLFSPatterns(paths), ",")
+	logger.Repo(repoPath).Infof("%s 使用git lfs migrate 将 %d 个路径转换为LFS", repoPath, len(paths))
+	output, err := system.RunCommand("git", repoPath, "lfs", "migrate", "import", "--everything", include)
+	if err != nil {
+		return fmt.Errorf("git lfs migrate import 失败: %s\n%s", err, removeCredentialsFromURL(output))
+	}
+	logger.Repo(repoPath).Infof("%s 指定路径转换为LFS成功", repoPath)
+	logger.Repo(repoPath).Debugf("%s 指定路径转换为LFS成功\n%s", repoPath, output)
+	return nil
+}
+
+// escapeLFSPatterns 路径按 gitignore 规则匹配，需要转义通配符，并以 / 开头锚定到仓库根目录
+// --include 以逗号分隔多个规则，路径中的逗号用单字符通配符 ? 代替
+func escapeLFSPatterns(paths []string) []string {
+	replacer := strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `,`, `?`)
+	patterns := make([]string, 0, len(paths))
+	for _, p := range paths {
+		patterns = append(patterns, "/"+replacer.Replace(strings.TrimPrefix(p, "/")))
+	}
+	return patterns
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLargeBlobs(t *testing.T) {
+	output := "commit 1111111111111111111111111111111111111111 250 \n" +
+		"tree 2222222222222222222222222222222222222222 120 \n" +
+		"blob aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa 314572800 assets/big.bin\n" +
+		"blob bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb 1024 README.md\n" +
+		"blob cccccccccccccccccccccccccccccccccccccccc 524288000 data/model v2.bin\n" +
+		"blob aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa 314572800 backup/big.bin\n" +
+		"blob dddddddddddddddddddddddddddddddddddddddd 268435456 exactly-limit.bin\n"
+
+	result := parseLargeBlobs(output, 256*bytesPerMiB)
+
+	if len(result) != 2 {
+		t.Fatalf("期望 2 个大文件，实际 %d 个: %+v", len(result), result)
+	}
+	if result[0].Oid != "cccccccccccccccccccccccccccccccccccccccc" {
+		t.Errorf("结果应按大小降序排列，第一个应为 ccc...，实际 %s", result[0].Oid)
+	}
+	if !reflect.DeepEqual(result[0].Paths, []string{"data/model v2.bin"}) {
+		t.Errorf("包含空格的路径解析错误: %v", result[0].Paths)
+	}
+	if !reflect.DeepEqual(result[1].Paths, []string{"assets/big.bin", "backup/big.bin"}) {
+		t.Errorf("同一 blob 的多个路径应合并: %v", result[1].Paths)
+	}
+}
+
+func TestParseLargeBlobs_NoLargeFiles(t *testing.T) {
+	output := "blob bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb 1024 README.md\n"
+	if result := parseLargeBlobs(output, 256*bytesPerMiB); len(result) != 0 {
+		t.Errorf("不应发现大文件，实际 %d 个", len(result))
+	}
+	if result := parseLargeBlobs("", 256*bytesPerMiB); len(result) != 0 {
+		t.Errorf("空输出不应发现大文件，实际 %d 个", len(result))
+	}
+}
+
+func TestLargeFilePaths(t *testing.T) {
+	largeFiles := []LargeFile{
+		{Oid: "a", Paths: []string{"a.bin", "b.bin"}},
+		{Oid: "b", Paths: []string{"b.bin", "c.bin"}},
+	}
+	expected := []string{"a.bin", "b.bin", "c.bin"}
+	if result := LargeFilePaths(largeFiles); !reflect.DeepEqual(result, expected) {
+		t.Errorf("LargeFilePaths() = %v, 期望 %v", result, expected)
+	}
+}
+
+func TestEscapeLFSPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "普通路径", input: "assets/big.bin", expected: "/assets/big.bin"},
+		{name: "已带斜杠", input: "/assets/big.bin", expected: "/assets/big.bin"},
+		{name: "通配符", input: "data/*[1]?.bin", expected: `/data/\*\[1]\?.bin`},
+		{name: "逗号", input: "data/a,b.bin", expected: "/data/a?b.bin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := escapeLFSPatterns([]string{tt.input})
+			if result[0] != tt.expected {
+				t.Errorf("escapeLFSPatterns() = %q, 期望 %q", result[0], tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package git
+
+import (
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/system"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ListRemoteRefs 列出目标仓库的分支和标签
+func ListRemoteRefs(repoPath, pushURL string, env []string) ([]string, error) {
+	output, err := system.RunCommandWithEnv(env, "git", repoPath, "ls-remote", "--heads", "--tags", pushURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s 获取目标仓库引用列表失败: %s\n%s", repoPath, err, removeCredentialsFromURL(output))
+	}
+	return parseLsRemoteRefs(output), nil
+}
+
+// parseLsRemoteRefs 解析 git ls-remote 输出，忽略附注标签的 ^{} 条目
+func parseLsRemoteRefs(output string) []string {
+	var refs []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || strings.HasSuffix(fields[1], "^{}") {
+			continue
+		}
+		refs = append(refs, fields[1])
+	}
+	return refs
+}
+
+// StaleRefs 计算目标仓库存在但源仓库已删除的分支和标签
+// 只处理引用过滤规则范围内的引用，被过滤掉的引用不属于迁移范围，不会删除
+func StaleRefs(remoteRefs, localRefs []string, filter RefFilter) []string {
+	local := make(map[string]bool, len(localRefs))
+	for _, ref := range localRefs {
+		local[ref] = true
+	}
+	var stale []string
+	for _, ref := range remoteRefs {
+		if local[ref] || !filter.Match(ref) {
+			continue
+		}
+		stale = append(stale, ref)
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// FindStaleRefs 对比源仓库与目标仓库，返回待删除的引用及目标仓库范围内的引用总数
+func FindStaleRefs(repoPath, pushURL string, filter RefFilter, env []string) (stale []string, total int, err error) {
+	remoteRefs, err := ListRemoteRefs(repoPath, pushURL, env)
+	if err != nil {
+		return nil, 0, err
+	}
+	branches, err := listRefs(repoPath, "refs/heads")
+	if err != nil {
+		return nil, 0, err
+	}
+	tags, err := listRefs(repoPath, "refs/tags")
+	if err != nil {
+		return nil, 0, err
+	}
+	return StaleRefs(remoteRefs, append(branches, tags...), filter), len(filter.Filter(remoteRefs)), nil
+}
+
+// DeleteRemoteRefs 分组删除目标仓库中的引用
+func DeleteRemoteRefs(repoPath, pushURL string, refs []string, env []string) (string, error) {
+	var output string
+	var err error
+	for i := 0; i < len(refs); i += refPushBatchSize {
+		end := i + refPushBatchSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+		var refspecs []string
+		for _, ref := range refs[i:end] {
+			refspecs = append(refspecs, ":"+ref)
+		}
+		output, err = pushRefspecs(repoPath, pushURL, false, refspecs, env)
+		if err != nil {
+			return output, err
+		}
+	}
+	logger.Repo(repoPath).Infof("%s 已删除目标仓库中 %d 个源仓库不存在的引用", repoPath, len(refs))
+	return output, nil
+}
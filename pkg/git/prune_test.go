@@ -0,0 +1,30 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLsRemoteRefs(t *testing.T) {
+	output := "1111111111111111111111111111111111111111\trefs/heads/main\n" +
+		"2222222222222222222222222222222222222222\trefs/tags/v1\n" +
+		"3333333333333333333333333333333333333333\trefs/tags/v1^{}\n"
+	expected := []string{"refs/heads/main", "refs/tags/v1"}
+	if result := parseLsRemoteRefs(output); !reflect.DeepEqual(result, expected) {
+		t.Errorf("parseLsRemoteRefs() = %v, 期望 %v", result, expected)
+	}
+}
+
+func TestStaleRefs(t *testing.T) {
+	remoteRefs := []string{"refs/heads/main", "refs/heads/old", "refs/heads/archive/x", "refs/tags/v2", "refs/tags/v1"}
+	localRefs := []string{"refs/heads/main", "refs/tags/v1"}
+	filter := RefFilter{Exclude: []string{"refs/heads/archive/*"}}
+
+	expected := []string{"refs/heads/old", "refs/tags/v2"}
+	if result := StaleRefs(remoteRefs, localRefs, filter); !reflect.DeepEqual(result, expected) {
+		t.Errorf("StaleRefs() = %v, 期望 %v", result, expected)
+	}
+	if result := StaleRefs(localRefs, localRefs, RefFilter{}); len(result) != 0 {
+		t.Errorf("源仓库与目标仓库一致时不应删除引用: %v", result)
+	}
+}
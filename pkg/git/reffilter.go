@@ -0,0 +1,173 @@
+package git
+
+import (
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/system"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// refPushBatchSize 按引用过滤推送时每条 git push 命令携带的 refspec 数，避免命令行过长
+	refPushBatchSize = 100
+)
+
+// PlatformRefPrefixes 代码托管平台在镜像克隆时附带的平台专有引用，迁移到 CNB 没有意义
+var PlatformRefPrefixes = []string{
+	"refs/pull/",
+	"refs/merge-requests/",
+	"refs/keep-around/",
+	gerritChangesPrefix,
+}
+
+// RefFilter 分支/标签过滤规则，规则为完整引用名的通配符，* 可匹配包括 / 在内的任意字符，? 匹配单个字符
+// Include 按命名空间（refs/heads/、refs/tags/）生效：某命名空间配置了 include 规则时，该命名空间下只保留匹配的引用，
+// 未配置 include 规则的命名空间不受限制；Exclude 优先于 Include
+type RefFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Active 是否配置了过滤规则
+func (f RefFilter) Active() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0
+}
+
+// Match 判断引用是否需要迁移
+func (f RefFilter) Match(ref string) bool {
+	for _, pattern := range f.Exclude {
+		if matchRefPattern(pattern, ref) {
+			return false
+		}
+	}
+	namespace := refNamespace(ref)
+	hasInclude := false
+	for _, pattern := range f.Include {
+		if refNamespace(pattern) != namespace {
+			continue
+		}
+		hasInclude = true
+		if matchRefPattern(pattern, ref) {
+			return true
+		}
+	}
+	return !hasInclude
+}
+
+// Filter 返回需要迁移的引用
+func (f RefFilter) Filter(refs []string) []string {
+	var result []string
+	for _, ref := range refs {
+		if f.Match(ref) {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+// refNamespace 返回引用所属命名空间，如 refs/heads/main 返回 refs/heads/
+func refNamespace(ref string) string {
+	parts := strings.SplitN(ref, "/", 3)
+	if len(parts) < 3 {
+		return ref
+	}
+	return parts[0] + "/" + parts[1] + "/"
+}
+
+// matchRefPattern 通配符匹配引用名，与 git refspec 一致，* 可以跨越 /
+func matchRefPattern(pattern, ref string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	matched, err := regexp.MatchString(b.String(), ref)
+	return err == nil && matched
+}
+
+// IsPlatformRef 判断是否为平台专有引用，如 refs/pull/*、refs/merge-requests/*、refs/keep-around/*、refs/changes/*
+func IsPlatformRef(ref string) bool {
+	for _, prefix := range PlatformRefPrefixes {
+		if strings.HasPrefix(ref, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DropPlatformRefs 删除镜像克隆附带的平台专有引用，减少后续大文件扫描、LFS 转换的处理量
+// 返回值:
+//   - int: 删除的引用数
+//   - error: 删除失败时返回错误信息
+func DropPlatformRefs(repoPath string) (int, error) {
+	refs, err := listRefs(repoPath, "refs/")
+	if err != nil {
+		return 0, err
+	}
+	dropped := 0
+	for _, ref := range refs {
+		if !IsPlatformRef(ref) {
+			continue
+		}
+		output, err := system.RunCommand("git", repoPath, "update-ref", "-d", ref)
+		if err != nil {
+			return dropped, fmt.Errorf("%s 删除引用 %s 失败: %s\n%s", repoPath, ref, err, output)
+		}
+		dropped++
+	}
+	if dropped > 0 {
+		logger.Repo(repoPath).Infof("%s 已删除 %d 个平台专有引用", repoPath, dropped)
+	}
+	return dropped, nil
+}
+
+// selectedRefs 列出按过滤规则需要推送的分支和标签
+func selectedRefs(repoPath string, filter RefFilter) (branches, tags []string, err error) {
+	branches, err = listRefs(repoPath, "refs/heads")
+	if err != nil {
+		return nil, nil, err
+	}
+	tags, err = listRefs(repoPath, "refs/tags")
+	if err != nil {
+		return nil, nil, err
+	}
+	return filter.Filter(branches), filter.Filter(tags), nil
+}
+
+// SelectedBranches 列出按过滤规则需要迁移的分支
+func SelectedBranches(repoPath string, filter RefFilter) ([]string, error) {
+	branches, err := listRefs(repoPath, "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	return filter.Filter(branches), nil
+}
+
+// filteredCodePush 按过滤规则分组推送分支和标签
+func filteredCodePush(repoPath, pushURL string, force bool, filter RefFilter, env []string) (string, error) {
+	if force {
+		logger.Repo(repoPath).Warnf("%s 即将执行强制推送(git push -f),此操作将覆盖目标仓库的历史记录,请确保您了解此操作的风险", repoPath)
+	}
+	branches, tags, err := selectedRefs(repoPath, filter)
+	if err != nil {
+		return "", err
+	}
+	logger.Repo(repoPath).Infof("%s 按引用过滤规则推送 %d 个分支、%d 个标签", repoPath, len(branches), len(tags))
+	var output string
+	for _, group := range groupRefspecs(append(branches, tags...), refPushBatchSize) {
+		output, err = pushRefspecs(repoPath, pushURL, force, group, env)
+		if err != nil {
+			return output, err
+		}
+	}
+	return output, nil
+}
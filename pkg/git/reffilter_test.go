@@ -0,0 +1,62 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRefFilterMatch(t *testing.T) {
+	filter := RefFilter{
+		Include: []string{"refs/tags/v*"},
+		Exclude: []string{"refs/heads/archive/*", "refs/tags/v0.*"},
+	}
+	tests := []struct {
+		ref      string
+		expected bool
+	}{
+		{ref: "refs/heads/main", expected: true},
+		{ref: "refs/heads/archive/2020", expected: false},
+		{ref: "refs/heads/archive/old/x", expected: false},
+		{ref: "refs/tags/v1.0.0", expected: true},
+		{ref: "refs/tags/release-1", expected: false},
+		{ref: "refs/tags/v0.9", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if result := filter.Match(tt.ref); result != tt.expected {
+				t.Errorf("Match(%s) = %v, 期望 %v", tt.ref, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRefFilterFilter(t *testing.T) {
+	filter := RefFilter{Include: []string{"refs/heads/main", "refs/heads/release/?"}}
+	refs := []string{"refs/heads/main", "refs/heads/dev", "refs/heads/release/1", "refs/heads/release/10"}
+	expected := []string{"refs/heads/main", "refs/heads/release/1"}
+	if result := filter.Filter(refs); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Filter() = %v, 期望 %v", result, expected)
+	}
+	if (RefFilter{}).Active() {
+		t.Errorf("未配置规则时不应生效")
+	}
+}
+
+func TestIsPlatformRef(t *testing.T) {
+	tests := []struct {
+		ref      string
+		expected bool
+	}{
+		{ref: "refs/pull/1/head", expected: true},
+		{ref: "refs/merge-requests/2/head", expected: true},
+		{ref: "refs/keep-around/abc", expected: true},
+		{ref: "refs/changes/34/1234/1", expected: true},
+		{ref: "refs/heads/pull/1", expected: false},
+		{ref: "refs/tags/v1", expected: false},
+	}
+	for _, tt := range tests {
+		if result := IsPlatformRef(tt.ref); result != tt.expected {
+			t.Errorf("IsPlatformRef(%s) = %v, 期望 %v", tt.ref, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package git
+
+import (
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/system"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	sshPassphraseEnv = "CCRCTL_SSH_PASSPHRASE"
+	knownHostsName   = "known_hosts"
+)
+
+var sshAgentVarRegex = regexp.MustCompile(`(SSH_AUTH_SOCK|SSH_AGENT_PID)=([^;]+);`)
+
+// SSHKey SSH 私钥
+type SSHKey struct {
+	Name       string // 密钥名称，仅用于日志和临时文件名
+	Content    string // 私钥内容
+	Passphrase string // 私钥密码，不为空时通过 ssh-agent 加载
+}
+
+// SSHOptions 本次运行的 SSH 配置
+type SSHOptions struct {
+	Keys       []SSHKey
+	KnownHosts string   // 已信任的 known_hosts 内容，不为空时严格校验主机公钥
+	Hosts      []string // 首次信任（TOFU）的主机，预先获取主机公钥并记录指纹，格式 host 或 host:port
+}
+
+// SSHEnv 本次运行的 SSH 环境，私钥和 known_hosts 保存在临时目录中，通过 GIT_SSH_COMMAND 生效
+type SSHEnv struct {
+	Dir      string
+	Env      []string // 需要传给 git clone/push/ls-remote 等命令的环境变量，如 GIT_SSH_COMMAND、SSH_AUTH_SOCK
+	agentPID string
+}
+
+// SetupSSH 在临时目录中准备私钥、known_hosts，生成 GIT_SSH_COMMAND 等环境变量，不修改当前进程环境变量和用户 ~/.ssh 目录
+// 有密码的私钥通过 ssh-agent 加载，ssh 使用对应的公钥文件从 agent 中选择私钥
+func SetupSSH(opts SSHOptions) (*SSHEnv, error) {
+	dir, err := os.MkdirTemp("", "ccrctl-ssh-")
+	if err != nil {
+		return nil, fmt.Errorf("创建SSH临时目录失败: %w", err)
+	}
+	env := &SSHEnv{Dir: dir}
+	if err := env.setup(opts); err != nil {
+		env.Close()
+		return nil, err
+	}
+	return env, nil
+}
+
+func (e *SSHEnv) setup(opts SSHOptions) error {
+	var identities []string
+	for i, key := range opts.Keys {
+		keyPath := filepath.Join(e.Dir, fmt.Sprintf("key%d", i))
+		if err := os.WriteFile(keyPath, []byte(normalizeKeyContent(key.Content)), 0600); err != nil {
+			return fmt.Errorf("写入SSH私钥 %s 失败: %w", key.Name, err)
+		}
+		if key.Passphrase == "" {
+			identities = append(identities, keyPath)
+			continue
+		}
+		pubPath, err := e.addToAgent(keyPath, key)
+		if err != nil {
+			return err
+		}
+		identities = append(identities, pubPath)
+	}
+
+	knownHostsPath := filepath.Join(e.Dir, knownHostsName)
+	strict := "yes"
+	knownHosts := normalizeKeyContent(opts.KnownHosts)
+	if knownHosts == "" {
+		// 未配置 known_hosts 时首次信任，预先获取公钥之外的主机由 ssh 在首次连接时添加
+		strict = "accept-new"
+	}
+	// 与已配置的 known_hosts 合并，未单独配置 known_hosts 的平台（如 CNB）同样可以连接
+	knownHosts += scanHostKeys(opts.Hosts)
+	if err := os.WriteFile(knownHostsPath, []byte(knownHosts), 0600); err != nil {
+		return fmt.Errorf("写入known_hosts失败: %w", err)
+	}
+	logHostKeyFingerprints(knownHostsPath)
+
+	e.setenv("GIT_SSH_COMMAND", buildSSHCommand(identities, knownHostsPath, strict))
+	logger.Logger.Infof("已加载 %d 个SSH私钥，SSH临时目录: %s", len(opts.Keys), e.Dir)
+	return nil
+}
+
+// addToAgent 启动 ssh-agent（首次调用时）并加载有密码的私钥，返回 agent 中对应公钥的文件路径
+func (e *SSHEnv) addToAgent(keyPath string, key SSHKey) (string, error) {
+	if e.agentPID == "" {
+		output, err := system.RunCommand("ssh-agent", e.Dir, "-s", "-a", filepath.Join(e.Dir, "agent.sock"))
+		if err != nil {
+			return "", fmt.Errorf("启动ssh-agent失败: %s\n%s", err, output)
+		}
+		vars := parseSSHAgentOutput(output)
+		if vars["SSH_AUTH_SOCK"] == "" || vars["SSH_AGENT_PID"] == "" {
+			return "", fmt.Errorf("解析ssh-agent输出失败: %s", output)
+		}
+		e.agentPID = vars["SSH_AGENT_PID"]
+		e.setenv("SSH_AUTH_SOCK", vars["SSH_AUTH_SOCK"])
+	}
+
+	// 通过 SSH_ASKPASS 脚本从环境变量读取密码，密码不落盘；脚本只应答一次，密码错误时 ssh-add 直接失败而不是反复重试
+	askPass := filepath.Join(e.Dir, "askpass.sh")
+	_ = os.Remove(askPass + ".used")
+	script := fmt.Sprintf("#!/bin/sh\n[ -e \"$0.used\" ] && exit 1\n: > \"$0.used\"\nprintf '%%s\\n' \"$%s\"\n", sshPassphraseEnv)
+	if err := os.WriteFile(askPass, []byte(script), 0700); err != nil {
+		return "", fmt.Errorf("写入SSH_ASKPASS脚本失败: %w", err)
+	}
+	env := []string{
+		e.getenv("SSH_AUTH_SOCK"),
+		sshPassphraseEnv + "=" + key.Passphrase,
+		"SSH_ASKPASS=" + askPass,
+		"SSH_ASKPASS_REQUIRE=force",
+		"DISPLAY=none",
+	}
+	before := e.agentPublicKeys()
+	if output, err := system.RunCommandWithEnv(env, "ssh-add", e.Dir, keyPath); err != nil {
+		return "", fmt.Errorf("ssh-agent 加载SSH私钥 %s 失败，请检查私钥密码: %s\n%s", key.Name, err, output)
+	}
+	// 对比加载前后 agent 中的公钥得到该私钥的公钥；私钥已在 agent 中时从私钥文件导出
+	pub := ""
+	for _, line := range e.agentPublicKeys() {
+		if !containsString(before, line) {
+			pub = line
+			break
+		}
+	}
+	if pub == "" {
+		output, err := system.RunCommand("ssh-keygen", e.Dir, "-y", "-f", keyPath)
+		if err != nil {
+			return "", fmt.Errorf("获取SSH公钥 %s 失败: %s\n%s", key.Name, err, output)
+		}
+		pub = output
+	}
+	pubPath := keyPath + ".pub"
+	if err := os.WriteFile(pubPath, []byte(strings.TrimSpace(pub)+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("写入SSH公钥 %s 失败: %w", key.Name, err)
+	}
+	logger.Logger.Infof("已通过ssh-agent加载SSH私钥 %s", key.Name)
+	return pubPath, nil
+}
+
+// agentPublicKeys 列出 ssh-agent 中已加载的公钥，agent 为空时返回空列表
+func (e *SSHEnv) agentPublicKeys() []string {
+	output, err := system.RunCommandWithEnv([]string{e.getenv("SSH_AUTH_SOCK")}, "ssh-add", e.Dir, "-L")
+	if err != nil {
+		return nil
+	}
+	return keyscanLines(output)
+}
+
+// setenv 记录需要传给 git 命令的环境变量，不修改当前进程环境变量
+func (e *SSHEnv) setenv(key, value string) {
+	e.Env = append(e.Env, key+"="+value)
+}
+
+// getenv 返回已记录的 key=value 环境变量，未记录时返回空字符串
+func (e *SSHEnv) getenv(key string) string {
+	for _, kv := range e.Env {
+		if strings.HasPrefix(kv, key+"=") {
+			return kv
+		}
+	}
+	return ""
+}
+
+// Close 停止 ssh-agent 并删除临时目录
+func (e *SSHEnv) Close() {
+	if e == nil {
+		return
+	}
+	if e.agentPID != "" {
+		if output, err := system.RunCommandWithEnv([]string{"SSH_AGENT_PID=" + e.agentPID}, "ssh-agent", e.Dir, "-k"); err != nil {
+			logger.Logger.Warnf("停止ssh-agent失败: %s\n%s", err, output)
+		}
+	}
+	_ = os.RemoveAll(e.Dir)
+}
+
+// buildSSHCommand 生成 GIT_SSH_COMMAND，只使用指定的私钥和 known_hosts
+func buildSSHCommand(identities []string, knownHostsPath, strictHostKeyChecking string) string {
+	parts := []string{"ssh", "-o", "IdentitiesOnly=yes", "-o", "BatchMode=yes",
+		"-o", "UserKnownHostsFile=" + shellQuote(knownHostsPath),
+		"-o", "StrictHostKeyChecking=" + strictHostKeyChecking}
+	for _, identity := range identities {
+		parts = append(parts, "-i", shellQuote(identity))
+	}
+	return strings.Join(parts, " ")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// normalizeKeyContent 支持在环境变量中使用 \n 表示换行，并保证以换行结尾（ssh 要求私钥以换行结尾）
+func normalizeKeyContent(content string) string {
+	content = strings.ReplaceAll(strings.TrimSpace(content), `\n`, "\n")
+	if content == "" {
+		return ""
+	}
+	return content + "\n"
+}
+
+// parseSSHAgentOutput 解析 ssh-agent -s 输出的环境变量
+func parseSSHAgentOutput(output string) map[string]string {
+	vars := make(map[string]string)
+	for _, m := range sshAgentVarRegex.FindAllStringSubmatch(output, -1) {
+		vars[m[1]] = m[2]
+	}
+	return vars
+}
+
+// scanHostKeys 使用 ssh-keyscan 获取主机公钥，失败的主机留给 ssh 首次连接时添加
+func scanHostKeys(hosts []string) string {
+	var b strings.Builder
+	for _, hostPort := range hosts {
+		host, port := splitSSHHostPort(hostPort)
+		args := []string{"-T", "10"}
+		if port != "" && port != "22" {
+			args = append(args, "-p", port)
+		}
+		args = append(args, host)
+		output, err := system.RunCommand("ssh-keyscan", "", args...)
+		keys := keyscanLines(output)
+		if err != nil || len(keys) == 0 {
+			logger.Logger.Warnf("获取主机 %s 的SSH公钥失败，未配置 known_hosts 时将在首次连接时信任: %v", hostPort, err)
+			continue
+		}
+		for _, line := range keys {
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// keyscanLines 过滤 ssh-keyscan 输出中的注释行
+func keyscanLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// logHostKeyFingerprints 记录 known_hosts 中的主机公钥指纹，便于与平台公布的指纹核对
+func logHostKeyFingerprints(knownHostsPath string) {
+	output, err := system.RunCommand("ssh-keygen", "", "-l", "-f", knownHostsPath)
+	if err != nil {
+		return
+	}
+	for _, line := range keyscanLines(output) {
+		logger.Logger.Infof("SSH主机公钥指纹: %s", line)
+	}
+}
+
+// splitSSHHostPort 拆分 host:port，未指定端口时 port 为空
+func splitSSHHostPort(hostPort string) (host, port string) {
+	if h, p, err := net.SplitHostPort(hostPort); err == nil {
+		return h, p
+	}
+	return hostPort, ""
+}
+
+// SSHHostsFromURLs 从仓库地址中提取 SSH 主机（host 或 host:port），用于获取主机公钥
+func SSHHostsFromURLs(urls []string) []string {
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, rawURL := range urls {
+		host := sshHostFromURL(rawURL)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// sshHostFromURL 提取 ssh://git@host:port/path 或 git@host:path 中的主机，非 SSH 地址返回空字符串
+func sshHostFromURL(rawURL string) string {
+	if strings.HasPrefix(rawURL, "ssh://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return ""
+		}
+		return u.Host
+	}
+	if strings.Contains(rawURL, "://") {
+		return ""
+	}
+	if m := scpLikeURLRegex.FindStringSubmatch(rawURL); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,189 @@
+package git
+
+import (
+	"ccrctl/pkg/system"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const gitmodulesFileName = ".gitmodules"
+
+// scpLikeURLRegex 匹配 git@host:group/repo.git 形式的 SSH 地址
+var scpLikeURLRegex = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// Submodule 子模块配置
+type Submodule struct {
+	Name string
+	URL  string
+}
+
+// SubmoduleRewrite 子模块地址改写
+type SubmoduleRewrite struct {
+	Name   string
+	OldURL string
+	NewURL string
+}
+
+// NormalizeRepoURL 将仓库地址规范化为 host/path 形式，用于匹配 HTTPS、SSH 等不同形式的同一仓库地址
+// 去除协议、认证信息、端口、结尾的 / 和 .git，并转为小写；相对地址和无法解析的地址返回空字符串
+func NormalizeRepoURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" || strings.HasPrefix(rawURL, "./") || strings.HasPrefix(rawURL, "../") {
+		return ""
+	}
+	var host, repoPath string
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Hostname() == "" {
+			return ""
+		}
+		host, repoPath = u.Hostname(), u.Path
+	} else if m := scpLikeURLRegex.FindStringSubmatch(rawURL); m != nil {
+		host, repoPath = m[1], m[2]
+	} else {
+		return ""
+	}
+	repoPath = strings.Trim(repoPath, "/")
+	repoPath = strings.TrimSuffix(repoPath, ".git")
+	if repoPath == "" {
+		return ""
+	}
+	return strings.ToLower(host + "/" + repoPath)
+}
+
+// ListSubmodules 读取指定引用中 .gitmodules 配置的子模块，不存在 .gitmodules 时返回空列表
+func ListSubmodules(repoPath, ref string) ([]Submodule, error) {
+	blob := ref + ":" + gitmodulesFileName
+	if _, err := system.RunCommand("git", repoPath, "cat-file", "-e", blob); err != nil {
+		return nil, nil
+	}
+	// 使用 -z 输出，子模块名称中包含空格时也能正确拆分
+	output, stderr, err := system.RunCommandOutput("git", repoPath, "config", "-z", "--blob", blob, "--get-regexp", `^submodule\..*\.url$`)
+	if err != nil {
+		// 没有匹配的配置项时 git config 返回 1
+		if strings.TrimSpace(stderr) == "" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s 读取 %s 失败: %s\n%s", repoPath, blob, err, stderr)
+	}
+	return parseSubmoduleURLs(output), nil
+}
+
+// parseSubmoduleURLs 解析 git config -z --get-regexp 的输出，每条记录以 NUL 结尾，键与值之间以换行分隔
+func parseSubmoduleURLs(output string) []Submodule {
+	var submodules []Submodule
+	for _, record := range strings.Split(output, "\x00") {
+		key, value, found := strings.Cut(record, "\n")
+		if !found {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "submodule."), ".url")
+		submodules = append(submodules, Submodule{Name: name, URL: strings.TrimSpace(value)})
+	}
+	return submodules
+}
+
+// PlanSubmoduleRewrites 按 规范化源地址→CNB 地址 映射计算需要改写的子模块，映射中不存在的子模块保持不变
+func PlanSubmoduleRewrites(submodules []Submodule, urlMap map[string]string) []SubmoduleRewrite {
+	var rewrites []SubmoduleRewrite
+	for _, s := range submodules {
+		newURL, ok := urlMap[NormalizeRepoURL(s.URL)]
+		if !ok || newURL == s.URL {
+			continue
+		}
+		rewrites = append(rewrites, SubmoduleRewrite{Name: s.Name, OldURL: s.URL, NewURL: newURL})
+	}
+	sort.Slice(rewrites, func(i, j int) bool { return rewrites[i].Name < rewrites[j].Name })
+	return rewrites
+}
+
+// CommitGitmodulesRewrite 基于指定引用创建一个只修改 .gitmodules 的新提交，不修改任何已有引用
+// 参数:
+//   - repoPath: 本地裸仓库路径
+//   - ref: 父提交所在引用
+//   - rewrites: 子模块地址改写
+//   - message: 提交信息
+//
+// 返回值:
+//   - string: 新提交的 commit ID
+//   - error: 创建失败时返回错误信息
+func CommitGitmodulesRewrite(repoPath, ref string, rewrites []SubmoduleRewrite, message string) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "ccrctl-gitmodules-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	tmpFile := filepath.Join(tmpDir, gitmodulesFileName)
+
+	content, stderr, err := system.RunCommandOutput("git", repoPath, "show", ref+":"+gitmodulesFileName)
+	if err != nil {
+		return "", fmt.Errorf("%s 读取 %s:%s 失败: %s\n%s", repoPath, ref, gitmodulesFileName, err, stderr)
+	}
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		return "", err
+	}
+	// 使用 git config 修改，保留文件中的其余内容
+	for _, r := range rewrites {
+		if output, err := system.RunCommand("git", tmpDir, "config", "--file", tmpFile, "submodule."+r.Name+".url", r.NewURL); err != nil {
+			return "", fmt.Errorf("%s 修改子模块 %s 地址失败: %s\n%s", repoPath, r.Name, err, output)
+		}
+	}
+
+	blob, err := runGitTrimmed(repoPath, "hash-object", "-w", tmpFile)
+	if err != nil {
+		return "", err
+	}
+	tree, err := replaceRootTreeEntry(repoPath, ref, gitmodulesFileName, blob)
+	if err != nil {
+		return "", err
+	}
+	parent, err := runGitTrimmed(repoPath, "rev-parse", ref+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	commit, err := system.RunCommand("git", repoPath, "-c", "user.name="+system.GitUserName, "-c", "user.email="+system.GitUserEmail,
+		"commit-tree", tree, "-p", parent, "-m", message)
+	if err != nil {
+		return "", fmt.Errorf("%s 创建提交失败: %s\n%s", repoPath, err, commit)
+	}
+	return strings.TrimSpace(commit), nil
+}
+
+// replaceRootTreeEntry 替换引用根目录树中的一个文件，返回新的 tree ID
+func replaceRootTreeEntry(repoPath, ref, fileName, blob string) (string, error) {
+	output, err := system.RunCommand("git", repoPath, "ls-tree", "-z", ref+"^{tree}")
+	if err != nil {
+		return "", fmt.Errorf("%s 读取 %s 目录树失败: %s\n%s", repoPath, ref, err, output)
+	}
+	var b strings.Builder
+	for _, entry := range strings.Split(output, "\x00") {
+		if entry == "" || strings.HasSuffix(entry, "\t"+fileName) {
+			continue
+		}
+		b.WriteString(entry + "\x00")
+	}
+	fmt.Fprintf(&b, "100644 blob %s\t%s\x00", blob, fileName)
+	tree, err := system.RunCommandWithInput(b.String(), "git", repoPath, "mktree", "-z")
+	if err != nil {
+		return "", fmt.Errorf("%s 创建目录树失败: %s\n%s", repoPath, err, tree)
+	}
+	return strings.TrimSpace(tree), nil
+}
+
+// PushCommit 将提交强制推送到目标仓库的指定引用
+func PushCommit(repoPath, pushURL, commit, ref string, env []string) (string, error) {
+	return pushRefspecs(repoPath, pushURL, false, []string{"+" + commit + ":" + ref}, env)
+}
+
+func runGitTrimmed(repoPath string, args ...string) (string, error) {
+	output, err := system.RunCommand("git", repoPath, args...)
+	if err != nil {
+		return "", fmt.Errorf("%s git %s 失败: %s\n%s", repoPath, args[0], err, output)
+	}
+	return strings.TrimSpace(output), nil
+}
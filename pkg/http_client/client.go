@@ -2,7 +2,6 @@ package http_client
 
 import (
 	"bytes"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/logger"
 	"context"
 	"encoding/json"
@@ -11,27 +10,30 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
 )
 
-const (
-	giteeApiPath          = "/api/v5"
-	giteeApiHost          = "https://gitee.com"
-	codingOpenAPIEndpoint = "/open-api"
-)
+// sourceClients 按地址和 token 复用源平台客户端，同一源平台的请求共享限流
+var sourceClients sync.Map
 
-var (
-	CnbURL = config.Cfg.GetString("cnb.url")
-)
+// sharedSourceClient 返回与 c 地址和 token 相同的已有客户端，不存在时保存并返回 c
+func sharedSourceClient(c *Client) *Client {
+	actual, _ := sourceClients.LoadOrStore(c.BaseURL+"\x00"+c.Username+"\x00"+c.Token, c)
+	return actual.(*Client)
+}
 
 // Client 是 OpenAPI 客户端的结构体
 type Client struct {
 	BaseURL    string
 	HTTPClient *http.Client
 	Token      string
-	Limiter    *rate.Limiter
+	// Username 使用 HTTP Basic 认证的平台（如 Gerrit）的用户名，Token 为对应的密码
+	Username string
+	Limiter  *rate.Limiter
 }
 
 // NewClient 创建一个新的 OpenAPI 客户端
@@ -43,51 +45,55 @@ func NewClient(baseURL string) *Client {
 	}
 }
 
-// NewClientV2 NewClientV2 创建一个新的 OpenAPI 客户端
-func NewClientV2() *Client {
+// NewClientV2 创建 CNB OpenAPI 客户端
+func NewClientV2(apiURL, token string) *Client {
 	return &Client{
-		BaseURL:    config.ConvertToApiURL(CnbURL),
+		BaseURL:    apiURL,
 		HTTPClient: &http.Client{},
-		Token:      config.Cfg.GetString("cnb.token"),
+		Token:      token,
 		Limiter:    rate.NewLimiter(rate.Every(time.Second), 10),
 	}
 }
 
-// NewCNBClient NewClientV3 创建一个新的 OpenAPI 客户端
-func NewCNBClient() *Client {
-	return &Client{
-		BaseURL:    config.ConvertToApiURL(config.Cfg.GetString("source.url")),
+// NewCNBClient 使用 CNB API 地址及 token 创建 CNB 源平台客户端，相同配置复用同一客户端
+func NewCNBClient(apiURL, token string) *Client {
+	return sharedSourceClient(&Client{
+		BaseURL:    apiURL,
 		HTTPClient: &http.Client{},
-		Token:      config.Cfg.GetString("source.token"),
+		Token:      token,
 		Limiter:    rate.NewLimiter(rate.Every(time.Second), 10),
-	}
+	})
 }
 
-func NewGiteeClient() *Client {
-	return &Client{
-		BaseURL:    giteeApiHost + giteeApiPath,
+// NewGiteeClient 使用 Gitee API 地址及 token 创建 Gitee 客户端，相同配置复用同一客户端
+func NewGiteeClient(apiURL, token string) *Client {
+	return sharedSourceClient(&Client{
+		BaseURL:    apiURL,
 		HTTPClient: &http.Client{},
-		Token:      config.Cfg.GetString("source.token"),
+		Token:      token,
 		Limiter:    rate.NewLimiter(rate.Every(time.Second), 1),
-	}
+	})
 }
 
-func NewCodingClient() *Client {
-	return &Client{
-		BaseURL:    config.Cfg.GetString("source.url") + codingOpenAPIEndpoint,
+// NewGiteaClient 使用 Gitea API 地址及 token 创建 Gitea 客户端，相同配置复用同一客户端
+func NewGiteaClient(apiURL, token string) *Client {
+	return sharedSourceClient(&Client{
+		BaseURL:    apiURL,
 		HTTPClient: &http.Client{},
-		Token:      config.Cfg.GetString("source.token"),
+		Token:      token,
 		Limiter:    rate.NewLimiter(rate.Every(time.Second), 10),
-	}
+	})
 }
 
-func NewGiteaClient() *Client {
-	return &Client{
-		BaseURL:    config.Cfg.GetString("source.url") + "/api/v1",
+// NewGerritClient 使用 Gerrit 地址及用户名、HTTP 密码创建 Gerrit 客户端，相同配置复用同一客户端
+func NewGerritClient(baseURL, username, password string) *Client {
+	return sharedSourceClient(&Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
 		HTTPClient: &http.Client{},
-		Token:      config.Cfg.GetString("source.token"),
+		Username:   username,
+		Token:      password,
 		Limiter:    rate.NewLimiter(rate.Every(time.Second), 10),
-	}
+	})
 }
 
 // Request 发送一个 HTTP 请求到 OpenAPI
@@ -367,6 +373,32 @@ func (c *Client) GiteeRequest(method, endpoint string, body interface{}, values
 	return respBody, resp.Header, resp.StatusCode, nil
 }
 
+// GerritRequest 发送 Gerrit REST 请求，配置了用户名时使用 HTTP Basic 认证，响应体保留 )]}' 前缀由调用方处理
+func (c *Client) GerritRequest(method, endpoint string) ([]byte, int, error) {
+	logger.Logger.Debugf("开始 Gerrit 请求 %s", endpoint)
+	if err := c.Limiter.Wait(context.Background()); err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequest(method, c.BaseURL+endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Token)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return respBody, resp.StatusCode, nil
+}
+
 func (c *Client) Unmarshal(data []byte, v interface{}) error {
 	err := json.Unmarshal(data, v)
 	if err != nil {
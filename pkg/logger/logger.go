@@ -1,107 +1,156 @@
 package logger
 
 import (
-	"ccrctl/pkg/config"
 	"fmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"os"
 	"path/filepath"
-	"time"
 )
 
-var (
-	Logger                *zap.SugaredLogger
-	SuccessfulLogFilePath string
-	SuccessfulLogFile     *os.File
+var Logger *zap.SugaredLogger
+
+const (
+	InfoLog = "migrate.log"
+	// RepoLogDir 单仓库日志所在目录，位于日志目录下
+	RepoLogDir = "logs"
 )
 
+// 日志格式
 const (
-	SuccessfulLog = "successful.log"
-	InfoLog       = "migrate.log"
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+// Options 日志选项
+type Options struct {
+	// Dir migrate.log 所在目录，为空时使用当前工作目录
+	Dir string
+	// Level 日志级别 debug/info/warn/error，为空时使用 info
+	Level string
+	// Format 日志格式 console/json，为空时使用 console
+	Format string
+	// RepoLog 是否同时将每个仓库的日志写入 Dir/logs/<仓库路径>.log
+	RepoLog bool
+}
+
+var (
+	// infoLogFile 当前写入的 migrate.log
+	infoLogFile *os.File
+	// logFormat、logLevel 当前日志格式及级别，单仓库日志使用相同的设置
+	logFormat = FormatConsole
+	logLevel  = zap.NewAtomicLevelAt(zap.InfoLevel)
+	// repoLogDir 单仓库日志目录，为空表示不写单仓库日志
+	repoLogDir string
 )
 
 func init() {
-	logFile, err := os.OpenFile(InfoLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	// 未调用 Init 前只输出到标准输出，不创建日志文件
+	Logger = newLogger(logFormat, logLevel, zapcore.AddSync(os.Stdout))
+}
+
+// Init 打开 migrate.log 并初始化日志，重复调用时关闭之前打开的日志文件
+func Init(opts Options) error {
+	level, err := parseLevel(opts.Level)
 	if err != nil {
-		Logger.Error("打开日志文件失败:", err)
+		return err
 	}
-	//defer logFile.Close()
-
-	SuccessfulLogFile, err = os.OpenFile(SuccessfulLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	format := opts.Format
+	switch format {
+	case "":
+		format = FormatConsole
+	case FormatConsole, FormatJSON:
+	default:
+		return fmt.Errorf("未知的日志格式: %s", format)
+	}
+	dir := opts.Dir
+	if dir == "" {
+		// 获取当前工作目录
+		dir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+	logFile, err := os.OpenFile(filepath.Join(dir, InfoLog), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
-		Logger.Error("打开日志文件失败:", err)
+		return fmt.Errorf("打开日志文件失败: %v", err)
+	}
+	repoDir := ""
+	if opts.RepoLog {
+		repoDir = filepath.Join(dir, RepoLogDir)
+		if err := os.MkdirAll(repoDir, 0755); err != nil {
+			logFile.Close()
+			return fmt.Errorf("创建仓库日志目录失败: %v", err)
+		}
+	}
+
+	if infoLogFile != nil {
+		infoLogFile.Close()
 	}
+	infoLogFile = logFile
+	logFormat, logLevel, repoLogDir = format, level, repoDir
 
 	//创建一个多写入器，同时写入标准输出和日志文件
-	multiWriteSyncer := zapcore.NewMultiWriteSyncer(
+	Logger = newLogger(format, level, zapcore.NewMultiWriteSyncer(
 		zapcore.AddSync(os.Stdout),
 		zapcore.AddSync(logFile),
-	)
+	))
+	return nil
+}
 
-	// 创建带颜色的编码器配置
-	encoderConfig := zapcore.EncoderConfig{
-		TimeKey:        "time",
-		LevelKey:       "level",
-		NameKey:        "logger",
-		CallerKey:      "caller",
-		MessageKey:     "msg",
-		StacktraceKey:  "stacktrace",
-		LineEnding:     zapcore.DefaultLineEnding,
-		// 自定义日志级别编码器：INFO级别不带颜色，其他级别带颜色
-		EncodeLevel: func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
-			if l == zapcore.InfoLevel {
-				enc.AppendString(l.CapitalString())
-			} else {
-				zapcore.CapitalColorLevelEncoder(l, enc)
-			}
-		},
-		EncodeTime:     zapcore.ISO8601TimeEncoder,
-		EncodeDuration: zapcore.StringDurationEncoder,
-		EncodeCaller:   zapcore.ShortCallerEncoder,
-	}
+// Initialized 是否已调用 Init 打开日志文件
+func Initialized() bool {
+	return infoLogFile != nil
+}
 
+func parseLevel(name string) (zap.AtomicLevel, error) {
 	level := zap.NewAtomicLevel()
-	switch config.Cfg.GetString("migrate.log_level") {
+	switch name {
 	case "debug":
 		level.SetLevel(zap.DebugLevel)
-	case "info":
+	case "info", "":
 		level.SetLevel(zap.InfoLevel)
 	case "warn":
 		level.SetLevel(zap.WarnLevel)
 	case "error":
 		level.SetLevel(zap.ErrorLevel)
 	default:
-		fmt.Println("未知的日志级别:", config.Cfg.GetString("migrate.log_level"))
-		return
+		return level, fmt.Errorf("未知的日志级别: %s", name)
 	}
+	return level, nil
+}
 
-	// 创建一个核心，使用多写入器和带颜色的编码器配置
-	core := zapcore.NewCore(
-		zapcore.NewConsoleEncoder(encoderConfig),
-		multiWriteSyncer,
-		level,
-	)
-
-	// 获取当前工作目录
-	currentDir, err := os.Getwd()
-	if err != nil {
-		Logger.Error("Error:", err)
-	}
-
-	// 拼接日志文件的绝对路径
-	SuccessfulLogFilePath = filepath.Join(currentDir, SuccessfulLog)
-
-	logger := zap.New(core)
+func newLogger(format string, level zap.AtomicLevel, writer zapcore.WriteSyncer) *zap.SugaredLogger {
+	logger := zap.New(newCore(format, level, writer))
 	logger = logger.WithOptions(zap.AddCaller())
-	Logger = logger.Sugar()
-
+	return logger.Sugar()
 }
 
-// 记录迁移成功的仓库
-func RecordSuccessfulRepo(repoPath string) {
-	_, err := SuccessfulLogFile.WriteString(time.Now().Format("2006-01-02 15:04:05") + " " + repoPath + "\n")
-	if err != nil {
-		Logger.Error("Error:", err)
+// newCore 按日志格式创建核心，并在输出前隐藏凭证
+func newCore(format string, level zapcore.LevelEnabler, writer zapcore.WriteSyncer) zapcore.Core {
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.CapitalLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+	if format == FormatJSON {
+		return newRedactCore(zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), writer, level))
+	}
+	// 自定义日志级别编码器：INFO级别不带颜色，其他级别带颜色
+	encoderConfig.EncodeLevel = func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		if l == zapcore.InfoLevel {
+			enc.AppendString(l.CapitalString())
+		} else {
+			zapcore.CapitalColorLevelEncoder(l, enc)
+		}
 	}
+	return newRedactCore(zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), writer, level))
 }
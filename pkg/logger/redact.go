@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"ccrctl/pkg/redact"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactCore 在日志写入标准输出及 migrate.log 前，隐藏消息和字段中已登记的凭证、地址中的用户信息及认证请求头
+type redactCore struct {
+	zapcore.Core
+}
+
+func newRedactCore(core zapcore.Core) zapcore.Core {
+	return redactCore{core}
+}
+
+func (c redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactCore{c.Core.With(redactFields(fields))}
+}
+
+func (c redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = redact.Sanitize(ent.Message)
+	ent.Stack = redact.Sanitize(ent.Stack)
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	if len(fields) == 0 {
+		return fields
+	}
+	redacted := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = redactField(f)
+	}
+	return redacted
+}
+
+// redactField 字符串类字段直接替换，结构体、切片等复杂字段先编码为 JSON 再替换
+func redactField(f zapcore.Field) zapcore.Field {
+	switch f.Type {
+	case zapcore.StringType:
+		f.String = redact.Sanitize(f.String)
+		return f
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		if b, ok := f.Interface.([]byte); ok {
+			f.Interface = []byte(redact.Sanitize(string(b)))
+		}
+		return f
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok && err != nil {
+			return zap.String(f.Key, redact.Sanitize(err.Error()))
+		}
+		return f
+	case zapcore.StringerType:
+		if s, ok := f.Interface.(fmt.Stringer); ok && s != nil {
+			return zap.String(f.Key, redact.Sanitize(s.String()))
+		}
+		return f
+	case zapcore.ReflectType, zapcore.ArrayMarshalerType, zapcore.ObjectMarshalerType:
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		var buf bytes.Buffer
+		jsonEnc := json.NewEncoder(&buf)
+		jsonEnc.SetEscapeHTML(false)
+		if err := jsonEnc.Encode(enc.Fields[f.Key]); err != nil {
+			return f
+		}
+		data := redact.Sanitize(string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))))
+		if json.Valid([]byte(data)) {
+			return zap.Reflect(f.Key, json.RawMessage(data))
+		}
+		return zap.String(f.Key, data)
+	default:
+		return f
+	}
+}
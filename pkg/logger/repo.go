@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 仓库迁移阶段，记录在单仓库日志的 phase 字段
+const (
+	PhaseCheck     = "check"
+	PhaseClone     = "clone"
+	PhaseLargeFile = "large_file"
+	PhaseCreate    = "create"
+	PhaseRebase    = "rebase"
+	PhasePush      = "push"
+	PhaseLFS       = "lfs"
+	PhasePrune     = "prune"
+	PhaseSubmodule = "submodule"
+	PhaseRelease   = "release"
+)
+
+// repoLog 正在迁移的仓库的日志
+type repoLog struct {
+	logger *zap.SugaredLogger
+	file   *os.File
+	phase  string
+}
+
+var (
+	repoLogsMu sync.RWMutex
+	repoLogs   = make(map[string]*repoLog)
+)
+
+// OpenRepo 开始记录仓库日志，之后 Repo(repoPath) 返回的日志都带 repo 字段
+// aliases 为仓库的其他路径（如本地克隆目录），Repo(alias) 返回同一个仓库日志
+// 开启单仓库日志时同时写入 logs/<仓库路径>.log，迁移结束后需调用 CloseRepo
+func OpenRepo(repoPath string, aliases ...string) *zap.SugaredLogger {
+	log := &repoLog{}
+	base := Logger
+	if repoLogDir != "" {
+		file, err := openRepoLogFile(repoPath)
+		if err != nil {
+			Logger.Warnf("%s 打开仓库日志文件失败: %s", repoPath, err)
+		} else {
+			log.file = file
+			core := zapcore.NewTee(Logger.Desugar().Core(), newCore(logFormat, logLevel, zapcore.AddSync(file)))
+			base = zap.New(core, zap.AddCaller()).Sugar()
+		}
+	}
+	log.logger = base.With("repo", repoPath)
+
+	repoLogsMu.Lock()
+	repoLogs[repoPath] = log
+	for _, alias := range aliases {
+		repoLogs[alias] = log
+	}
+	repoLogsMu.Unlock()
+	return log.logger
+}
+
+func openRepoLogFile(repoPath string) (*os.File, error) {
+	name := RepoLogFile(repoPath)
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+}
+
+// RepoLogFile 返回仓库日志文件路径，路径中的 .. 不会跳出日志目录；未开启单仓库日志时返回空字符串
+func RepoLogFile(repoPath string) string {
+	if repoLogDir == "" {
+		return ""
+	}
+	return filepath.Join(repoLogDir, filepath.FromSlash(path.Clean("/"+repoPath))+".log")
+}
+
+// Repo 返回仓库日志，带 repo 及当前 phase 字段；仓库未调用 OpenRepo 时只带 repo 字段
+func Repo(repoPath string) *zap.SugaredLogger {
+	repoLogsMu.RLock()
+	log, ok := repoLogs[repoPath]
+	var phase string
+	if ok {
+		phase = log.phase
+	}
+	repoLogsMu.RUnlock()
+	if !ok {
+		return Logger.With("repo", repoPath)
+	}
+	if phase == "" {
+		return log.logger
+	}
+	return log.logger.With("phase", phase)
+}
+
+// SetPhase 设置仓库当前所处的迁移阶段
+func SetPhase(repoPath, phase string) {
+	repoLogsMu.Lock()
+	defer repoLogsMu.Unlock()
+	if log, ok := repoLogs[repoPath]; ok {
+		log.phase = phase
+	}
+}
+
+// Phase 返回仓库当前所处的迁移阶段
+func Phase(repoPath string) string {
+	repoLogsMu.RLock()
+	defer repoLogsMu.RUnlock()
+	if log, ok := repoLogs[repoPath]; ok {
+		return log.phase
+	}
+	return ""
+}
+
+// CloseRepo 结束记录仓库日志并关闭单仓库日志文件，同时移除 OpenRepo 注册的其他路径
+func CloseRepo(repoPath string) {
+	repoLogsMu.Lock()
+	log, ok := repoLogs[repoPath]
+	for key, l := range repoLogs {
+		if l == log {
+			delete(repoLogs, key)
+		}
+	}
+	repoLogsMu.Unlock()
+	if !ok || log.file == nil {
+		return
+	}
+	_ = log.logger.Sync()
+	if err := log.file.Close(); err != nil {
+		Logger.Warnf("%s 关闭仓库日志文件失败: %s", repoPath, err)
+	}
+}
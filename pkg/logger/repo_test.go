@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRepoLog 测试 JSON 格式日志的 repo、phase、attempt 字段及单仓库日志文件
+func TestRepoLog(t *testing.T) {
+	dir := t.TempDir()
+	if err := Init(Options{Dir: dir, Format: FormatJSON, RepoLog: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	repoPath := "group/sub/repo"
+	repoDir := filepath.Join(dir, "work", repoPath)
+	OpenRepo(repoPath, repoDir)
+	SetPhase(repoPath, PhaseClone)
+	Repo(repoPath).With("attempt", 2).Warnf("%s git clone 失败", repoPath)
+	SetPhase(repoPath, PhasePush)
+	// 按本地克隆目录记录的日志同样写入仓库日志
+	Repo(repoDir).Infof("%s 开始push", repoDir)
+	CloseRepo(repoPath)
+	if got := Phase(repoDir); got != "" {
+		t.Errorf("CloseRepo() 后 Phase(%s) = %s, 期望为空", repoDir, got)
+	}
+	Logger.Infof("迁移完成")
+
+	repoLogFile := filepath.Join(dir, RepoLogDir, "group", "sub", "repo.log")
+	if got := RepoLogFile(repoPath); got != repoLogFile {
+		t.Errorf("RepoLogFile() = %s, 期望 %s", got, repoLogFile)
+	}
+	entries := readJSONLines(t, repoLogFile)
+	if len(entries) != 2 {
+		t.Fatalf("仓库日志期望 2 条，实际 %d 条: %v", len(entries), entries)
+	}
+	expected := []map[string]interface{}{
+		{"repo": repoPath, "phase": PhaseClone, "attempt": float64(2), "level": "WARN"},
+		{"repo": repoPath, "phase": PhasePush, "level": "INFO"},
+	}
+	for i, want := range expected {
+		for key, value := range want {
+			if entries[i][key] != value {
+				t.Errorf("第 %d 条日志 %s = %v, 期望 %v", i+1, key, entries[i][key], value)
+			}
+		}
+	}
+	if _, ok := entries[1]["attempt"]; ok {
+		t.Errorf("attempt 字段不应出现在后续日志中: %v", entries[1])
+	}
+
+	// migrate.log 包含所有仓库及全局日志
+	if got := len(readJSONLines(t, filepath.Join(dir, InfoLog))); got != 3 {
+		t.Errorf("migrate.log 期望 3 条日志，实际 %d 条", got)
+	}
+}
+
+// TestRepoLogPath 测试仓库路径中的 .. 不会跳出日志目录
+func TestRepoLogPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := Init(Options{Dir: dir, RepoLog: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	expected := filepath.Join(dir, RepoLogDir, "etc", "passwd.log")
+	if got := RepoLogFile("../../etc/passwd"); got != expected {
+		t.Errorf("RepoLogFile() = %s, 期望 %s", got, expected)
+	}
+
+	if err := Init(Options{Dir: dir}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if got := RepoLogFile("group/repo"); got != "" {
+		t.Errorf("未开启单仓库日志时期望返回空字符串，实际 %s", got)
+	}
+	if err := Init(Options{Dir: dir, Format: "xml"}); err == nil {
+		t.Error("未知的日志格式期望返回错误")
+	}
+}
+
+func readJSONLines(t *testing.T, name string) []map[string]interface{} {
+	t.Helper()
+	file, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	defer file.Close()
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := make(map[string]interface{})
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("日志不是 JSON 格式: %s", scanner.Text())
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,106 @@
+package migrate
+
+import (
+	"ccrctl/pkg/git"
+	"ccrctl/pkg/logger"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const ChunkPushStateName = "chunk-push-state.json"
+
+var chunkPushStateMutex sync.Mutex
+
+// pushRepo 推送裸仓库到 CNB，开启 migrate.chunk_push 时使用分批推送并记录进度，失败后重新运行可从上次确认的步骤继续
+func (m *Migrator) pushRepo(repoPath, pushURL string, forcePush bool) (string, error) {
+	if !m.opts.ChunkPush {
+		return git.Push(m.repoDir(repoPath), pushURL, forcePush, m.opts.RefFilter, m.gitEnv)
+	}
+	opts := git.ChunkPushOptions{
+		StepSize:     m.opts.ChunkPushStep,
+		RefBatchSize: m.opts.ChunkPushRefBatch,
+		Force:        forcePush,
+		Filter:       m.opts.RefFilter,
+		Env:          m.gitEnv,
+	}
+	statePath := m.chunkPushStatePath()
+	resume, err := loadChunkPushProgress(statePath, repoPath)
+	if err != nil {
+		logger.Repo(repoPath).Warnf("%s 读取分批推送进度失败，将从头推送: %s", repoPath, err)
+	}
+	ack := func(progress git.ChunkPushProgress) {
+		if err := saveChunkPushProgress(statePath, repoPath, &progress); err != nil {
+			logger.Repo(repoPath).Warnf("%s 记录分批推送进度失败: %s", repoPath, err)
+		}
+	}
+	output, err := git.ChunkedPush(m.repoDir(repoPath), pushURL, opts, resume, ack)
+	if err != nil {
+		return output, err
+	}
+	if err := saveChunkPushProgress(statePath, repoPath, nil); err != nil {
+		logger.Repo(repoPath).Warnf("%s 清理分批推送进度失败: %s", repoPath, err)
+	}
+	return output, nil
+}
+
+// chunkPushStatePath 分批推送进度文件路径
+func (m *Migrator) chunkPushStatePath() string {
+	return filepath.Join(m.reportDir, ChunkPushStateName)
+}
+
+// loadChunkPushProgress 读取指定仓库的分批推送进度，文件不存在时返回零值
+func loadChunkPushProgress(statePath, repoPath string) (git.ChunkPushProgress, error) {
+	chunkPushStateMutex.Lock()
+	defer chunkPushStateMutex.Unlock()
+
+	state, err := readChunkPushState(statePath)
+	if err != nil {
+		return git.ChunkPushProgress{}, err
+	}
+	return state[repoPath], nil
+}
+
+// saveChunkPushProgress 更新指定仓库的分批推送进度，progress 为 nil 时删除该仓库的记录
+func saveChunkPushProgress(statePath, repoPath string, progress *git.ChunkPushProgress) error {
+	chunkPushStateMutex.Lock()
+	defer chunkPushStateMutex.Unlock()
+
+	state, err := readChunkPushState(statePath)
+	if err != nil {
+		return err
+	}
+	if progress == nil {
+		if _, ok := state[repoPath]; !ok {
+			return nil
+		}
+		delete(state, repoPath)
+	} else {
+		state[repoPath] = *progress
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statePath, data, 0644)
+}
+
+func readChunkPushState(statePath string) (map[string]git.ChunkPushProgress, error) {
+	state := make(map[string]git.ChunkPushProgress)
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return state, nil
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,39 @@
+package migrate
+
+import (
+	"ccrctl/pkg/git"
+	"path/filepath"
+	"testing"
+)
+
+func TestChunkPushProgressState(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), ChunkPushStateName)
+
+	progress, err := loadChunkPushProgress(statePath, "group/repo")
+	if err != nil || progress.Step != 0 {
+		t.Fatalf("状态文件不存在时应返回零值, got %+v, %v", progress, err)
+	}
+
+	if err := saveChunkPushProgress(statePath, "group/repo", &git.ChunkPushProgress{Step: 3, Commit: "c3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveChunkPushProgress(statePath, "group/other", &git.ChunkPushProgress{Step: 1}); err != nil {
+		t.Fatal(err)
+	}
+	progress, err = loadChunkPushProgress(statePath, "group/repo")
+	if err != nil || progress.Step != 3 || progress.Commit != "c3" {
+		t.Errorf("读取进度错误: %+v, %v", progress, err)
+	}
+
+	if err := saveChunkPushProgress(statePath, "group/repo", nil); err != nil {
+		t.Fatal(err)
+	}
+	progress, _ = loadChunkPushProgress(statePath, "group/repo")
+	if progress.Step != 0 {
+		t.Errorf("清理后进度应为零值: %+v", progress)
+	}
+	progress, _ = loadChunkPushProgress(statePath, "group/other")
+	if progress.Step != 1 {
+		t.Errorf("不应影响其他仓库的进度: %+v", progress)
+	}
+}
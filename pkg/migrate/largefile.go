@@ -0,0 +1,93 @@
+package migrate
+
+import (
+	"ccrctl/pkg/git"
+	"ccrctl/pkg/logger"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	LargeFileReportName = "large-file-report.log"
+
+	// 大文件处理策略
+	LargeFileStrategySkip = "skip" // 跳过该仓库，不迁移
+	LargeFileStrategyLFS  = "lfs"  // 仅将扫描到的大文件路径转换为 LFS，会改变相关提交的 commit ID
+	LargeFileStrategyFail = "fail" // 迁移失败，由用户自行处理
+)
+
+// largeFileStrategyFor 获取指定仓库的大文件处理策略
+// migrate.large_file_repo_strategy 中的 <仓库路径>=<策略> 优先于 migrate.large_file_strategy
+func (m *Migrator) largeFileStrategyFor(repoPath string) string {
+	for _, item := range m.opts.LargeFileRepoStrategy {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if strings.TrimSpace(parts[0]) == repoPath {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+	return m.opts.LargeFileStrategy
+}
+
+// handleLargeFiles 推送前扫描仓库历史中的大文件，并按策略处理
+// 返回值:
+//   - skip: 为 true 时表示按策略跳过该仓库
+//   - error: 按策略判定失败或处理失败时返回错误信息
+func (m *Migrator) handleLargeFiles(repoPath string) (skip bool, err error) {
+	if !m.opts.LargeFileScan {
+		return false, nil
+	}
+	limit := m.opts.FileLimitSize
+	largeFiles, err := git.ScanLargeFiles(m.repoDir(repoPath), limit)
+	if err != nil {
+		return false, err
+	}
+	if len(largeFiles) == 0 {
+		return false, nil
+	}
+	strategy := m.largeFileStrategyFor(repoPath)
+	if reportErr := m.writeLargeFileReport(repoPath, strategy, largeFiles); reportErr != nil {
+		logger.Repo(repoPath).Warnf("%s 写入大文件报告失败: %s", repoPath, reportErr)
+	}
+	switch strategy {
+	case LargeFileStrategySkip:
+		logger.Repo(repoPath).Errorf("%s 历史提交中存在 %d 个超过%dM的文件，按策略 %s 忽略迁移，详见 %s", repoPath, len(largeFiles), limit, strategy, LargeFileReportName)
+		return true, nil
+	case LargeFileStrategyFail:
+		return false, fmt.Errorf("%s 历史提交中存在 %d 个超过%dM的文件，按策略 %s 终止迁移，详见 %s", repoPath, len(largeFiles), limit, strategy, LargeFileReportName)
+	default:
+		paths := git.LargeFilePaths(largeFiles)
+		logger.Repo(repoPath).Warnf("%s 历史提交中存在 %d 个超过%dM的文件，将转换为LFS，相关提交的 commit ID 会发生变化，详见 %s", repoPath, len(largeFiles), limit, LargeFileReportName)
+		if err := git.MigratePathsToLFS(m.repoDir(repoPath), paths); err != nil {
+			return false, fmt.Errorf("%s 大文件转换LFS失败: %s", repoPath, err)
+		}
+		return false, nil
+	}
+}
+
+// writeLargeFileReport 将大文件扫描结果追加写入报告文件
+func (m *Migrator) writeLargeFileReport(repoPath, strategy string, largeFiles []git.LargeFile) error {
+	return m.appendReport(LargeFileReportName, formatLargeFileReport(repoPath, strategy, largeFiles, time.Now()))
+}
+
+// formatLargeFileReport 生成单个仓库的大文件报告内容
+func formatLargeFileReport(repoPath, strategy string, largeFiles []git.LargeFile, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s 大文件数: %d 处理策略: %s\n", now.Format("2006-01-02 15:04:05"), repoPath, len(largeFiles), strategy)
+	if strategy == LargeFileStrategyLFS {
+		b.WriteString("  注意: 以下文件将转换为LFS，引入提交及其之后的所有提交 commit ID 都会发生变化\n")
+	}
+	for _, f := range largeFiles {
+		fmt.Fprintf(&b, "  %s %.1fMiB\n", f.Oid, f.SizeMiB())
+		for _, p := range f.Paths {
+			fmt.Fprintf(&b, "    路径: %s\n", p)
+		}
+		for _, c := range f.Commits {
+			fmt.Fprintf(&b, "    引入提交: %s\n", c)
+		}
+	}
+	return b.String()
+}
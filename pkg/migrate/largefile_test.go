@@ -0,0 +1,76 @@
+package migrate
+
+import (
+	"ccrctl/pkg/git"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestLargeFileStrategyFor(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("migrate.large_file_strategy", "lfs")
+	cfg.Set("migrate.large_file_repo_strategy", []string{"group/repo1=skip", " group/repo2 = fail ", "invalid"})
+	m := NewMigrator(OptionsFromConfig(cfg))
+
+	tests := []struct {
+		repoPath string
+		expected string
+	}{
+		{repoPath: "group/repo1", expected: LargeFileStrategySkip},
+		{repoPath: "group/repo2", expected: LargeFileStrategyFail},
+		{repoPath: "group/repo3", expected: LargeFileStrategyLFS},
+	}
+	for _, tt := range tests {
+		if result := m.largeFileStrategyFor(tt.repoPath); result != tt.expected {
+			t.Errorf("largeFileStrategyFor(%s) = %s, 期望 %s", tt.repoPath, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatLargeFileReport(t *testing.T) {
+	largeFiles := []git.LargeFile{
+		{
+			Oid:     "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			Size:    300 * 1024 * 1024,
+			Paths:   []string{"assets/big.bin", "assets/copy.bin"},
+			Commits: []string{"1111111111111111111111111111111111111111"},
+		},
+	}
+	fileLines := []string{
+		"  aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa 300.0MiB",
+		"    路径: assets/big.bin",
+		"    路径: assets/copy.bin",
+		"    引入提交: 1111111111111111111111111111111111111111",
+	}
+	tests := []struct {
+		strategy string
+		want     []string
+	}{
+		{
+			strategy: LargeFileStrategyLFS,
+			want: append([]string{
+				"group/repo 大文件数: 1 处理策略: lfs",
+				"  注意: 以下文件将转换为LFS，引入提交及其之后的所有提交 commit ID 都会发生变化",
+			}, fileLines...),
+		},
+		{
+			strategy: LargeFileStrategySkip,
+			want:     append([]string{"group/repo 大文件数: 1 处理策略: skip"}, fileLines...),
+		},
+		{
+			strategy: LargeFileStrategyFail,
+			want:     append([]string{"group/repo 大文件数: 1 处理策略: fail"}, fileLines...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			lines := reportLines(t, formatLargeFileReport("group/repo", tt.strategy, largeFiles, time.Now()))
+			if !reflect.DeepEqual(lines, tt.want) {
+				t.Errorf("formatLargeFileReport() = %q, 期望 %q", lines, tt.want)
+			}
+		})
+	}
+}
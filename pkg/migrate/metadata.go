@@ -0,0 +1,66 @@
+package migrate
+
+import (
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/vcs"
+	"fmt"
+	"time"
+)
+
+// MetadataFilter 按仓库元数据过滤，平台未返回的元数据不参与过滤
+type MetadataFilter struct {
+	SkipArchived bool
+	SkipFork     bool
+	// MaxSizeMB 仓库大小上限，0 表示不限制
+	MaxSizeMB int64
+	// ActiveSince 最后推送时间早于该时间的仓库不迁移，零值表示不限制
+	ActiveSince time.Time
+	// OnlyPrivate 只迁移私有及内部公开仓库
+	OnlyPrivate bool
+}
+
+// Active 是否配置了过滤条件
+func (f MetadataFilter) Active() bool {
+	return f.SkipArchived || f.SkipFork || f.MaxSizeMB > 0 || !f.ActiveSince.IsZero() || f.OnlyPrivate
+}
+
+// Exclude 判断仓库是否需要排除，返回排除的原因，不排除时返回空字符串
+func (f MetadataFilter) Exclude(metadata vcs.Metadata) string {
+	if f.SkipArchived && metadata.Archived {
+		return "skip_archived: 已归档"
+	}
+	if f.SkipFork && metadata.Fork {
+		return "skip_fork: fork 仓库"
+	}
+	if f.MaxSizeMB > 0 && metadata.SizeKB > f.MaxSizeMB*1024 {
+		return fmt.Sprintf("max_size_mb: %dMB 超过 %dMB", metadata.SizeKB/1024, f.MaxSizeMB)
+	}
+	if !f.ActiveSince.IsZero() && !metadata.PushedAt.IsZero() && metadata.PushedAt.Before(f.ActiveSince) {
+		return fmt.Sprintf("active_since: 最后推送于 %s", metadata.PushedAt.Format(time.DateOnly))
+	}
+	if f.OnlyPrivate && metadata.Visibility == vcs.VisibilityPublic {
+		return "only_private: 公开仓库"
+	}
+	return ""
+}
+
+// filterReposByMetadata 根据仓库元数据（归档、fork、大小、最后推送时间、可见性）过滤仓库列表
+func (m *Migrator) filterReposByMetadata(depotList []vcs.VCS) []vcs.VCS {
+	filter := m.opts.MetadataFilter
+	if !filter.Active() {
+		return depotList
+	}
+
+	filteredDepotList := make([]vcs.VCS, 0, len(depotList))
+	for _, depot := range depotList {
+		repoPath := depot.GetRepoPath()
+		if reason := filter.Exclude(depot.GetMetadata()); reason != "" {
+			m.recordSelection(repoPath, reason)
+			logger.Logger.Infof("跳过仓库 %s（%s）", repoPath, reason)
+			continue
+		}
+		filteredDepotList = append(filteredDepotList, depot)
+	}
+	logger.Logger.Infof("根据仓库元数据过滤后，待迁移仓库数: %d", len(filteredDepotList))
+	return filteredDepotList
+}
@@ -0,0 +1,61 @@
+package migrate
+
+import (
+	"ccrctl/pkg/vcs"
+	"testing"
+	"time"
+)
+
+func TestMetadataFilterExclude(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := MetadataFilter{SkipArchived: true, SkipFork: true, MaxSizeMB: 100, ActiveSince: since, OnlyPrivate: true}
+	tests := []struct {
+		name     string
+		metadata vcs.Metadata
+		want     string
+	}{
+		{name: "满足条件", metadata: vcs.Metadata{SizeKB: 1024, PushedAt: since.AddDate(0, 1, 0), Visibility: vcs.VisibilityPrivate}, want: ""},
+		{name: "元数据未知", metadata: vcs.Metadata{}, want: ""},
+		{name: "内部公开", metadata: vcs.Metadata{Visibility: vcs.VisibilityInternal}, want: ""},
+		{name: "已归档", metadata: vcs.Metadata{Archived: true}, want: "skip_archived: 已归档"},
+		{name: "fork", metadata: vcs.Metadata{Fork: true}, want: "skip_fork: fork 仓库"},
+		{name: "超过大小", metadata: vcs.Metadata{SizeKB: 200 * 1024}, want: "max_size_mb: 200MB 超过 100MB"},
+		{name: "等于大小", metadata: vcs.Metadata{SizeKB: 100 * 1024}, want: ""},
+		{name: "不活跃", metadata: vcs.Metadata{PushedAt: since.AddDate(0, -1, 0)}, want: "active_since: 最后推送于 2023-12-01"},
+		{name: "公开仓库", metadata: vcs.Metadata{Visibility: vcs.VisibilityPublic}, want: "only_private: 公开仓库"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Exclude(tt.metadata); got != tt.want {
+				t.Errorf("Exclude() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterReposByMetadata(t *testing.T) {
+	m := NewMigrator(Options{MetadataFilter: MetadataFilter{SkipArchived: true}})
+	depotList := []vcs.VCS{
+		&MockVCS{repoPath: "group/active"},
+		&MockVCS{repoPath: "group/archived", metadata: vcs.Metadata{Archived: true}},
+	}
+	result := m.filterReposByMetadata(depotList)
+	if len(result) != 1 || result[0].GetRepoPath() != "group/active" {
+		t.Fatalf("filterReposByMetadata() = %v", result)
+	}
+	if m.selection["group/archived"] != "skip_archived: 已归档" {
+		t.Errorf("未记录排除原因: %v", m.selection)
+	}
+	if got := NewMigrator(Options{}).filterReposByMetadata(depotList); len(got) != 2 {
+		t.Errorf("未配置过滤条件时不应过滤，got %d", len(got))
+	}
+}
+
+func TestActiveSince(t *testing.T) {
+	if got := activeSince("2024-01-02"); got.Format(time.DateOnly) != "2024-01-02" {
+		t.Errorf("activeSince() = %v", got)
+	}
+	if got := activeSince(""); !got.IsZero() {
+		t.Errorf("activeSince(\"\") = %v, 期望零值", got)
+	}
+}
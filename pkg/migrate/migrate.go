@@ -3,10 +3,13 @@ package migrate
 import (
 	"ccrctl/pkg/api/aliyun"
 	"ccrctl/pkg/api/target"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/git"
 	"ccrctl/pkg/http_client"
 	"ccrctl/pkg/logger"
+	"ccrctl/pkg/naming"
+	"ccrctl/pkg/redact"
+	"ccrctl/pkg/report"
+	"ccrctl/pkg/selector"
 	"ccrctl/pkg/system"
 	"ccrctl/pkg/util"
 	"ccrctl/pkg/vcs"
@@ -15,6 +18,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -88,36 +92,82 @@ const (
 	MaxConcurrency  = 10
 	RebaseDirPrefix = "rebase"
 	RepoPathFile    = "repo-path.txt"
+	// SuccessfulLogName 记录迁移成功的仓库，再次迁移时跳过其中的仓库
+	SuccessfulLogName = "successful.log"
 )
 
-var (
-	CnbURL                   = config.Cfg.GetString("cnb.url")
-	CnbApiURL                = config.ConvertToApiURL(CnbURL)
-	CnbToken                 = config.Cfg.GetString("cnb.token")
-	Concurrency              = config.Cfg.GetInt("migrate.concurrency")
-	totalRepoNumber          int64
-	skipRepoNumber           int64
-	successfulRepoNumber     int64
-	failedRepoNumber         int64
-	useLfsMigrate            = config.Cfg.GetBool("migrate.use_lfs_migrate")
-	organizationMappingLevel = config.Cfg.GetInt("migrate.organization_mapping_level")
-	SourcePlatformName       = config.Cfg.GetString("source.platform")
-	SkipExistsRepo           = config.Cfg.GetBool("migrate.skip_exists_repo")
-	MigrateRelease           = config.Cfg.GetBool("migrate.release")
-	MigrateCode              = config.Cfg.GetBool("migrate.code")
-	MigrateRebase            = config.Cfg.GetBool("migrate.rebase")
-	DownloadOnly             = config.Cfg.GetBool("migrate.download_only")
-	RootGroupName            = config.Cfg.GetString("cnb.root_organization")
-	rebaseBackDirPath        string
-	rebaseBranchesMap        sync.Map
-	workDirCreated           bool
-)
+// Migrator 按 Options 执行一次迁移，不依赖全局配置，同一进程内可以创建多个
+type Migrator struct {
+	opts   Options
+	target *target.Client
+
+	totalRepoNumber      int64
+	skipRepoNumber       int64
+	successfulRepoNumber int64
+	failedRepoNumber     int64
+
+	rebaseBackDirPath string
+	// workDir 仓库工作目录的绝对路径，仓库克隆到 workDir/<仓库路径>
+	workDir        string
+	workDirCreated bool
+	// stopInterrupt 取消中断信号时删除工作目录的登记
+	stopInterrupt func()
+	// gitEnv 追加到 git clone/push/ls-remote 命令的环境变量，开启 SSH 时包含 GIT_SSH_COMMAND
+	gitEnv []string
+	// reportDir successful.log、迁移报告及迁移状态文件所在目录的绝对路径
+	reportDir string
+
+	// results 每个仓库的迁移结果，用于生成迁移报告
+	resultsMu sync.Mutex
+	results   []report.Repo
+	// notFoundRepos source.repo 中配置但源平台未找到的仓库，计入迁移失败
+	notFoundRepos []string
+	// selection 仓库选择过程中决定仓库被选中或排除的规则，用于 plan 输出
+	selection map[string]string
+	// snapshots 仓库快照，与 results 一起写入迁移状态文件
+	snapshots map[string]*vcs.Snapshot
+	// previousState retry-failed 读取的上次迁移状态，本次结果合并后写回
+	previousState *State
+	// submoduleURLMap 规范化的源仓库地址 → CNB 仓库地址，由本次迁移的仓库列表生成
+	submoduleURLMap map[string]string
+	// targets 源仓库路径 → 根组织下的目标路径，由 resolveTargets 在迁移前计算
+	targets map[string]naming.Target
+	// targetRules 源仓库路径 → 决定目标路径的规则，按 organization_mapping_level 计算时为空
+	targetRules map[string]string
+}
+
+// NewMigrator 创建迁移器
+func NewMigrator(opts Options) *Migrator {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Concurrency > MaxConcurrency {
+		opts.Concurrency = MaxConcurrency
+	}
+	if opts.WorkDir != "" && opts.Vcs.Source.Dir == "" {
+		opts.Vcs.Source.Dir = opts.WorkDir
+	}
+	reportDir := opts.ReportDir
+	if reportDir == "" {
+		reportDir = opts.Log.Dir
+	}
+	if dir, err := filepath.Abs(reportDir); err == nil {
+		reportDir = dir
+	}
+	client := target.NewClient(opts.CnbURL, opts.CnbToken, opts.RootOrganization)
+	client.SSHPort = opts.CnbSSHPort
+	return &Migrator{
+		opts:      opts,
+		target:    client,
+		reportDir: reportDir,
+	}
+}
 
 // checkAndGetRepoList 检查并获取仓库列表
 // 如果启用了仓库选择功能且 repo-path.txt 不存在，则获取仓库列表并写入文件
 // 返回是否需要继续执行迁移
-func checkAndGetRepoList(source vcs.VCS) (bool, error) {
-	if !config.Cfg.GetBool("migrate.allow_select_repos") {
+func (m *Migrator) checkAndGetRepoList(source vcs.VCS) (bool, error) {
+	if !m.opts.AllowSelectRepos {
 		return true, nil
 	}
 
@@ -135,8 +185,8 @@ func checkAndGetRepoList(source vcs.VCS) (bool, error) {
 }
 
 // filterReposBySelection 根据 repo-path.txt 过滤仓库列表
-func filterReposBySelection(depotList []vcs.VCS) ([]vcs.VCS, error) {
-	if !config.Cfg.GetBool("migrate.allow_select_repos") {
+func (m *Migrator) filterReposBySelection(depotList []vcs.VCS) ([]vcs.VCS, error) {
+	if !m.opts.AllowSelectRepos {
 		return depotList, nil
 	}
 
@@ -150,6 +200,7 @@ func filterReposBySelection(depotList []vcs.VCS) ([]vcs.VCS, error) {
 		if selectedRepos[depot.GetRepoPath()] {
 			filteredDepotList = append(filteredDepotList, depot)
 		} else {
+			m.recordSelection(depot.GetRepoPath(), RepoPathFile+": 未选择")
 			logger.Logger.Infof("跳过仓库 %s（未在 %s 中选择）", depot.GetRepoPath(), RepoPathFile)
 		}
 	}
@@ -160,16 +211,16 @@ func filterReposBySelection(depotList []vcs.VCS) ([]vcs.VCS, error) {
 // 当 source.repo 不为空时，只保留在配置列表中的仓库
 // 注意：common 和 local 平台不需要过滤，因为它们的 source.repo 是必填项，直接指定要迁移的仓库
 // 返回值: 过滤后的仓库列表, 未匹配到的仓库数量
-func filterReposByConfigList(depotList []vcs.VCS) ([]vcs.VCS, int) {
+func (m *Migrator) filterReposByConfigList(depotList []vcs.VCS) ([]vcs.VCS, int) {
 	// common 和 local 平台不需要过滤
-	if SourcePlatformName == "common" || SourcePlatformName == "local" {
+	if m.opts.SourcePlatform == "common" || m.opts.SourcePlatform == "local" {
 		return depotList, 0
 	}
 
-	configRepos := config.Cfg.GetStringSlice("source.repo")
+	configRepos := m.opts.SourceRepos
 
 	// 如果配置为空，返回完整列表
-	if len(configRepos) == 0 || (len(configRepos) == 1 && configRepos[0] == "") {
+	if len(configRepos) == 0 {
 		return depotList, 0
 	}
 
@@ -201,7 +252,10 @@ func filterReposByConfigList(depotList []vcs.VCS) ([]vcs.VCS, int) {
 		if repoMap[repoPath] {
 			filteredDepotList = append(filteredDepotList, depot)
 			matchedRepos[repoPath] = true
-			logger.Logger.Infof("匹配到配置仓库: %s", repoPath)
+			m.recordSelection(repoPath, "source.repo")
+			logger.Repo(repoPath).Infof("匹配到配置仓库: %s", repoPath)
+		} else {
+			m.recordSelection(repoPath, "source.repo: 未配置")
 		}
 	}
 
@@ -209,7 +263,8 @@ func filterReposByConfigList(depotList []vcs.VCS) ([]vcs.VCS, int) {
 	notFoundCount := 0
 	for repoPath := range repoMap {
 		if !matchedRepos[repoPath] {
-			logger.Logger.Errorf("配置的仓库 %s 在源平台未找到，将计入迁移失败", repoPath)
+			logger.Repo(repoPath).Errorf("配置的仓库 %s 在源平台未找到，将计入迁移失败", repoPath)
+			m.notFoundRepos = append(m.notFoundRepos, repoPath)
 			notFoundCount++
 		}
 	}
@@ -218,29 +273,102 @@ func filterReposByConfigList(depotList []vcs.VCS) ([]vcs.VCS, int) {
 	return filteredDepotList, notFoundCount
 }
 
-// initMigrationStats 初始化迁移统计信息
-func initMigrationStats(depotList []vcs.VCS) {
-	atomic.StoreInt64(&totalRepoNumber, int64(len(depotList)))
-	atomic.StoreInt64(&failedRepoNumber, int64(len(depotList)))
-	atomic.StoreInt64(&successfulRepoNumber, 0)
-	atomic.StoreInt64(&skipRepoNumber, 0)
+// filterReposByRules 根据 source.include / source.exclude 规则过滤仓库列表，对所有平台生效
+func (m *Migrator) filterReposByRules(depotList []vcs.VCS) ([]vcs.VCS, error) {
+	filter, err := selector.New(m.opts.SourceInclude, m.opts.SourceExclude)
+	if err != nil {
+		return nil, err
+	}
+	if !filter.Active() {
+		return depotList, nil
+	}
+
+	filteredDepotList := make([]vcs.VCS, 0, len(depotList))
+	for _, depot := range depotList {
+		repoPath := depot.GetRepoPath()
+		decision := filter.Match(repoPath)
+		if decision.Rule != "" {
+			m.recordSelection(repoPath, decision.Rule)
+		}
+		if decision.Selected {
+			filteredDepotList = append(filteredDepotList, depot)
+		} else {
+			logger.Logger.Infof("跳过仓库 %s（%s）", repoPath, decision.Rule)
+		}
+	}
+	logger.Logger.Infof("根据 source.include / source.exclude 规则过滤后，待迁移仓库数: %d", len(filteredDepotList))
+	return filteredDepotList, nil
+}
+
+// recordSelection 记录决定仓库被选中或排除的规则
+func (m *Migrator) recordSelection(repoPath, rule string) {
+	if m.selection == nil {
+		m.selection = make(map[string]string)
+	}
+	m.selection[repoPath] = rule
 }
 
-func Run() int {
-	startTime := time.Now()     // 记录迁移开始时间
-	err := config.CheckConfig() // 检查配置文件
+// filterCandidates 依次按 source.repo、source.include / source.exclude、仓库元数据过滤仓库列表
+// 返回值: 过滤后的仓库列表, source.repo 中未匹配到的仓库数量
+func (m *Migrator) filterCandidates(depotList []vcs.VCS) ([]vcs.VCS, int, error) {
+	// 先根据 source.repo 配置过滤（如果配置了的话）
+	depotList, notFoundRepoCount := m.filterReposByConfigList(depotList)
+
+	// 再根据 source.include / source.exclude 规则过滤
+	depotList, err := m.filterReposByRules(depotList)
 	if err != nil {
-		logger.Logger.Errorf("配置文件校验失败: %s", err)
-		return 1
+		return nil, 0, err
 	}
-	logger.Logger.Infof("源平台%s", config.Cfg.GetString("source.platform"))
-	if SourcePlatformName == "aliyun" {
+
+	// 再根据仓库元数据过滤
+	return m.filterReposByMetadata(depotList), notFoundRepoCount, nil
+}
+
+// selectRepos 按配置过滤仓库列表后，再按 repo-path.txt 过滤
+// plan 为 true 时 repo-path.txt 不存在则不按其过滤
+// 返回值: 过滤后的仓库列表, source.repo 中未匹配到的仓库数量
+func (m *Migrator) selectRepos(depotList []vcs.VCS, plan bool) ([]vcs.VCS, int, error) {
+	depotList, notFoundRepoCount, err := m.filterCandidates(depotList)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 最后根据 repo-path.txt 文件过滤（如果启用了仓库选择功能）
+	if plan && m.opts.AllowSelectRepos {
+		if _, err := os.Stat(RepoPathFile); err != nil {
+			logger.Logger.Warnf("%s 不存在，迁移计划未按仓库选择过滤", RepoPathFile)
+			return depotList, notFoundRepoCount, nil
+		}
+	}
+	depotList, err = m.filterReposBySelection(depotList)
+	if err != nil {
+		return nil, 0, err
+	}
+	return depotList, notFoundRepoCount, nil
+}
+
+// initMigrationStats 初始化迁移统计信息，notFoundRepoCount 为 source.repo 中未匹配到的仓库数量
+// 仓库总数 = 过滤后的待迁移数 + 未找到数，被 source.exclude、skip_archived 等规则过滤掉的仓库不计入统计
+// 未找到的仓库直接计入失败数，待迁移的仓库在迁移成功或跳过后从失败数中扣除
+func (m *Migrator) initMigrationStats(depotList []vcs.VCS, notFoundRepoCount int) {
+	total := int64(len(depotList) + notFoundRepoCount)
+	atomic.StoreInt64(&m.totalRepoNumber, total)
+	atomic.StoreInt64(&m.failedRepoNumber, total)
+	atomic.StoreInt64(&m.successfulRepoNumber, 0)
+	atomic.StoreInt64(&m.skipRepoNumber, 0)
+}
+
+// Run 执行迁移，返回进程退出码，配置需由调用方提前校验
+func (m *Migrator) Run() int {
+	startTime := time.Now() // 记录迁移开始时间
+	logger.Logger.Infof("源平台%s", m.opts.SourcePlatform)
+	if m.opts.SourcePlatform == "aliyun" {
 		logger.Logger.Infof("SOURCE_URL: %s", aliyun.AliyunEndpoint)
 	} else {
-		logger.Logger.Infof("SOURCE_URL: %s", config.Cfg.GetString("source.url"))
+		logger.Logger.Infof("SOURCE_URL: %s", m.opts.SourceURL)
 	}
-	logger.Logger.Infof("CNB_URL: %s", config.Cfg.GetString("cnb.url"))
-	err = system.SetFileDescriptorLimit(system.Limit) // 设置文件描述符限制
+	logger.Logger.Infof("CNB_URL: %s", m.opts.CnbURL)
+	err := system.SetFileDescriptorLimit(system.Limit) // 设置文件描述符限制
 	if err != nil {
 		logger.Logger.Errorf("设置文件描述符限制失败: %s", err)
 
@@ -248,7 +376,7 @@ func Run() int {
 	}
 
 	// 获取源平台的 VCS 实例列表
-	sourceVcsList, err := vcs.NewVcs(SourcePlatformName)
+	sourceVcsList, err := vcs.New(m.opts.SourcePlatform, m.opts.Vcs)
 	if err != nil {
 		logger.Logger.Errorf("获取源平台仓库列表失败，请检查配置参数: %s", err)
 		return 1
@@ -260,7 +388,7 @@ func Run() int {
 	sourceVcs := sourceVcsList[0] // 使用第一个实例
 
 	// 检查是否需要获取仓库列表
-	shouldContinue, err := checkAndGetRepoList(sourceVcs)
+	shouldContinue, err := m.checkAndGetRepoList(sourceVcs)
 	if err != nil {
 		logger.Logger.Errorf("%s", err)
 		return 1
@@ -273,12 +401,7 @@ func Run() int {
 	depotList := sourceVcsList
 	logger.Logger.Infof("从源平台获取到仓库总数: %d", len(depotList))
 
-	// 先根据 source.repo 配置过滤（如果配置了的话）
-	var notFoundRepoCount int
-	depotList, notFoundRepoCount = filterReposByConfigList(depotList)
-
-	// 再根据 repo-path.txt 文件过滤（如果启用了仓库选择功能）
-	depotList, err = filterReposBySelection(depotList)
+	depotList, notFoundRepoCount, err := m.selectRepos(depotList, false)
 	if err != nil {
 		logger.Logger.Errorf("%s", err)
 		return 1
@@ -286,45 +409,73 @@ func Run() int {
 
 	logger.Logger.Infof("经过过滤后，待迁移仓库总数: %d", len(depotList))
 
-	// 初始化迁移统计：如果配置了 source.repo，仓库总数应该包含未找到的仓库
-	configRepos := config.Cfg.GetStringSlice("source.repo")
-	if len(configRepos) > 0 && configRepos[0] != "" {
-		// 计算配置的仓库总数（去除空字符串）
-		configRepoCount := 0
-		for _, repo := range configRepos {
-			if strings.TrimSpace(repo) != "" {
-				configRepoCount++
-			}
+	// 初始化迁移统计：配置了 source.repo 时仓库总数包含未找到的仓库
+	m.initMigrationStats(depotList, notFoundRepoCount)
+
+	return m.migrate(depotList, startTime)
+}
+
+// RunRetry 只重新迁移上次迁移状态中失败的仓库，reasons 不为空时按失败原因过滤，不重新获取源平台仓库列表
+func (m *Migrator) RunRetry(state *State, reasons []string) int {
+	startTime := time.Now()
+	if state.SourcePlatform != m.opts.SourcePlatform {
+		logger.Logger.Errorf("迁移状态文件的源平台 %s 与当前配置 %s 不一致", state.SourcePlatform, m.opts.SourcePlatform)
+		return 1
+	}
+	if err := system.SetFileDescriptorLimit(system.Limit); err != nil {
+		logger.Logger.Errorf("设置文件描述符限制失败: %s", err)
+		return 1
+	}
+
+	failed := state.Failed(reasons)
+	depotList := make([]vcs.VCS, 0, len(failed))
+	for _, repo := range failed {
+		if repo.Repo == nil {
+			logger.Repo(repo.Result.Source).Warnf("%s 上次迁移时在源平台未找到，无法重试，请检查 source.repo 配置", repo.Result.Source)
+			continue
 		}
-		// 仓库总数 = 配置的仓库数量
-		atomic.StoreInt64(&totalRepoNumber, int64(configRepoCount))
-		// 失败数 = 待迁移数 + 未找到数
-		atomic.StoreInt64(&failedRepoNumber, int64(len(depotList)+notFoundRepoCount))
-		// 未找到的仓库直接计入失败数，成功数和跳过数初始化为0
-		atomic.StoreInt64(&successfulRepoNumber, 0)
-		atomic.StoreInt64(&skipRepoNumber, 0)
-	} else {
-		// 没有配置 source.repo，使用原有逻辑
-		initMigrationStats(depotList)
+		depot, err := repo.Repo.Restore(m.opts.Vcs)
+		if err != nil {
+			logger.Repo(repo.Result.Source).Errorf("%s %s", repo.Result.Source, err)
+			return 1
+		}
+		depotList = append(depotList, depot)
+	}
+	logger.Logger.Infof("上次迁移失败仓库数: %d，本次重试仓库数: %d", len(failed), len(depotList))
+	if len(depotList) == 0 {
+		logger.Logger.Infof("没有需要重试的仓库")
+		return 0
 	}
 
+	m.previousState = state
+	m.initMigrationStats(depotList, 0)
+	return m.migrate(depotList, startTime)
+}
+
+// migrate 创建 CNB 组织、准备工作目录后迁移 depotList 中的仓库
+func (m *Migrator) migrate(depotList []vcs.VCS, startTime time.Time) int {
 	// 如果不是只下载模式，则执行 CNB 相关操作
-	if !DownloadOnly {
+	if !m.opts.DownloadOnly {
+		// 创建任何组织或仓库之前检查目标路径
+		if err := m.resolveTargets(depotList); err != nil {
+			logger.Logger.Errorf("%s", err)
+			return 1
+		}
 		// 检查根组织
-		logger.Logger.Infof("检查根组织%s是否存在", RootGroupName)
-		exist, err := target.RootOrganizationExists(CnbApiURL, CnbToken)
+		logger.Logger.Infof("检查根组织%s是否存在", m.opts.RootOrganization)
+		exist, err := m.target.RootOrganizationExists()
 		if err != nil {
 			logger.Logger.Errorf("判断根组织是否存在失败: %s", err)
 			return 1
 		}
 		if !exist {
-			logger.Logger.Errorf("根组织%s不存在，请先创建根组织", RootGroupName)
+			logger.Logger.Errorf("根组织%s不存在，请先创建根组织", m.opts.RootOrganization)
 			return 1
 		}
 		// 创建子组织（如果需要）
-		if organizationMappingLevel == 1 {
+		if subGroups := m.targetSubGroups(depotList); len(subGroups) > 0 {
 			logger.Logger.Infof("开始创建子组织")
-			err = target.CreateSubOrganizationIfNotExists(CnbApiURL, CnbToken, depotList)
+			err = m.target.CreateSubOrganizationIfNotExists(subGroups)
 			if err != nil {
 				logger.Logger.Errorf("创建子组织失败: %s", err)
 				return 1
@@ -332,82 +483,56 @@ func Run() int {
 		}
 	}
 
-	// 设置并发数
-	if Concurrency > MaxConcurrency {
-		Concurrency = MaxConcurrency
-	}
-
 	// 处理 SSH 配置
-	if config.Cfg.GetBool("migrate.ssh") {
-		if err := setupSSH(); err != nil {
-			panic(err)
-		}
+	sshEnv, err := m.setupSSH(depotList)
+	if err != nil {
+		logger.Logger.Errorf("SSH配置失败: %s", err)
+		return 1
+	}
+	defer sshEnv.Close()
+	if sshEnv != nil {
+		m.gitEnv = sshEnv.Env
 	}
 
 	// 设置工作目录
-	if err := setupWorkDir(); err != nil {
+	if err := m.setupWorkDir(); err != nil {
 		logger.Logger.Errorf("%s", err)
 		return 1
 	}
+	defer m.stopInterrupt()
 
 	// defer 删除 source_git_dir 目录，确保所有迁移操作完成后再清理（仅删除本工具创建的目录，且非 local 平台）
-	pwdDir, err := os.Getwd()
-	if err == nil && !DownloadOnly && workDirCreated && SourcePlatformName != "local" {
-		gitDirABSPath := filepath.Join(pwdDir, "..", GitDirName)
+	if !m.opts.DownloadOnly && m.workDirCreated && m.opts.SourcePlatform != "local" {
 		defer func(path string) {
 			_ = os.RemoveAll(path)
-		}(gitDirABSPath)
-	}
-
-	// 执行迁移
-	return executeMigration(depotList, startTime)
-}
-
-// setupSSH 设置 SSH 配置
-func setupSSH() error {
-	sourceKeyPath := "ssh.key"
-	sshDir := filepath.Join(os.Getenv("HOME"), ".ssh")
-
-	if err := os.MkdirAll(sshDir, 0700); err != nil {
-		return fmt.Errorf("创建.ssh目录失败: %v", err)
-	}
-
-	if _, err := os.Stat(sourceKeyPath); os.IsNotExist(err) {
-		return fmt.Errorf("SSH私钥文件 %s 不存在于当前目录", sourceKeyPath)
-	}
-
-	privateKeyPath := filepath.Join(sshDir, "id_rsa")
-	keyData, err := os.ReadFile(sourceKeyPath)
-	if err != nil {
-		return fmt.Errorf("读取SSH私钥文件失败: %v", err)
+		}(m.workDir)
 	}
 
-	if err := os.WriteFile(privateKeyPath, keyData, 0600); err != nil {
-		return fmt.Errorf("复制SSH私钥文件失败: %v", err)
-	}
+	m.submoduleURLMap = m.buildSubmoduleURLMap(depotList)
 
-	logger.Logger.Infof("已成功复制SSH私钥文件从 %s 到 %s", sourceKeyPath, privateKeyPath)
-	return nil
+	// 执行迁移
+	return m.executeMigration(depotList, startTime)
 }
 
-// setupWorkDir 设置工作目录
-func setupWorkDir() error {
-	var workDirName string
-	if DownloadOnly {
-		// 在只下载模式下，使用带时间戳的目录名
-		timestamp := time.Now().Format("20060102150405")
-		workDirName = fmt.Sprintf("%s_%s", GitDirName, timestamp)
-	} else {
+// setupWorkDir 设置工作目录，未指定 WorkDir 时使用当前目录下的 source_git_dir
+func (m *Migrator) setupWorkDir() error {
+	workDirName := m.opts.WorkDir
+	if workDirName == "" {
 		workDirName = GitDirName
+		if m.opts.DownloadOnly {
+			// 在只下载模式下，使用带时间戳的目录名
+			timestamp := time.Now().Format("20060102150405")
+			workDirName = fmt.Sprintf("%s_%s", GitDirName, timestamp)
+		}
 	}
 
 	if st, err := os.Stat(workDirName); err != nil {
 		if os.IsNotExist(err) {
-			if err := os.Mkdir(workDirName, 0755); err != nil {
+			if err := os.MkdirAll(workDirName, 0755); err != nil {
 				return fmt.Errorf("创建Git工作目录失败: %s", err)
 			}
 			logger.Logger.Infof("创建仓库工作目录%s成功", workDirName)
-			workDirCreated = true
+			m.workDirCreated = true
 		} else {
 			return fmt.Errorf("检查Git工作目录失败: %s", err)
 		}
@@ -417,30 +542,31 @@ func setupWorkDir() error {
 		logger.Logger.Infof("使用已有仓库工作目录%s", workDirName)
 	}
 
-	pwdDir, err := os.Getwd()
+	workDir, err := filepath.Abs(workDirName)
 	if err != nil {
-		return fmt.Errorf("获取当前工作目录失败: %s", err)
+		return fmt.Errorf("获取Git工作目录绝对路径失败: %s", err)
 	}
+	m.workDir = workDir
 
-	gitDirABSPath := filepath.Join(pwdDir, workDirName)
-
-	system.HandleInterrupt(gitDirABSPath)
-
-	if MigrateRebase {
-		if err := setupRebase(pwdDir); err != nil {
+	if m.opts.Rebase {
+		// rebase 备份目录与工作目录位于同一目录下
+		if err := m.setupRebase(filepath.Dir(m.workDir)); err != nil {
 			return err
 		}
 	}
 
-	if err := os.Chdir(workDirName); err != nil {
-		return fmt.Errorf("切换到Git工作目录失败: %s", err)
-	}
+	m.stopInterrupt = system.HandleInterrupt(m.workDir)
 
 	return nil
 }
 
-// setupRebase 设置 rebase 相关配置
-func setupRebase(pwdDir string) error {
+// repoDir 返回仓库在工作目录下的本地路径
+func (m *Migrator) repoDir(repoPath string) string {
+	return filepath.Join(m.workDir, repoPath)
+}
+
+// setupRebase 设置 rebase 相关配置，并在 dir 下创建 rebase 备份目录
+func (m *Migrator) setupRebase(dir string) error {
 	if err := system.SetGlobalGitUser(); err != nil {
 		return fmt.Errorf("设置全局Git用户失败: %s", err)
 	}
@@ -449,23 +575,23 @@ func setupRebase(pwdDir string) error {
 		return fmt.Errorf("设置默认远程仓库失败: %s", err)
 	}
 
-	rebaseBackDirPath = filepath.Join(pwdDir, time.Now().Format("200601021504")+"bak")
-	if err := os.Mkdir(rebaseBackDirPath, 0755); err != nil {
+	m.rebaseBackDirPath = filepath.Join(dir, time.Now().Format("200601021504")+"bak")
+	if err := os.Mkdir(m.rebaseBackDirPath, 0755); err != nil {
 		return fmt.Errorf("创建rebase备份目录失败: %s", err)
 	}
-	logger.Logger.Infof("创建rebase备份目录%s成功", rebaseBackDirPath)
+	logger.Logger.Infof("创建rebase备份目录%s成功", m.rebaseBackDirPath)
 
 	return nil
 }
 
 // executeMigration 执行迁移操作
-func executeMigration(depotList []vcs.VCS, startTime time.Time) int {
-	if DownloadOnly {
-		logger.Logger.Infof("开始下载仓库，当前并发数:%d", Concurrency)
+func (m *Migrator) executeMigration(depotList []vcs.VCS, startTime time.Time) int {
+	if m.opts.DownloadOnly {
+		logger.Logger.Infof("开始下载仓库，当前并发数:%d", m.opts.Concurrency)
 	} else {
-		logger.Logger.Infof("开始迁移仓库，当前并发数:%d", Concurrency)
+		logger.Logger.Infof("开始迁移仓库，当前并发数:%d", m.opts.Concurrency)
 	}
-	sem := semaphore.NewWeighted(int64(Concurrency))
+	sem := semaphore.NewWeighted(int64(m.opts.Concurrency))
 	var wg sync.WaitGroup
 
 	for _, depot := range depotList {
@@ -477,23 +603,40 @@ func executeMigration(depotList []vcs.VCS, startTime time.Time) int {
 				panic(err)
 			}
 			defer sem.Release(1)
-			if err := migrateDo(depot); err != nil {
-				logger.Logger.Errorf("%s 仓库%s失败: %s", depot.GetRepoPath(), getOperationType(), err)
+			repoPath := depot.GetRepoPath()
+			logger.OpenRepo(repoPath, m.repoDir(repoPath))
+			defer logger.CloseRepo(repoPath)
+			result := &report.Repo{Source: repoPath}
+			if !m.opts.DownloadOnly {
+				m.recordTarget(result, depot)
 			}
+			repoStartTime := time.Now()
+			err := m.migrateDo(depot, result)
+			result.Duration = time.Since(repoStartTime)
+			if err != nil {
+				logger.Repo(repoPath).Errorf("%s 仓库%s失败: %s", repoPath, m.getOperationType(), err)
+				result.Status = report.StatusFailed
+				result.Phase = logger.Phase(repoPath)
+				result.Message = redact.Sanitize(err.Error())
+				result.Reason = report.Categorize(result.Message, m.repoDir(repoPath), repoPath, result.Target)
+			}
+			m.addResult(*result, depot)
 		}(depotCopy)
 	}
 
 	wg.Wait()
 	duration := formatDuration(time.Since(startTime))
-	if DownloadOnly {
+	if m.opts.DownloadOnly {
 		logger.Logger.Infof("代码仓库下载完成，耗时%s。\n【仓库总数】%d【成功下载】%d【忽略下载】%d【下载失败】%d",
-			duration, totalRepoNumber, successfulRepoNumber, skipRepoNumber, failedRepoNumber)
+			duration, m.totalRepoNumber, m.successfulRepoNumber, m.skipRepoNumber, m.failedRepoNumber)
 	} else {
 		logger.Logger.Infof("代码仓库迁移完成，耗时%s。\n【仓库总数】%d【成功迁移】%d【忽略迁移】%d【迁移失败】%d",
-			duration, totalRepoNumber, successfulRepoNumber, skipRepoNumber, failedRepoNumber)
+			duration, m.totalRepoNumber, m.successfulRepoNumber, m.skipRepoNumber, m.failedRepoNumber)
 	}
+	m.writeReport(startTime)
+	m.writeState()
 	// 检查是否有忽略迁移或迁移失败的仓库
-	if skipRepoNumber > 0 || failedRepoNumber > 0 {
+	if m.skipRepoNumber > 0 || m.failedRepoNumber > 0 {
 		logger.Logger.Errorf("存在忽略迁移或迁移失败的仓库，请检查ERROR级别日志查看详情")
 		return 1
 	}
@@ -501,112 +644,227 @@ func executeMigration(depotList []vcs.VCS, startTime time.Time) int {
 }
 
 // getOperationType 根据当前模式返回操作类型
-func getOperationType() string {
-	if DownloadOnly {
+func (m *Migrator) getOperationType() string {
+	if m.opts.DownloadOnly {
 		return "下载"
 	}
 	return "迁移"
 }
 
-func migrateDo(depot vcs.VCS) error {
+// addResult 记录仓库迁移结果及仓库快照
+func (m *Migrator) addResult(result report.Repo, depot vcs.VCS) {
+	snapshot, err := vcs.NewSnapshot(m.opts.SourcePlatform, depot)
+	if err != nil {
+		logger.Repo(result.Source).Warnf("%s", err)
+	}
+	m.resultsMu.Lock()
+	defer m.resultsMu.Unlock()
+	m.results = append(m.results, result)
+	if snapshot != nil {
+		if m.snapshots == nil {
+			m.snapshots = make(map[string]*vcs.Snapshot)
+		}
+		m.snapshots[result.Source] = snapshot
+	}
+}
+
+// markSucceeded 记录迁移成功的仓库
+func (m *Migrator) markSucceeded(result *report.Repo) {
+	atomic.AddInt64(&m.successfulRepoNumber, 1)
+	atomic.AddInt64(&m.failedRepoNumber, -1)
+	result.Status = report.StatusSuccess
+}
+
+// markSkipped 记录忽略迁移的仓库及原因
+func (m *Migrator) markSkipped(result *report.Repo, message string) {
+	atomic.AddInt64(&m.skipRepoNumber, 1)
+	atomic.AddInt64(&m.failedRepoNumber, -1)
+	result.Status = report.StatusSkipped
+	result.Message = message
+}
+
+// collectResults 返回所有仓库的迁移结果，包括源平台未找到的仓库
+func (m *Migrator) collectResults() []report.Repo {
+	m.resultsMu.Lock()
+	results := append([]report.Repo(nil), m.results...)
+	m.resultsMu.Unlock()
+	for _, repoPath := range m.notFoundRepos {
+		results = append(results, report.Repo{
+			Source:  repoPath,
+			Status:  report.StatusFailed,
+			Phase:   logger.PhaseCheck,
+			Reason:  report.ReasonNotFound,
+			Message: "配置的仓库在源平台未找到",
+		})
+	}
+	return results
+}
+
+// writeReport 将每个仓库的迁移结果写入 successful.log 所在目录
+func (m *Migrator) writeReport(startTime time.Time) {
+	if len(m.opts.Report) == 0 {
+		return
+	}
+	results := m.collectResults()
+	r := report.New(report.Report{
+		SourcePlatform: m.opts.SourcePlatform,
+		SourceURL:      m.opts.SourceURL,
+		CnbURL:         m.opts.CnbURL,
+		DownloadOnly:   m.opts.DownloadOnly,
+		StartedAt:      startTime,
+		FinishedAt:     time.Now(),
+		Repos:          results,
+	})
+	if m.opts.DownloadOnly {
+		r.CnbURL = ""
+	}
+	files, err := r.WriteFiles(m.reportDir, m.opts.Report)
+	if err != nil {
+		logger.Logger.Errorf("%s", err)
+	}
+	for _, file := range files {
+		logger.Logger.Infof("迁移报告已写入 %s", file)
+	}
+}
+
+// writeState 写入迁移状态文件，retry-failed 时与上次的状态合并
+func (m *Migrator) writeState() {
+	state := &State{
+		SourcePlatform: m.opts.SourcePlatform,
+		SourceURL:      m.opts.SourceURL,
+		FinishedAt:     time.Now(),
+	}
+	results := m.collectResults()
+	m.resultsMu.Lock()
+	for _, result := range results {
+		state.Repos = append(state.Repos, StateRepo{Result: result, Repo: m.snapshots[result.Source]})
+	}
+	m.resultsMu.Unlock()
+	if m.previousState != nil {
+		state = m.previousState.Merge(state)
+	}
+	if err := state.Write(m.StateFilePath()); err != nil {
+		logger.Logger.Errorf("%s", err)
+	}
+}
+
+func (m *Migrator) migrateDo(depot vcs.VCS, result *report.Repo) error {
 	var err error
-	repoName, subGroup, repoPath, repoPrivate := depot.GetRepoName(), depot.GetSubGroup(), depot.GetRepoPath(), depot.GetRepoPrivate()
-	subGroupName := subGroup.Name
+	repoPath, repoPrivate := depot.GetRepoPath(), depot.GetRepoPrivate()
+	dir := m.repoDir(repoPath)
 
 	// 如果不是只下载模式，则检查是否已迁移
-	if !DownloadOnly {
-		err, migrated := isMigrated(repoPath, logger.SuccessfulLogFilePath)
+	logger.SetPhase(repoPath, logger.PhaseCheck)
+	if !m.opts.DownloadOnly {
+		err, migrated := isMigrated(repoPath, m.successfulLogPath())
 		if err != nil {
-			logger.Logger.Errorf("判断是否迁移失败: %s", err)
+			logger.Repo(repoPath).Errorf("判断是否迁移失败: %s", err)
 			return fmt.Errorf("%s 判断是否迁移失败%s", repoPath, err)
 		}
 		if migrated {
-			atomic.AddInt64(&skipRepoNumber, 1)
-			atomic.AddInt64(&failedRepoNumber, -1)
-			logger.Logger.Infof("%s 已迁移，忽略迁移", repoPath)
+			m.markSkipped(result, "已迁移")
+			logger.Repo(repoPath).Infof("%s 已迁移，忽略迁移", repoPath)
 			return nil
 		}
 	}
 
-	logger.Logger.Infof("%s 开始迁移", repoPath)
+	logger.Repo(repoPath).Infof("%s 开始迁移", repoPath)
 	startTime := time.Now()
 	isSvn := git.IsSvnRepo(depot.GetRepoType())
 	if isSvn {
-		atomic.AddInt64(&skipRepoNumber, 1)
-		atomic.AddInt64(&failedRepoNumber, -1)
-		logger.Logger.Errorf("%s svn仓库，忽略迁移", repoPath)
+		m.markSkipped(result, "svn仓库")
+		logger.Repo(repoPath).Errorf("%s svn仓库，忽略迁移", repoPath)
 		return nil
 	}
 	// 执行 clone 操作
-	err = depot.Clone()
+	logger.SetPhase(repoPath, logger.PhaseClone)
+	err = depot.Clone(dir, m.gitEnv)
 	if err != nil {
-		logger.Logger.Errorf(err.Error())
+		logger.Repo(repoPath).Errorf(err.Error())
 		return fmt.Errorf(err.Error())
 	}
+	result.Bytes, _ = util.DirSize(dir)
+	result.LFSObjects = git.CountLFSObjects(dir)
+	// 删除镜像克隆附带的平台专有引用，本地仓库为用户原始仓库，不做修改
+	if m.opts.DropPlatformRefs && m.opts.SourcePlatform != "local" {
+		if _, err = git.DropPlatformRefs(dir); err != nil {
+			return err
+		}
+	}
 	// 如果是只下载模式，则直接返回
-	if DownloadOnly {
-		atomic.AddInt64(&successfulRepoNumber, 1)
-		atomic.AddInt64(&failedRepoNumber, -1)
+	if m.opts.DownloadOnly {
+		m.markSucceeded(result)
 		duration := formatDuration(time.Since(startTime))
-		logger.Logger.Infof("%s 下载完成，耗时%s", repoPath, duration)
+		logger.Repo(repoPath).Infof("%s 下载完成，耗时%s", repoPath, duration)
 		return nil
 	}
 	// 以下是原有的迁移逻辑
-	cnbRepoPath, cnbRepoGroup := target.GetCnbRepoPathAndGroup(subGroupName, repoName, organizationMappingLevel)
-	if MigrateCode {
-		has, err := target.HasRepoV2(CnbApiURL, CnbToken, cnbRepoPath)
+	cnbRepoPath, cnbRepoGroup := m.cnbRepoPathAndGroup(depot)
+	cnbRepo := m.targetOf(depot)
+	if m.opts.MigrateCode {
+		// 先登记删除镜像克隆，忽略迁移或出错返回时同样清理，避免批量迁移时克隆堆积在工作目录
+		if m.opts.SourcePlatform != "local" {
+			defer func(path string) {
+				err := os.RemoveAll(path)
+				if err != nil {
+					logger.Repo(repoPath).Errorf("%s 删除失败: %s", path, err)
+				}
+			}(dir)
+		}
+		has, err := m.target.HasRepoV2(cnbRepoPath)
 		if err != nil {
 			return err
 		}
+		if has && m.opts.SkipExistsRepo {
+			m.markSkipped(result, "CNB仓库已存在")
+			logger.Repo(repoPath).Warnf("%s CNB仓库%s已存在，忽略迁移", repoPath, cnbRepoPath)
+			return nil
+		}
+		// 推送前扫描历史提交中的大文件，在创建CNB仓库前按策略处理，未初始化的仓库没有提交，不需要扫描
+		initialized := git.IsBareRepoInitialized(dir)
+		if initialized {
+			logger.SetPhase(repoPath, logger.PhaseLargeFile)
+			skip, err := m.handleLargeFiles(repoPath)
+			if err != nil {
+				return err
+			}
+			if skip {
+				m.markSkipped(result, "历史提交中存在超过大小限制的文件，按大文件策略忽略迁移")
+				return nil
+			}
+		}
+		logger.SetPhase(repoPath, logger.PhaseCreate)
 		if !has {
-			err = target.CreateRepo(CnbApiURL, CnbToken, cnbRepoGroup, repoName, depot.GetRepoDescription(), repoPrivate)
+			err = m.target.CreateRepo(cnbRepoGroup, cnbRepo.Name, depot.GetRepoDescription(), repoPrivate)
 			if err != nil {
 				return fmt.Errorf("%s 仓库创建失败: %s", repoPath, err)
 			}
-			logger.Logger.Infof("%s 仓库创建成功", repoPath)
+			logger.Repo(repoPath).Infof("%s 仓库创建成功", repoPath)
 			time.Sleep(1000 * time.Millisecond) // 添加0.5秒延迟，避免push操作太快导致报错找不到仓库
-		} else if has && SkipExistsRepo {
-			atomic.AddInt64(&skipRepoNumber, 1)
-			atomic.AddInt64(&failedRepoNumber, -1)
-			logger.Logger.Warnf("%s CNB仓库%s已存在，忽略迁移", repoPath, cnbRepoPath)
-			return nil
 		}
 		// 检查源仓库是否初始化
-		if !git.IsBareRepoInitialized(repoPath) {
-			atomic.AddInt64(&successfulRepoNumber, 1)
-			atomic.AddInt64(&failedRepoNumber, -1)
-			logger.Logger.Infof("%s 源仓库未初始化", repoPath)
+		if !initialized {
+			m.markSucceeded(result)
+			logger.Repo(repoPath).Infof("%s 源仓库未初始化", repoPath)
 			return nil
 		}
-		// 设置要进入的目录路径
-		pwdDir, err := os.Getwd()
-		if err != nil {
-			return err
-		}
-		// 完整的仓库目录路径
-		fullRepoDir := filepath.Join(pwdDir, repoPath)
-		if SourcePlatformName != "local" {
-			defer func(path string) {
-				err := os.RemoveAll(path)
-				if err != nil {
-					logger.Logger.Errorf("%s 删除失败: %s", path, err)
-				}
-			}(fullRepoDir)
+		pushURL := m.target.GetPushUrl(targetMappingLevel, CnbUserName, cnbRepo.SubGroup, cnbRepo.Name)
+		if m.opts.CnbSSH {
+			pushURL = m.target.GetSSHPushUrl(targetMappingLevel, cnbRepo.SubGroup, cnbRepo.Name)
 		}
-
-		pushURL := target.GetPushUrl(organizationMappingLevel, CnbURL, CnbUserName, CnbToken, subGroupName, repoName)
-		rebaseRepoPath := filepath.Join(RebaseDirPrefix, repoPath)
-		isForcePush := config.Cfg.GetBool("migrate.force_push")
+		rebaseRepoPath := filepath.Join(m.workDir, RebaseDirPrefix, repoPath)
+		isForcePush := m.opts.ForcePush
 
 		// 处理 rebase 逻辑：明确分为两种情况
-		if MigrateRebase {
+		if m.opts.Rebase {
+			logger.SetPhase(repoPath, logger.PhaseRebase)
 			// 情况1：当CNB侧不存在对应仓库时，跳过rebase操作，直接执行原有的迁移push流程
 			if !has {
-				logger.Logger.Infof("%s CNB侧仓库不存在，跳过rebase操作，直接执行迁移push流程", repoPath)
+				logger.Repo(repoPath).Infof("%s CNB侧仓库不存在，跳过rebase操作，直接执行迁移push流程", repoPath)
 			} else {
 				// CNB侧仓库存在，尝试克隆进行rebase
 				isForcePush = true // 如果使用rebase同步，那么需要开启强制 push，避免出现冲突
-				cloneOutput, rebaseCloneErr := git.NormalCloneWithOutput(pushURL, rebaseRepoPath)
+				cloneOutput, rebaseCloneErr := git.NormalCloneWithOutput(pushURL, rebaseRepoPath, m.gitEnv)
 
 				if rebaseCloneErr != nil {
 					return fmt.Errorf("git rebase clone失败: %s", rebaseCloneErr)
@@ -614,34 +872,36 @@ func migrateDo(depot vcs.VCS) error {
 
 				// 情况2：当遇到未初始化的空仓库时，同样跳过rebase操作，执行原有的迁移push流程
 				if git.IsEmptyRepositoryOutput(cloneOutput) {
-					logger.Logger.Infof("%s CNB侧仓库为空仓库，跳过rebase操作，执行原有的迁移push流程", repoPath)
+					logger.Repo(repoPath).Infof("%s CNB侧仓库为空仓库，跳过rebase操作，执行原有的迁移push流程", repoPath)
 				} else {
 					// 成功克隆非空仓库，执行正常的rebase流程
-					destPath := filepath.Join(rebaseBackDirPath, repoPath)
+					destPath := filepath.Join(m.rebaseBackDirPath, repoPath)
 					//备份CNB侧仓库
 					err = util.CopyDir(rebaseRepoPath, destPath)
 					if err != nil {
-						logger.Logger.Errorf("备份仓库失败: %v", err)
+						logger.Repo(repoPath).Errorf("备份仓库失败: %v", err)
 						return fmt.Errorf("备份仓库失败: %w", err)
 					}
-					logger.Logger.Infof("%s 已备份仓库到 %s", repoPath, destPath)
-					rebaseErr := git.Rebase(rebaseRepoPath, depot.GetRepoPath())
+					logger.Repo(repoPath).Infof("%s 已备份仓库到 %s", repoPath, destPath)
+					rebaseErr := git.Rebase(rebaseRepoPath, dir, m.opts.RefFilter)
 					if rebaseErr != nil {
 						return rebaseErr
 					}
-					logger.Logger.Infof("%s rebase操作完成", repoPath)
+					logger.Repo(repoPath).Infof("%s rebase操作完成", repoPath)
 				}
 			}
 		}
 
-		output, err := git.Push(repoPath, pushURL, isForcePush)
-		if err != nil && useLfsMigrate && git.IsExceededLimitError(output) {
-			logger.Logger.Warnf("%s 历史提交文件大小超过%sM", repoPath, git.FileLimitSize)
-			fixError := git.FixExceededLimitError(repoPath)
+		logger.SetPhase(repoPath, logger.PhasePush)
+		output, err := m.pushRepo(repoPath, pushURL, isForcePush)
+		if err != nil && m.opts.UseLfsMigrate && git.IsExceededLimitError(output) {
+			fileLimitSize := strconv.FormatInt(m.opts.FileLimitSize, 10)
+			logger.Repo(repoPath).Warnf("%s 历史提交文件大小超过%sM", repoPath, fileLimitSize)
+			fixError := git.FixExceededLimitError(dir, fileLimitSize)
 			if fixError != nil {
 				return fmt.Errorf("%s 修复大文件超过限制: %s", repoPath, fixError)
 			}
-			output, err = git.Push(repoPath, pushURL, isForcePush)
+			output, err = m.pushRepo(repoPath, pushURL, isForcePush)
 			if err != nil {
 				return fmt.Errorf("%s push失败: %s\n %s", repoPath, err, output)
 			}
@@ -649,23 +909,34 @@ func migrateDo(depot vcs.VCS) error {
 		if err != nil {
 			return fmt.Errorf("%s push失败: %s\n %s", repoPath, err, output)
 		}
+		logger.SetPhase(repoPath, logger.PhasePrune)
+		if err = m.pruneRefs(repoPath, pushURL); err != nil {
+			return err
+		}
+		logger.SetPhase(repoPath, logger.PhaseSubmodule)
+		if err = m.rewriteSubmodules(repoPath, pushURL); err != nil {
+			return err
+		}
 	}
-	if MigrateRelease {
-		err = migrateRelease(depot, cnbRepoPath)
+	if m.opts.MigrateRelease {
+		logger.SetPhase(repoPath, logger.PhaseRelease)
+		result.Releases, err = m.migrateRelease(depot, cnbRepoPath)
 		if err != nil {
 			return err
 		}
 	}
-	atomic.AddInt64(&successfulRepoNumber, 1)
-	atomic.AddInt64(&failedRepoNumber, -1)
+	m.markSucceeded(result)
 	duration := formatDuration(time.Since(startTime))
-	logger.Logger.Infof("%s 迁移至CNB %s 成功,耗时%s", repoPath, cnbRepoPath, duration)
-	logger.RecordSuccessfulRepo(repoPath)
+	logger.Repo(repoPath).Infof("%s 迁移至CNB %s 成功,耗时%s", repoPath, cnbRepoPath, duration)
+	m.recordSuccessfulRepo(repoPath)
 	return nil
 }
 
 func isMigrated(repoPath, filePath string) (error, bool) {
 	content, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, false
+	}
 	if err != nil {
 		return err, false
 	}
@@ -684,44 +955,49 @@ func isMigrated(repoPath, filePath string) (error, bool) {
 //
 // 返回:
 //   - error: 迁移过程中的错误信息
-func migrateRelease(depot vcs.VCS, targetRepoPath string) error {
-	if SourcePlatformName == "common" {
-		return nil
+func (m *Migrator) migrateRelease(depot vcs.VCS, targetRepoPath string) (int, error) {
+	if m.opts.SourcePlatform == "common" {
+		return 0, nil
 	}
 	releases := depot.GetReleases()
 	if releases == nil || 0 == len(releases) {
-		logger.Logger.Infof("%s 无release需要迁移", depot.GetRepoPath())
-		return nil
+		logger.Repo(depot.GetRepoPath()).Infof("%s 无release需要迁移", depot.GetRepoPath())
+		return 0, nil
 	}
 	sourceRepoPath := depot.GetRepoPath()
 	normalizedTargetRepoPath := strings.Trim(strings.TrimSpace(targetRepoPath), "/")
-	releaseTag := strings.TrimSpace(config.Cfg.GetString("migrate.release_tag"))
+	releaseTag := m.opts.ReleaseTag
 
-	logger.Logger.Infof("%s 开始迁移 release", sourceRepoPath)
+	logger.Repo(sourceRepoPath).Infof("%s 开始迁移 release", sourceRepoPath)
 
 	selectedReleases, err := filterReleasesByTagOrLatest(releases, releaseTag)
 	if err != nil {
-		return fmt.Errorf("%s release筛选失败: %w", sourceRepoPath, err)
+		return 0, fmt.Errorf("%s release筛选失败: %w", sourceRepoPath, err)
 	}
 	if len(selectedReleases) == 0 {
-		logger.Logger.Infof("%s 无release需要迁移", sourceRepoPath)
-		return nil
+		logger.Repo(sourceRepoPath).Infof("%s 无release需要迁移", sourceRepoPath)
+		return 0, nil
 	}
 	if releaseTag == "" {
-		logger.Logger.Infof("%s 未指定 release tag，仅同步最新release: %s", sourceRepoPath, selectedReleases[0].TagName)
+		logger.Repo(sourceRepoPath).Infof("%s 未指定 release tag，仅同步最新release: %s", sourceRepoPath, selectedReleases[0].TagName)
 	} else {
-		logger.Logger.Infof("%s 已指定 release tag，仅同步: %s", sourceRepoPath, selectedReleases[0].TagName)
+		logger.Repo(sourceRepoPath).Infof("%s 已指定 release tag，仅同步: %s", sourceRepoPath, selectedReleases[0].TagName)
 	}
 
 	// 遍历处理每个release
+	migrated := 0
 	for _, release := range selectedReleases {
-		if err := migrateOneRelease(depot, release, sourceRepoPath, normalizedTargetRepoPath); err != nil {
-			return err
+		created, err := m.migrateOneRelease(depot, release, sourceRepoPath, normalizedTargetRepoPath)
+		if err != nil {
+			return migrated, err
+		}
+		if created {
+			migrated++
 		}
 	}
 
-	logger.Logger.Infof("%s 迁移 release 成功", sourceRepoPath)
-	return nil
+	logger.Repo(sourceRepoPath).Infof("%s 迁移 release 成功", sourceRepoPath)
+	return migrated, nil
 }
 
 func filterReleasesByTagOrLatest(releases []vcs.Releases, releaseTag string) ([]vcs.Releases, error) {
@@ -752,32 +1028,33 @@ func filterReleasesByTagOrLatest(releases []vcs.Releases, releaseTag string) ([]
 //   - repoPath: 仓库路径
 //
 // 返回:
+//   - bool: 是否新建了release，release已存在时为false
 //   - error: 迁移过程中的错误信息
-func migrateOneRelease(depot vcs.VCS, release vcs.Releases, sourceRepoPath, targetRepoPath string) error {
-	logger.Logger.Infof("%s 开始迁移release: %s", sourceRepoPath, release.Name)
+func (m *Migrator) migrateOneRelease(depot vcs.VCS, release vcs.Releases, sourceRepoPath, targetRepoPath string) (bool, error) {
+	logger.Repo(sourceRepoPath).Infof("%s 开始迁移release: %s", sourceRepoPath, release.Name)
 
 	// 在目标平台创建release
-	releaseID, exist, err := target.CreateRelease(targetRepoPath, sourceRepoPath, depot.GetProjectID(), release, depot)
+	releaseID, exist, err := m.target.CreateRelease(targetRepoPath, sourceRepoPath, depot.GetProjectID(), release, depot)
 	if err != nil {
-		logger.Logger.Errorf("%s 迁移 release %s 失败: %s", sourceRepoPath, release.Name, err)
-		return err
+		logger.Repo(sourceRepoPath).Errorf("%s 迁移 release %s 失败: %s", sourceRepoPath, release.Name, err)
+		return false, err
 	}
 
 	// 如果release已存在则跳过
 	if exist {
-		logger.Logger.Warnf("%s 迁移release: %s 已存在，忽略迁移", sourceRepoPath, release.Name)
-		return nil
+		logger.Repo(sourceRepoPath).Warnf("%s 迁移release: %s 已存在，忽略迁移", sourceRepoPath, release.Name)
+		return false, nil
 	}
 
 	// 处理release附带的资源文件
 	if len(release.Assets) > 0 {
-		if err := migrateReleaseAssets(sourceRepoPath, targetRepoPath, releaseID, release); err != nil {
-			return err
+		if err := m.migrateReleaseAssets(sourceRepoPath, targetRepoPath, releaseID, release); err != nil {
+			return false, err
 		}
 	}
 
-	logger.Logger.Infof("%s 迁移 release %s 成功", sourceRepoPath, release.Name)
-	return nil
+	logger.Repo(sourceRepoPath).Infof("%s 迁移 release %s 成功", sourceRepoPath, release.Name)
+	return true, nil
 }
 
 // migrateReleaseAssets 处理release相关的资源文件迁移
@@ -788,12 +1065,12 @@ func migrateOneRelease(depot vcs.VCS, release vcs.Releases, sourceRepoPath, targ
 //
 // 返回:
 //   - error: 迁移过程中的错误信息
-func migrateReleaseAssets(sourceRepoPath, targetRepoPath, releaseID string, release vcs.Releases) error {
+func (m *Migrator) migrateReleaseAssets(sourceRepoPath, targetRepoPath, releaseID string, release vcs.Releases) error {
 	// 遍历处理每个资源文件
 	for _, asset := range release.Assets {
 
-		if err := migrateReleaseAsset(targetRepoPath, releaseID, asset.Name, asset.Url); err != nil {
-			logger.Logger.Errorf("%s 迁移 release %s asset %s 失败: %s",
+		if err := m.migrateReleaseAsset(targetRepoPath, releaseID, asset); err != nil {
+			logger.Repo(sourceRepoPath).Errorf("%s 迁移 release %s asset %s 失败: %s",
 				sourceRepoPath, release.Name, asset.Name, err)
 			return err
 		}
@@ -801,13 +1078,19 @@ func migrateReleaseAssets(sourceRepoPath, targetRepoPath, releaseID string, rele
 	return nil
 }
 
-func migrateReleaseAsset(repoPath, releaseID, fileName, downloadUrl string) (err error) {
-	data, err := http_client.DownloadFromUrl(downloadUrl)
+func (m *Migrator) migrateReleaseAsset(repoPath, releaseID string, asset vcs.Asset) (err error) {
+	fileName, downloadUrl := asset.Name, asset.Url
+	var data []byte
+	if asset.Download != nil {
+		data, err = asset.Download()
+	} else {
+		data, err = http_client.DownloadFromUrl(downloadUrl)
+	}
 	if err != nil {
 		logger.Logger.Errorf("%s 下载release asset %s 失败: %s", downloadUrl, fileName, err)
 		return err
 	}
-	err = target.UploadReleaseAsset(repoPath, releaseID, fileName, data)
+	err = m.target.UploadReleaseAsset(repoPath, releaseID, fileName, data)
 	if err != nil {
 		logger.Logger.Errorf("%s 上传release asset %s 失败: %s", downloadUrl, fileName, err)
 		return err
@@ -828,13 +1111,21 @@ func GetRepoList(source vcs.VCS) ([]string, error) {
 	}
 
 	// 将仓库列表写入文件
-	if err := os.WriteFile(RepoPathFile, []byte(strings.Join(repoPaths, "\n")), 0644); err != nil {
-		return nil, fmt.Errorf("写入仓库列表文件失败: %v", err)
+	if err := WriteSelectedRepos(repoPaths); err != nil {
+		return nil, err
 	}
 
 	return repoPaths, nil
 }
 
+// WriteSelectedRepos 将仓库列表写入 repo-path.txt，每行一个仓库路径
+func WriteSelectedRepos(repoPaths []string) error {
+	if err := os.WriteFile(RepoPathFile, []byte(strings.Join(repoPaths, "\n")), 0644); err != nil {
+		return fmt.Errorf("写入仓库列表文件失败: %v", err)
+	}
+	return nil
+}
+
 // ReadSelectedRepos 读取用户选择的仓库列表
 func ReadSelectedRepos() (map[string]bool, error) {
 	content, err := os.ReadFile(RepoPathFile)
@@ -1,11 +1,10 @@
 package migrate
 
 import (
-	"ccrctl/pkg/config"
+	"ccrctl/pkg/picker"
 	"ccrctl/pkg/vcs"
+	"reflect"
 	"testing"
-
-	"github.com/spf13/viper"
 )
 
 // MockVCS 模拟 VCS 接口用于测试
@@ -13,42 +12,35 @@ type MockVCS struct {
 	repoPath string
 	repoName string
 	subGroup *vcs.SubGroup
+	metadata vcs.Metadata
 }
 
-func (m *MockVCS) GetRepoPath() string           { return m.repoPath }
-func (m *MockVCS) GetRepoName() string           { return m.repoName }
-func (m *MockVCS) GetSubGroup() *vcs.SubGroup    { return m.subGroup }
-func (m *MockVCS) GetRepoType() string           { return "git" }
-func (m *MockVCS) GetCloneUrl() string           { return "" }
-func (m *MockVCS) GetUserName() string           { return "" }
-func (m *MockVCS) GetToken() string              { return "" }
-func (m *MockVCS) Clone() error                  { return nil }
-func (m *MockVCS) GetRepoPrivate() bool          { return false }
-func (m *MockVCS) GetReleases() []vcs.Releases   { return nil }
-func (m *MockVCS) GetProjectID() string          { return "" }
-func (m *MockVCS) GetRepoDescription() string    { return "" }
-func (m *MockVCS) ListRepos() ([]vcs.VCS, error) { return nil, nil }
+func (m *MockVCS) GetRepoPath() string                  { return m.repoPath }
+func (m *MockVCS) GetRepoName() string                  { return m.repoName }
+func (m *MockVCS) GetSubGroup() *vcs.SubGroup           { return m.subGroup }
+func (m *MockVCS) GetRepoType() string                  { return "git" }
+func (m *MockVCS) GetCloneUrl() string                  { return "" }
+func (m *MockVCS) GetUserName() string                  { return "" }
+func (m *MockVCS) GetToken() string                     { return "" }
+func (m *MockVCS) Clone(dir string, env []string) error { return nil }
+func (m *MockVCS) GetRepoPrivate() bool                 { return false }
+func (m *MockVCS) GetReleases() []vcs.Releases          { return nil }
+func (m *MockVCS) GetProjectID() string                 { return "" }
+func (m *MockVCS) GetRepoDescription() string           { return "" }
+func (m *MockVCS) ListRepos() ([]vcs.VCS, error)        { return nil, nil }
+func (m *MockVCS) GetMetadata() vcs.Metadata            { return m.metadata }
 func (m *MockVCS) GetReleaseAttachments(desc string, repoPath string, projectID string) ([]vcs.Attachment, error) {
 	return nil, nil
 }
 
-// setupTestConfig 设置测试用的配置
-func setupTestConfig(sourceRepo []string) *viper.Viper {
-	cfg := viper.New()
-	if len(sourceRepo) > 0 {
-		cfg.Set("source.repo", sourceRepo)
-	}
-	return cfg
+// newTestMigrator 创建测试用的迁移器
+func newTestMigrator(sourceRepo []string) *Migrator {
+	return NewMigrator(Options{SourceRepos: nonEmptyStrings(sourceRepo)})
 }
 
 // TestFilterReposByConfigList_EmptyConfig 测试配置为空的情况
 func TestFilterReposByConfigList_EmptyConfig(t *testing.T) {
-	// 保存原有配置
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	// 设置空配置
-	config.Cfg = setupTestConfig(nil)
+	m := newTestMigrator(nil)
 
 	depotList := []vcs.VCS{
 		&MockVCS{repoPath: "org1/project1/repo1"},
@@ -56,7 +48,7 @@ func TestFilterReposByConfigList_EmptyConfig(t *testing.T) {
 		&MockVCS{repoPath: "org2/project2/repo3"},
 	}
 
-	result, notFoundCount := filterReposByConfigList(depotList)
+	result, notFoundCount := m.filterReposByConfigList(depotList)
 
 	if len(result) != 3 {
 		t.Errorf("空配置应返回完整列表，期望 3 个仓库，实际 %d 个", len(result))
@@ -68,10 +60,7 @@ func TestFilterReposByConfigList_EmptyConfig(t *testing.T) {
 
 // TestFilterReposByConfigList_SingleRepo 测试单个仓库过滤
 func TestFilterReposByConfigList_SingleRepo(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{"org1/project1/repo1"})
+	m := newTestMigrator([]string{"org1/project1/repo1"})
 
 	depotList := []vcs.VCS{
 		&MockVCS{repoPath: "org1/project1/repo1"},
@@ -79,7 +68,7 @@ func TestFilterReposByConfigList_SingleRepo(t *testing.T) {
 		&MockVCS{repoPath: "org2/project2/repo3"},
 	}
 
-	result, notFoundCount := filterReposByConfigList(depotList)
+	result, notFoundCount := m.filterReposByConfigList(depotList)
 
 	if len(result) != 1 {
 		t.Errorf("应该只返回1个仓库，实际 %d 个", len(result))
@@ -94,10 +83,7 @@ func TestFilterReposByConfigList_SingleRepo(t *testing.T) {
 
 // TestFilterReposByConfigList_MultipleRepos 测试多个仓库过滤
 func TestFilterReposByConfigList_MultipleRepos(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{
+	m := newTestMigrator([]string{
 		"org1/project1/repo1",
 		"org2/project2/repo3",
 	})
@@ -109,7 +95,7 @@ func TestFilterReposByConfigList_MultipleRepos(t *testing.T) {
 		&MockVCS{repoPath: "org3/project3/repo4"},
 	}
 
-	result, notFoundCount := filterReposByConfigList(depotList)
+	result, notFoundCount := m.filterReposByConfigList(depotList)
 
 	if len(result) != 2 {
 		t.Errorf("应该返回2个仓库，实际 %d 个", len(result))
@@ -136,10 +122,7 @@ func TestFilterReposByConfigList_MultipleRepos(t *testing.T) {
 
 // TestFilterReposByConfigList_WithWhitespace 测试带空格的配置
 func TestFilterReposByConfigList_WithWhitespace(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{
+	m := newTestMigrator([]string{
 		" org1/project1/repo1 ",
 		"  org2/project2/repo3  ",
 	})
@@ -150,7 +133,7 @@ func TestFilterReposByConfigList_WithWhitespace(t *testing.T) {
 		&MockVCS{repoPath: "org2/project2/repo3"},
 	}
 
-	result, _ := filterReposByConfigList(depotList)
+	result, _ := m.filterReposByConfigList(depotList)
 
 	if len(result) != 2 {
 		t.Errorf("应该正确处理带空格的配置，期望 2 个仓库，实际 %d 个", len(result))
@@ -159,17 +142,14 @@ func TestFilterReposByConfigList_WithWhitespace(t *testing.T) {
 
 // TestFilterReposByConfigList_EmptyStrings 测试包含空字符串的配置
 func TestFilterReposByConfigList_EmptyStrings(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{""})
+	m := newTestMigrator([]string{""})
 
 	depotList := []vcs.VCS{
 		&MockVCS{repoPath: "org1/project1/repo1"},
 		&MockVCS{repoPath: "org1/project1/repo2"},
 	}
 
-	result, _ := filterReposByConfigList(depotList)
+	result, _ := m.filterReposByConfigList(depotList)
 
 	if len(result) != 2 {
 		t.Errorf("只包含空字符串的配置应返回完整列表，期望 2 个仓库，实际 %d 个", len(result))
@@ -178,17 +158,14 @@ func TestFilterReposByConfigList_EmptyStrings(t *testing.T) {
 
 // TestFilterReposByConfigList_NoMatch 测试没有匹配的仓库
 func TestFilterReposByConfigList_NoMatch(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{"org99/project99/repo99"})
+	m := newTestMigrator([]string{"org99/project99/repo99"})
 
 	depotList := []vcs.VCS{
 		&MockVCS{repoPath: "org1/project1/repo1"},
 		&MockVCS{repoPath: "org1/project1/repo2"},
 	}
 
-	result, _ := filterReposByConfigList(depotList)
+	result, _ := m.filterReposByConfigList(depotList)
 
 	if len(result) != 0 {
 		t.Errorf("没有匹配的仓库应返回空列表，期望 0 个仓库，实际 %d 个", len(result))
@@ -197,10 +174,7 @@ func TestFilterReposByConfigList_NoMatch(t *testing.T) {
 
 // TestFilterReposByConfigList_GitlabFormat 测试GitLab格式的仓库路径
 func TestFilterReposByConfigList_GitlabFormat(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{
+	m := newTestMigrator([]string{
 		"group1/subgroup1/repo1",
 		"group2/repo2",
 	})
@@ -212,7 +186,7 @@ func TestFilterReposByConfigList_GitlabFormat(t *testing.T) {
 		&MockVCS{repoPath: "group3/repo3"},
 	}
 
-	result, _ := filterReposByConfigList(depotList)
+	result, _ := m.filterReposByConfigList(depotList)
 
 	if len(result) != 2 {
 		t.Errorf("应该支持GitLab格式的仓库路径，期望 2 个仓库，实际 %d 个", len(result))
@@ -221,10 +195,7 @@ func TestFilterReposByConfigList_GitlabFormat(t *testing.T) {
 
 // TestFilterReposByConfigList_GithubFormat 测试GitHub格式的仓库路径
 func TestFilterReposByConfigList_GithubFormat(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{
+	m := newTestMigrator([]string{
 		"owner1/repo1",
 		"owner2/repo2",
 	})
@@ -235,7 +206,7 @@ func TestFilterReposByConfigList_GithubFormat(t *testing.T) {
 		&MockVCS{repoPath: "owner2/repo2"},
 	}
 
-	result, _ := filterReposByConfigList(depotList)
+	result, _ := m.filterReposByConfigList(depotList)
 
 	if len(result) != 2 {
 		t.Errorf("应该支持GitHub格式的仓库路径，期望 2 个仓库，实际 %d 个", len(result))
@@ -244,10 +215,7 @@ func TestFilterReposByConfigList_GithubFormat(t *testing.T) {
 
 // TestFilterReposByConfigList_GongfengFormat 测试工蜂格式的仓库路径
 func TestFilterReposByConfigList_GongfengFormat(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{
+	m := newTestMigrator([]string{
 		"tencent/team1/project1/repo1",
 		"tencent/team2/repo2",
 	})
@@ -258,7 +226,7 @@ func TestFilterReposByConfigList_GongfengFormat(t *testing.T) {
 		&MockVCS{repoPath: "tencent/team2/repo2"},
 	}
 
-	result, _ := filterReposByConfigList(depotList)
+	result, _ := m.filterReposByConfigList(depotList)
 
 	if len(result) != 2 {
 		t.Errorf("应该支持工蜂格式的仓库路径，期望 2 个仓库，实际 %d 个", len(result))
@@ -267,10 +235,7 @@ func TestFilterReposByConfigList_GongfengFormat(t *testing.T) {
 
 // TestFilterReposByConfigList_MixedFormats 测试混合格式的仓库路径
 func TestFilterReposByConfigList_MixedFormats(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{
+	m := newTestMigrator([]string{
 		"owner/repo",            // GitHub格式
 		"group/subgroup/repo",   // GitLab格式
 		"org/team/project/repo", // 工蜂格式
@@ -283,7 +248,7 @@ func TestFilterReposByConfigList_MixedFormats(t *testing.T) {
 		&MockVCS{repoPath: "other/repo"},
 	}
 
-	result, _ := filterReposByConfigList(depotList)
+	result, _ := m.filterReposByConfigList(depotList)
 
 	if len(result) != 3 {
 		t.Errorf("应该支持混合格式的仓库路径，期望 3 个仓库，实际 %d 个", len(result))
@@ -292,10 +257,7 @@ func TestFilterReposByConfigList_MixedFormats(t *testing.T) {
 
 // TestFilterReposByConfigList_ExactMatch 测试精确匹配
 func TestFilterReposByConfigList_ExactMatch(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{"org/project/repo"})
+	m := newTestMigrator([]string{"org/project/repo"})
 
 	depotList := []vcs.VCS{
 		&MockVCS{repoPath: "org/project/repo"},
@@ -304,7 +266,7 @@ func TestFilterReposByConfigList_ExactMatch(t *testing.T) {
 		&MockVCS{repoPath: "org2/project/repo"}, // 不应匹配
 	}
 
-	result, _ := filterReposByConfigList(depotList)
+	result, _ := m.filterReposByConfigList(depotList)
 
 	if len(result) != 1 {
 		t.Errorf("应该只精确匹配指定的仓库，期望 1 个仓库，实际 %d 个", len(result))
@@ -316,17 +278,14 @@ func TestFilterReposByConfigList_ExactMatch(t *testing.T) {
 
 // TestFilterReposByConfigList_CaseSensitive 测试大小写敏感
 func TestFilterReposByConfigList_CaseSensitive(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{"Org/Project/Repo"})
+	m := newTestMigrator([]string{"Org/Project/Repo"})
 
 	depotList := []vcs.VCS{
 		&MockVCS{repoPath: "Org/Project/Repo"},
 		&MockVCS{repoPath: "org/project/repo"}, // 大小写不同，不应匹配
 	}
 
-	result, _ := filterReposByConfigList(depotList)
+	result, _ := m.filterReposByConfigList(depotList)
 
 	if len(result) != 1 {
 		t.Errorf("应该区分大小写，期望 1 个仓库，实际 %d 个", len(result))
@@ -338,14 +297,11 @@ func TestFilterReposByConfigList_CaseSensitive(t *testing.T) {
 
 // TestFilterReposByConfigList_EmptyDepotList 测试空的仓库列表
 func TestFilterReposByConfigList_EmptyDepotList(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{"org/project/repo"})
+	m := newTestMigrator([]string{"org/project/repo"})
 
 	depotList := []vcs.VCS{}
 
-	result, _ := filterReposByConfigList(depotList)
+	result, _ := m.filterReposByConfigList(depotList)
 
 	if len(result) != 0 {
 		t.Errorf("空的仓库列表应返回空列表，期望 0 个仓库，实际 %d 个", len(result))
@@ -354,10 +310,7 @@ func TestFilterReposByConfigList_EmptyDepotList(t *testing.T) {
 
 // TestFilterReposByConfigList_DuplicateConfig 测试配置中有重复的仓库
 func TestFilterReposByConfigList_DuplicateConfig(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{
+	m := newTestMigrator([]string{
 		"org/project/repo1",
 		"org/project/repo1", // 重复
 		"org/project/repo2",
@@ -368,7 +321,7 @@ func TestFilterReposByConfigList_DuplicateConfig(t *testing.T) {
 		&MockVCS{repoPath: "org/project/repo2"},
 	}
 
-	result, _ := filterReposByConfigList(depotList)
+	result, _ := m.filterReposByConfigList(depotList)
 
 	if len(result) != 2 {
 		t.Errorf("重复配置应该正确处理，期望 2 个仓库，实际 %d 个", len(result))
@@ -377,11 +330,8 @@ func TestFilterReposByConfigList_DuplicateConfig(t *testing.T) {
 
 // TestFilterReposByConfigList_NotFound 测试未找到仓库的计数
 func TestFilterReposByConfigList_NotFound(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
 	// 配置2个仓库，但只有1个存在
-	config.Cfg = setupTestConfig([]string{
+	m := newTestMigrator([]string{
 		"org/project/repo1",
 		"org/project/repo-not-exist",
 	})
@@ -391,7 +341,7 @@ func TestFilterReposByConfigList_NotFound(t *testing.T) {
 		&MockVCS{repoPath: "org/project/repo2"},
 	}
 
-	result, notFoundCount := filterReposByConfigList(depotList)
+	result, notFoundCount := m.filterReposByConfigList(depotList)
 
 	if len(result) != 1 {
 		t.Errorf("应该返回1个匹配的仓库，实际 %d 个", len(result))
@@ -399,14 +349,14 @@ func TestFilterReposByConfigList_NotFound(t *testing.T) {
 	if notFoundCount != 1 {
 		t.Errorf("应该有1个未找到的仓库，实际 %d 个", notFoundCount)
 	}
+	if len(m.notFoundRepos) != 1 || m.notFoundRepos[0] != "org/project/repo-not-exist" {
+		t.Errorf("未找到的仓库应记录到迁移报告，实际 %v", m.notFoundRepos)
+	}
 }
 
 // TestFilterReposByConfigList_AllNotFound 测试所有仓库都未找到
 func TestFilterReposByConfigList_AllNotFound(t *testing.T) {
-	oldCfg := config.Cfg
-	defer func() { config.Cfg = oldCfg }()
-
-	config.Cfg = setupTestConfig([]string{
+	m := newTestMigrator([]string{
 		"org/project/repo-not-exist1",
 		"org/project/repo-not-exist2",
 	})
@@ -416,7 +366,7 @@ func TestFilterReposByConfigList_AllNotFound(t *testing.T) {
 		&MockVCS{repoPath: "org/project/repo2"},
 	}
 
-	result, notFoundCount := filterReposByConfigList(depotList)
+	result, notFoundCount := m.filterReposByConfigList(depotList)
 
 	if len(result) != 0 {
 		t.Errorf("应该返回0个匹配的仓库，实际 %d 个", len(result))
@@ -493,3 +443,56 @@ func TestFilterReleasesByTagOrLatest_NotFound(t *testing.T) {
 		t.Fatalf("expected no selected releases, got %d", len(selected))
 	}
 }
+
+func TestKeepHiddenSelections(t *testing.T) {
+	items := []picker.Item{{Path: "group/a"}, {Path: "group/b"}}
+	preselected := map[string]bool{"group/a": true, "group/z": true, "archived/c": true}
+
+	// group/a 在列表中显示，由本次选择决定；未显示的 archived/c、group/z 保留
+	result, hidden := keepHiddenSelections([]string{"group/b"}, preselected, items)
+	expected := []string{"group/b", "archived/c", "group/z"}
+	if hidden != 2 || !reflect.DeepEqual(result, expected) {
+		t.Errorf("keepHiddenSelections() = %v, %d, 期望 %v, 2", result, hidden, expected)
+	}
+}
+
+func TestPickerItems(t *testing.T) {
+	depotList := []vcs.VCS{
+		&MockVCS{repoPath: "team/project/api", subGroup: &vcs.SubGroup{Name: "project"}},
+		&MockVCS{repoPath: "team/project/web", subGroup: &vcs.SubGroup{Name: "project"}},
+		&MockVCS{repoPath: "owner/tool"},
+	}
+	var groups []string
+	for _, item := range pickerItems(depotList) {
+		groups = append(groups, item.Group)
+	}
+	expected := []string{"project", "project", ""}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("pickerItems() 分组 = %v, 期望 %v", groups, expected)
+	}
+}
+
+// TestInitMigrationStats_SourceReposWithExclude 测试同时配置 source.repo 与 source.exclude 时，仓库总数与失败数一致
+func TestInitMigrationStats_SourceReposWithExclude(t *testing.T) {
+	m := NewMigrator(Options{
+		SourceRepos:   []string{"org/project/repo1", "org/project/repo-archive", "org/project/repo-not-exist"},
+		SourceExclude: []string{"re:-archive$"},
+	})
+	depotList := []vcs.VCS{
+		&MockVCS{repoPath: "org/project/repo1"},
+		&MockVCS{repoPath: "org/project/repo-archive"},
+		&MockVCS{repoPath: "org/project/repo2"},
+	}
+
+	result, notFoundCount := m.filterReposByConfigList(depotList)
+	result, err := m.filterReposByRules(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.initMigrationStats(result, notFoundCount)
+
+	// 被 exclude 过滤掉的仓库不计入统计：1 个待迁移 + 1 个未找到
+	if m.totalRepoNumber != 2 || m.failedRepoNumber != 2 {
+		t.Errorf("仓库总数 = %d, 失败数 = %d, 期望均为 2", m.totalRepoNumber, m.failedRepoNumber)
+	}
+}
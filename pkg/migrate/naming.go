@@ -0,0 +1,226 @@
+package migrate
+
+import (
+	"ccrctl/pkg/api/target"
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/naming"
+	"ccrctl/pkg/report"
+	"ccrctl/pkg/vcs"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// targetMappingLevel 目标仓库路径已包含子组织，按 organization_mapping_level 1 拼接 CNB 仓库地址
+const targetMappingLevel = 1
+
+// 多个仓库映射到同一目标路径时的处理策略
+const (
+	CollisionFail    = "fail"    // 报错退出
+	CollisionSuffix  = "suffix"  // 在仓库名后追加源仓库子组织，如 group-a/repo → repo-group-a
+	CollisionMapping = "mapping" // 要求在映射文件中为冲突仓库显式配置目标路径
+)
+
+// collisionSuffixRule 按 suffix 策略改名的仓库在 targetRules 中记录的规则
+const collisionSuffixRule = "collision_strategy: suffix"
+
+// resolveTargets 按 migrate.target_mapping_file、migrate.target_rules、migrate.target_template 计算每个仓库的 CNB 目标路径，
+// 未命中规则的仓库按 organization_mapping_level 计算；检查规则生成的路径是否符合 CNB 命名规则，并按 collision_strategy 处理
+// 多个仓库映射到同一目标的情况，在创建任何组织或仓库之前调用，检查不通过时返回所有问题
+// retry-failed 时沿用上次迁移记录的目标路径
+func (m *Migrator) resolveTargets(depotList []vcs.VCS) error {
+	mapper, err := naming.New(m.opts.TargetRules, m.opts.TargetTemplate, m.opts.TargetMappingFile)
+	if err != nil {
+		return err
+	}
+	m.targets = make(map[string]naming.Target, len(depotList))
+	m.targetRules = make(map[string]string, len(depotList))
+	var problems []string
+	for _, depot := range depotList {
+		repoPath := depot.GetRepoPath()
+		t := m.defaultTarget(depot)
+		mapped, rule, err := mapper.Map(naming.Source{Path: repoPath, SubGroup: depot.GetSubGroup().Name, Name: depot.GetRepoName()})
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if rule != "" {
+			if err := target.CheckRepoPath(mapped); err != nil {
+				problems = append(problems, fmt.Sprintf("%s (%s): %s", repoPath, rule, err))
+				continue
+			}
+			t = naming.ParseTarget(mapped)
+			logger.Repo(repoPath).Infof("%s 按 %s 映射到 %s", repoPath, rule, t.Path())
+		}
+		m.targets[repoPath] = t
+		m.targetRules[repoPath] = rule
+	}
+	if m.previousState != nil {
+		m.reusePreviousTargets()
+	}
+	problems = append(problems, m.resolveCollisions(depotList)...)
+	if len(problems) > 0 {
+		return fmt.Errorf("目标仓库路径检查失败:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// reusePreviousTargets 沿用上次迁移记录的目标路径及规则，只重试部分仓库时冲突的另一方可能不在本次范围内，
+// 重新计算会使按 suffix 策略改名的仓库恢复为原路径，推送到另一个仓库
+func (m *Migrator) reusePreviousTargets() {
+	prefix := "/" + m.target.RootOrganization + "/"
+	for _, repo := range m.previousState.Repos {
+		repoPath := repo.Result.Source
+		current, ok := m.targets[repoPath]
+		if !ok || !strings.HasPrefix(repo.Result.Target, prefix) {
+			continue
+		}
+		t := naming.ParseTarget(strings.TrimPrefix(repo.Result.Target, prefix))
+		if t != current {
+			logger.Repo(repoPath).Infof("%s 沿用上次迁移的目标路径 %s", repoPath, t.Path())
+		}
+		m.targets[repoPath] = t
+		m.targetRules[repoPath] = repo.Result.TargetRule
+	}
+}
+
+// collisions 返回映射到同一目标路径的源仓库，每组按源仓库路径排序，CNB 仓库路径不区分大小写
+func (m *Migrator) collisions() [][]string {
+	sources := make(map[string][]string)
+	for repoPath, t := range m.targets {
+		key := strings.ToLower(t.Path())
+		sources[key] = append(sources[key], repoPath)
+	}
+	var groups [][]string
+	for _, repoPaths := range sources {
+		if len(repoPaths) > 1 {
+			sort.Strings(repoPaths)
+			groups = append(groups, repoPaths)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0] < groups[j][0]
+	})
+	return groups
+}
+
+// resolveCollisions 按 collision_strategy 处理目标路径冲突，返回无法处理的冲突
+func (m *Migrator) resolveCollisions(depotList []vcs.VCS) []string {
+	groups := m.collisions()
+	if len(groups) == 0 {
+		return nil
+	}
+	depots := make(map[string]vcs.VCS, len(depotList))
+	for _, depot := range depotList {
+		depots[depot.GetRepoPath()] = depot
+	}
+	var problems []string
+	switch m.opts.CollisionStrategy {
+	case CollisionSuffix:
+		for _, group := range groups {
+			for _, repoPath := range group {
+				// 映射文件中显式配置的目标路径不改名
+				if m.targetRules[repoPath] == naming.MappingFileRule {
+					continue
+				}
+				t, ok := suffixTarget(m.targets[repoPath], depots[repoPath])
+				if !ok {
+					continue
+				}
+				if err := target.CheckRepoPath(t.Path()); err != nil {
+					problems = append(problems, fmt.Sprintf("%s (%s): %s", repoPath, collisionSuffixRule, err))
+					continue
+				}
+				logger.Repo(repoPath).Infof("%s 目标路径 %s 与其他仓库冲突，改为 %s", repoPath, m.targets[repoPath].Path(), t.Path())
+				m.targets[repoPath] = t
+				m.targetRules[repoPath] = collisionSuffixRule
+			}
+		}
+		// 改名后仍冲突的按 fail 处理
+		for _, group := range m.collisions() {
+			problems = append(problems, m.collisionProblem(group))
+		}
+	case CollisionMapping:
+		var entries []string
+		for _, group := range groups {
+			problems = append(problems, m.collisionProblem(group))
+			for _, repoPath := range group {
+				if m.targetRules[repoPath] == naming.MappingFileRule {
+					continue
+				}
+				t, _ := suffixTarget(m.targets[repoPath], depots[repoPath])
+				entries = append(entries, fmt.Sprintf("  %s,%s", repoPath, t.Path()))
+			}
+		}
+		if len(entries) > 0 {
+			problems = append(problems, "请在 migrate.target_mapping_file 中为冲突仓库配置目标路径，如:")
+			problems = append(problems, entries...)
+		}
+	default:
+		for _, group := range groups {
+			problems = append(problems, m.collisionProblem(group))
+		}
+		problems = append(problems, "可配置 migrate.collision_strategy 为 suffix 或 mapping 处理冲突")
+	}
+	return problems
+}
+
+func (m *Migrator) collisionProblem(group []string) string {
+	return fmt.Sprintf("%s 对应多个源仓库: %s", m.targets[group[0]].Path(), strings.Join(group, ", "))
+}
+
+// suffixTarget 在目标仓库名后追加源仓库子组织，多级子组织以 - 连接，源仓库无子组织时返回 false
+func suffixTarget(t naming.Target, depot vcs.VCS) (naming.Target, bool) {
+	subGroup := depot.GetSubGroup()
+	if subGroup == nil || subGroup.Name == "" {
+		return t, false
+	}
+	t.Name += "-" + strings.ReplaceAll(subGroup.Name, "/", "-")
+	return t, true
+}
+
+// recordTarget 在迁移结果中记录仓库的 CNB 仓库路径及决定该路径的规则
+func (m *Migrator) recordTarget(result *report.Repo, depot vcs.VCS) {
+	result.Target, _ = m.cnbRepoPathAndGroup(depot)
+	result.TargetRule = m.targetRules[depot.GetRepoPath()]
+}
+
+// defaultTarget 按 organization_mapping_level 计算目标路径，1 保留源仓库子组织，2 只保留仓库名称
+func (m *Migrator) defaultTarget(depot vcs.VCS) naming.Target {
+	t := naming.Target{Name: depot.GetRepoName()}
+	if m.opts.OrganizationMappingLevel == 1 {
+		t.SubGroup = depot.GetSubGroup().Name
+	}
+	return t
+}
+
+// targetOf 返回仓库在根组织下的目标路径，未经 resolveTargets 计算的仓库按 organization_mapping_level 计算
+func (m *Migrator) targetOf(depot vcs.VCS) naming.Target {
+	if t, ok := m.targets[depot.GetRepoPath()]; ok {
+		return t
+	}
+	return m.defaultTarget(depot)
+}
+
+// cnbRepoPathAndGroup 返回仓库的 CNB 仓库路径及所属组织路径，如 /root/team/api、/root/team
+func (m *Migrator) cnbRepoPathAndGroup(depot vcs.VCS) (string, string) {
+	t := m.targetOf(depot)
+	return m.target.GetCnbRepoPathAndGroup(t.SubGroup, t.Name, targetMappingLevel)
+}
+
+// targetSubGroups 返回需要创建的子组织，目标子组织与源仓库子组织相同时保留源平台的描述和备注
+func (m *Migrator) targetSubGroups(depotList []vcs.VCS) []*vcs.SubGroup {
+	var subGroups []*vcs.SubGroup
+	for _, depot := range depotList {
+		t := m.targetOf(depot)
+		if t.SubGroup == "" {
+			continue
+		}
+		subGroup := &vcs.SubGroup{Name: t.SubGroup}
+		if source := depot.GetSubGroup(); source != nil && source.Name == t.SubGroup {
+			subGroup = source
+		}
+		subGroups = append(subGroups, subGroup)
+	}
+	return subGroups
+}
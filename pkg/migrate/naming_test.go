@@ -0,0 +1,163 @@
+package migrate
+
+import (
+	"ccrctl/pkg/report"
+	"ccrctl/pkg/vcs"
+	"strings"
+	"testing"
+)
+
+func TestResolveTargets(t *testing.T) {
+	m := NewMigrator(Options{
+		RootOrganization:         "cnb-org",
+		OrganizationMappingLevel: 1,
+		TargetRules:              []string{`^legacy/(.*)$ => archive/$1`},
+	})
+	depotList := []vcs.VCS{
+		&vcs.GithubVcs{RepoPath: "team/api", RepoName: "api"},
+		&vcs.GithubVcs{RepoPath: "legacy/web", RepoName: "web"},
+	}
+	if err := m.resolveTargets(depotList); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"team/api":   "/cnb-org/team/api",
+		"legacy/web": "/cnb-org/archive/web",
+	}
+	for _, depot := range depotList {
+		if got, _ := m.cnbRepoPathAndGroup(depot); got != want[depot.GetRepoPath()] {
+			t.Errorf("cnbRepoPathAndGroup(%s) = %s, want %s", depot.GetRepoPath(), got, want[depot.GetRepoPath()])
+		}
+	}
+	subGroups := m.targetSubGroups(depotList)
+	if len(subGroups) != 2 || subGroups[0].Name != "team" || subGroups[1].Name != "archive" {
+		t.Errorf("targetSubGroups() = %+v", subGroups)
+	}
+}
+
+func TestResolveTargetsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		depots  []vcs.VCS
+		wantErr string
+	}{
+		{
+			name: "不符合命名规则",
+			opts: Options{OrganizationMappingLevel: 1, TargetTemplate: "{{.SubGroup}}/-{{.Name}}"},
+			depots: []vcs.VCS{
+				&vcs.GithubVcs{RepoPath: "team/api", RepoName: "api"},
+			},
+			wantErr: "不符合 CNB 命名规则",
+		},
+		{
+			name: "规则映射到同一目标",
+			opts: Options{OrganizationMappingLevel: 1, TargetRules: []string{`^.*/(\w+)$ => flat/$1`}},
+			depots: []vcs.VCS{
+				&vcs.GithubVcs{RepoPath: "a/api", RepoName: "api"},
+				&vcs.GithubVcs{RepoPath: "b/API", RepoName: "API"},
+			},
+			wantErr: "flat/api 对应多个源仓库: a/api, b/API",
+		},
+		{
+			name: "organization_mapping_level 2 同名仓库",
+			opts: Options{OrganizationMappingLevel: 2},
+			depots: []vcs.VCS{
+				&vcs.GithubVcs{RepoPath: "a/api", RepoName: "api"},
+				&vcs.GithubVcs{RepoPath: "b/api", RepoName: "api"},
+			},
+			wantErr: "api 对应多个源仓库: a/api, b/api",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.RootOrganization = "cnb-org"
+			err := NewMigrator(tt.opts).resolveTargets(tt.depots)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolveTargets() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveTargetsCollisionStrategy(t *testing.T) {
+	depotList := []vcs.VCS{
+		&vcs.GithubVcs{RepoPath: "group-a/api", RepoName: "api"},
+		&vcs.GithubVcs{RepoPath: "team/b/api", RepoName: "api"},
+		&vcs.GithubVcs{RepoPath: "group-a/web", RepoName: "web"},
+	}
+	tests := []struct {
+		name     string
+		strategy string
+		want     map[string]string
+		wantErr  []string
+	}{
+		{
+			name:     "fail",
+			strategy: CollisionFail,
+			wantErr:  []string{"api 对应多个源仓库: group-a/api, team/b/api", "migrate.collision_strategy"},
+		},
+		{
+			name:     "suffix",
+			strategy: CollisionSuffix,
+			want: map[string]string{
+				"group-a/api": "/cnb-org/api-group-a",
+				"team/b/api":  "/cnb-org/api-team-b",
+				"group-a/web": "/cnb-org/web",
+			},
+		},
+		{
+			name:     "mapping",
+			strategy: CollisionMapping,
+			wantErr:  []string{"target_mapping_file", "group-a/api,api-group-a", "team/b/api,api-team-b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMigrator(Options{RootOrganization: "cnb-org", OrganizationMappingLevel: 2, CollisionStrategy: tt.strategy})
+			err := m.resolveTargets(depotList)
+			if len(tt.wantErr) > 0 {
+				if err == nil {
+					t.Fatal("resolveTargets() error = nil")
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("resolveTargets() error = %v, want %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, depot := range depotList {
+				result := &report.Repo{Source: depot.GetRepoPath()}
+				m.recordTarget(result, depot)
+				if result.Target != tt.want[result.Source] {
+					t.Errorf("recordTarget(%s) Target = %s, want %s", result.Source, result.Target, tt.want[result.Source])
+				}
+				if result.Source != "group-a/web" && result.TargetRule != collisionSuffixRule {
+					t.Errorf("recordTarget(%s) TargetRule = %q", result.Source, result.TargetRule)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveTargetsRetryKeepsSuffix(t *testing.T) {
+	m := NewMigrator(Options{RootOrganization: "cnb-org", OrganizationMappingLevel: 2, CollisionStrategy: CollisionSuffix})
+	m.previousState = &State{Repos: []StateRepo{
+		{Result: report.Repo{Source: "group-a/api", Target: "/cnb-org/api-group-a", TargetRule: collisionSuffixRule}},
+		{Result: report.Repo{Source: "team/b/api", Target: "/cnb-org/api-team-b", TargetRule: collisionSuffixRule}},
+	}}
+	// 只重试其中一个仓库，不存在冲突也应沿用上次改名后的路径
+	depot := &vcs.GithubVcs{RepoPath: "group-a/api", RepoName: "api"}
+	if err := m.resolveTargets([]vcs.VCS{depot}); err != nil {
+		t.Fatal(err)
+	}
+	result := &report.Repo{Source: depot.GetRepoPath()}
+	m.recordTarget(result, depot)
+	if result.Target != "/cnb-org/api-group-a" || result.TargetRule != collisionSuffixRule {
+		t.Errorf("recordTarget() = %s (%s), want /cnb-org/api-group-a (%s)", result.Target, result.TargetRule, collisionSuffixRule)
+	}
+}
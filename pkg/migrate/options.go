@@ -0,0 +1,237 @@
+package migrate
+
+import (
+	"ccrctl/pkg/git"
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
+	"ccrctl/pkg/vcs"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Options 迁移选项，字段与配置文件中的参数一一对应
+type Options struct {
+	CnbURL           string
+	CnbToken         string
+	RootOrganization string
+	CnbSSH           bool
+	CnbSSHPrivateKey string
+	CnbSSHPassphrase string
+	CnbSSHPort       string
+	CnbSSHKnownHosts string
+
+	SourcePlatform      string
+	SourceURL           string
+	SourceAPIURL        string
+	SourceRepos         []string
+	SourceInclude       []string
+	SourceExclude       []string
+	SourceSSHPrivateKey string
+	SourceSSHPassphrase string
+	SourceSSHKnownHosts string
+
+	Concurrency              int
+	OrganizationMappingLevel int
+	ForcePush                bool
+	UseLfsMigrate            bool
+	FileLimitSize            int64
+	SkipExistsRepo           bool
+	MigrateRelease           bool
+	MigrateCode              bool
+	Rebase                   bool
+	DownloadOnly             bool
+	DropPlatformRefs         bool
+	AllowSelectRepos         bool
+	ReleaseTag               string
+	RefFilter                git.RefFilter
+
+	ChunkPush         bool
+	ChunkPushStep     int
+	ChunkPushRefBatch int
+
+	LargeFileScan         bool
+	LargeFileStrategy     string
+	LargeFileRepoStrategy []string
+
+	PruneRefs       bool
+	PruneMaxPercent int
+
+	SubmoduleRewrite      string
+	SubmoduleBranchPrefix string
+
+	// MetadataFilter 按仓库元数据过滤
+	MetadataFilter MetadataFilter
+
+	// TargetRules 按顺序匹配的目标路径重写规则，格式 <正则表达式> => <目标路径>
+	TargetRules []string
+	// TargetTemplate 目标路径模板，如 {{.SubGroup | lower}}/{{.Name}}
+	TargetTemplate string
+	// TargetMappingFile 源仓库路径 → 目标路径的 CSV/YAML 映射文件，优先于规则和模板
+	TargetMappingFile string
+	// CollisionStrategy 多个仓库映射到同一目标路径时的处理策略 fail/suffix/mapping
+	CollisionStrategy string
+
+	// Report 迁移报告格式 json/junit/html，为空时不生成报告
+	Report []string
+
+	// Vcs 源平台仓库选项，其中 SSH 对应 migrate.ssh
+	Vcs vcs.Options
+	// Log 日志选项，由调用方在 Run 之前传给 logger.Init
+	Log logger.Options
+
+	// WorkDir 仓库克隆目录，为空时使用当前目录下的 source_git_dir，只下载模式下目录名带时间戳
+	// local 平台未指定 Vcs.Source.Dir 时从该目录读取待迁移仓库
+	WorkDir string
+	// ReportDir successful.log、迁移报告、迁移状态等文件所在目录，为空时使用 Log.Dir，同样为空时使用当前目录
+	ReportDir string
+}
+
+// OptionsFromConfig 从配置中读取迁移选项
+func OptionsFromConfig(v *viper.Viper) Options {
+	return Options{
+		CnbURL:           v.GetString("cnb.url"),
+		CnbToken:         v.GetString("cnb.token"),
+		RootOrganization: v.GetString("cnb.root_organization"),
+		CnbSSH:           v.GetBool("cnb.ssh"),
+		CnbSSHPrivateKey: v.GetString("cnb.ssh_private_key"),
+		CnbSSHPassphrase: v.GetString("cnb.ssh_passphrase"),
+		CnbSSHPort:       v.GetString("cnb.ssh_port"),
+		CnbSSHKnownHosts: v.GetString("cnb.ssh_known_hosts"),
+
+		SourcePlatform:      v.GetString("source.platform"),
+		SourceURL:           v.GetString("source.url"),
+		SourceAPIURL:        v.GetString("source.api_url"),
+		SourceRepos:         nonEmptyStrings(v.GetStringSlice("source.repo")),
+		SourceInclude:       nonEmptyStrings(v.GetStringSlice("source.include")),
+		SourceExclude:       nonEmptyStrings(v.GetStringSlice("source.exclude")),
+		SourceSSHPrivateKey: v.GetString("source.ssh_private_key"),
+		SourceSSHPassphrase: v.GetString("source.ssh_passphrase"),
+		SourceSSHKnownHosts: v.GetString("source.ssh_known_hosts"),
+
+		Concurrency:              v.GetInt("migrate.concurrency"),
+		OrganizationMappingLevel: v.GetInt("migrate.organization_mapping_level"),
+		ForcePush:                v.GetBool("migrate.force_push"),
+		UseLfsMigrate:            v.GetBool("migrate.use_lfs_migrate"),
+		FileLimitSize:            v.GetInt64("migrate.file_limit_size"),
+		SkipExistsRepo:           v.GetBool("migrate.skip_exists_repo"),
+		MigrateRelease:           v.GetBool("migrate.release"),
+		MigrateCode:              v.GetBool("migrate.code"),
+		Rebase:                   v.GetBool("migrate.rebase"),
+		DownloadOnly:             v.GetBool("migrate.download_only"),
+		DropPlatformRefs:         v.GetBool("migrate.drop_platform_refs"),
+		AllowSelectRepos:         v.GetBool("migrate.allow_select_repos"),
+		ReleaseTag:               strings.TrimSpace(v.GetString("migrate.release_tag")),
+		RefFilter: git.RefFilter{
+			Include: v.GetStringSlice("migrate.include_refs"),
+			Exclude: v.GetStringSlice("migrate.exclude_refs"),
+		},
+
+		ChunkPush:         v.GetBool("migrate.chunk_push"),
+		ChunkPushStep:     v.GetInt("migrate.chunk_push_step"),
+		ChunkPushRefBatch: v.GetInt("migrate.chunk_push_ref_batch"),
+
+		LargeFileScan:         v.GetBool("migrate.large_file_scan"),
+		LargeFileStrategy:     strings.TrimSpace(v.GetString("migrate.large_file_strategy")),
+		LargeFileRepoStrategy: v.GetStringSlice("migrate.large_file_repo_strategy"),
+
+		PruneRefs:       v.GetBool("migrate.prune_refs"),
+		PruneMaxPercent: v.GetInt("migrate.prune_max_percent"),
+
+		SubmoduleRewrite:      v.GetString("migrate.submodule_rewrite"),
+		SubmoduleBranchPrefix: v.GetString("migrate.submodule_branch_prefix"),
+
+		MetadataFilter: MetadataFilter{
+			SkipArchived: v.GetBool("migrate.skip_archived"),
+			SkipFork:     v.GetBool("migrate.skip_fork"),
+			MaxSizeMB:    v.GetInt64("migrate.max_size_mb"),
+			ActiveSince:  activeSince(v.GetString("migrate.active_since")),
+			OnlyPrivate:  v.GetBool("migrate.only_private"),
+		},
+
+		TargetRules:       nonEmptyStrings(v.GetStringSlice("migrate.target_rules")),
+		TargetTemplate:    v.GetString("migrate.target_template"),
+		TargetMappingFile: v.GetString("migrate.target_mapping_file"),
+		CollisionStrategy: strings.TrimSpace(v.GetString("migrate.collision_strategy")),
+
+		Report: reportFormats(v.GetStringSlice("migrate.report")),
+
+		Vcs: vcs.Options{
+			SSH:                    v.GetBool("migrate.ssh"),
+			AllowIncompletePush:    v.GetBool("migrate.allow_incomplete_push"),
+			MapCodingDescription:   v.GetBool("migrate.map_coding_description"),
+			MapCodingDisplayName:   v.GetBool("migrate.map_coding_display_name"),
+			GerritChangesNamespace: v.GetString("migrate.gerrit_changes_namespace"),
+			Source: source.Options{
+				Platform:            v.GetString("source.platform"),
+				URL:                 v.GetString("source.url"),
+				APIURL:              v.GetString("source.api_url"),
+				Token:               v.GetString("source.token"),
+				Username:            v.GetString("source.username"),
+				Password:            v.GetString("source.password"),
+				Projects:            v.GetStringSlice("source.project"),
+				Repos:               nonEmptyStrings(v.GetStringSlice("source.repo")),
+				Group:               v.GetString("source.group"),
+				OrganizationID:      v.GetString("source.organizationId"),
+				Organizations:       trimmedStrings(v.GetStringSlice("source.organizations")),
+				Users:               trimmedStrings(v.GetStringSlice("source.users")),
+				Enterprise:          v.GetString("source.enterprise"),
+				AK:                  v.GetString("source.ak"),
+				SK:                  v.GetString("source.sk"),
+				Region:              v.GetString("source.region"),
+				GitlabProjectsOwned: v.GetBool("migrate.gitlab_projects_owned"),
+				ExcludeGithubFork:   v.GetBool("migrate.exclude_github_fork"),
+			},
+		},
+		Log: logger.Options{
+			Level:   v.GetString("migrate.log_level"),
+			Format:  v.GetString("migrate.log_format"),
+			RepoLog: v.GetBool("migrate.repo_log"),
+		},
+	}
+}
+
+// nonEmptyStrings 去掉空白项，配置 source.repo 为空字符串时视为未配置
+func nonEmptyStrings(items []string) []string {
+	var result []string
+	for _, item := range items {
+		if strings.TrimSpace(item) != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// trimmedStrings 去掉每项首尾空白及空白项
+func trimmedStrings(items []string) []string {
+	var result []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// reportFormats 去掉空白项，配置为 off 时不生成报告
+func reportFormats(items []string) []string {
+	var result []string
+	for _, item := range nonEmptyStrings(items) {
+		item = strings.TrimSpace(item)
+		if item == "off" {
+			return nil
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// activeSince 解析 migrate.active_since，格式为 YYYY-MM-DD，未配置或格式错误时返回零值，格式由 config.CheckConfig 校验
+func activeSince(value string) time.Time {
+	t, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,104 @@
+package migrate
+
+import (
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/vcs"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// 迁移计划中仓库的处理方式
+const (
+	PlanMigrate  = "migrate"
+	PlanExclude  = "exclude"
+	PlanNotFound = "not_found"
+)
+
+// PlanEntry 迁移计划中的单个仓库
+type PlanEntry struct {
+	Action string
+	Source string
+	// Target CNB 仓库路径，只下载模式或未迁移的仓库为空
+	Target string
+	// Rule 决定仓库被选中或排除的规则
+	Rule string
+}
+
+// Plan 获取源平台仓库列表并按配置过滤，输出每个仓库是否迁移及命中的规则，不执行迁移
+func (m *Migrator) Plan(w io.Writer) int {
+	sourceVcsList, err := vcs.New(m.opts.SourcePlatform, m.opts.Vcs)
+	if err != nil {
+		logger.Logger.Errorf("获取源平台仓库列表失败，请检查配置参数: %s", err)
+		return 1
+	}
+	selected, _, err := m.selectRepos(sourceVcsList, true)
+	if err != nil {
+		logger.Logger.Errorf("%s", err)
+		return 1
+	}
+	var targetErr error
+	if !m.opts.DownloadOnly {
+		targetErr = m.resolveTargets(selected)
+	}
+	if err := writePlan(w, m.planEntries(sourceVcsList, selected)); err != nil {
+		logger.Logger.Errorf("输出迁移计划失败: %s", err)
+		return 1
+	}
+	if targetErr != nil {
+		logger.Logger.Errorf("%s", targetErr)
+		return 1
+	}
+	return 0
+}
+
+// planEntries 按源仓库路径排序生成迁移计划，source.repo 中未找到的仓库排在最后
+func (m *Migrator) planEntries(depotList, selected []vcs.VCS) []PlanEntry {
+	selectedSet := make(map[string]bool, len(selected))
+	for _, depot := range selected {
+		selectedSet[depot.GetRepoPath()] = true
+	}
+	entries := make([]PlanEntry, 0, len(depotList)+len(m.notFoundRepos))
+	for _, depot := range depotList {
+		repoPath := depot.GetRepoPath()
+		entry := PlanEntry{Action: PlanExclude, Source: repoPath, Rule: m.selection[repoPath]}
+		if selectedSet[repoPath] {
+			entry.Action = PlanMigrate
+			if !m.opts.DownloadOnly {
+				entry.Target, _ = m.cnbRepoPathAndGroup(depot)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Source < entries[j].Source
+	})
+	for _, repoPath := range m.notFoundRepos {
+		entries = append(entries, PlanEntry{Action: PlanNotFound, Source: repoPath, Rule: "source.repo"})
+	}
+	return entries
+}
+
+// writePlan 以表格输出迁移计划及汇总
+func writePlan(w io.Writer, entries []PlanEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tSOURCE\tTARGET\tRULE")
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		counts[entry.Action]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", entry.Action, entry.Source, dashIfEmpty(entry.Target), dashIfEmpty(entry.Rule))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n待迁移: %d，排除: %d，未找到: %d\n", counts[PlanMigrate], counts[PlanExclude], counts[PlanNotFound])
+	return err
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+package migrate
+
+import (
+	"bytes"
+	"ccrctl/pkg/vcs"
+	"strings"
+	"testing"
+)
+
+func TestPlanEntries(t *testing.T) {
+	m := NewMigrator(Options{
+		SourcePlatform:           "github",
+		RootOrganization:         "cnb-org",
+		OrganizationMappingLevel: 1,
+		SourceInclude:            []string{"team/*"},
+		SourceExclude:            []string{"re:-archive$"},
+	})
+	depotList := []vcs.VCS{
+		&vcs.GithubVcs{RepoPath: "team/api", RepoName: "api"},
+		&vcs.GithubVcs{RepoPath: "team/old-archive", RepoName: "old-archive"},
+		&vcs.GithubVcs{RepoPath: "other/web", RepoName: "web"},
+	}
+	selected, _, err := m.selectRepos(depotList, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := m.planEntries(depotList, selected)
+	want := []PlanEntry{
+		{Action: PlanExclude, Source: "other/web", Rule: "include: 未匹配任何规则"},
+		{Action: PlanMigrate, Source: "team/api", Target: "/cnb-org/team/api", Rule: "include: team/*"},
+		{Action: PlanExclude, Source: "team/old-archive", Rule: "exclude: re:-archive$"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("planEntries() = %+v", entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("planEntries()[%d] = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writePlan(&buf, entries); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "待迁移: 1，排除: 2，未找到: 0") {
+		t.Errorf("计划汇总错误:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,79 @@
+package migrate
+
+import (
+	"ccrctl/pkg/git"
+	"ccrctl/pkg/logger"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const PruneReportName = "prune-report.log"
+
+// pruneRefs 删除 CNB 仓库中源仓库已删除的分支和标签，待删除引用占比超过 migrate.prune_max_percent 时放弃删除并返回错误
+func (m *Migrator) pruneRefs(repoPath, pushURL string) error {
+	if !m.opts.PruneRefs {
+		return nil
+	}
+	stale, total, err := git.FindStaleRefs(m.repoDir(repoPath), pushURL, m.opts.RefFilter, m.gitEnv)
+	if err != nil {
+		return err
+	}
+	stale = m.excludeSubmoduleRewriteBranches(stale)
+	if len(stale) == 0 {
+		logger.Repo(repoPath).Infof("%s 目标仓库没有需要删除的引用", repoPath)
+		return nil
+	}
+	if err := checkPruneThreshold(len(stale), total, m.opts.PruneMaxPercent); err != nil {
+		if reportErr := m.writePruneReport(repoPath, stale, false); reportErr != nil {
+			logger.Repo(repoPath).Warnf("%s 写入引用删除报告失败: %s", repoPath, reportErr)
+		}
+		return fmt.Errorf("%s %s，已放弃删除，待删除引用详见 %s", repoPath, err, PruneReportName)
+	}
+	output, err := git.DeleteRemoteRefs(m.repoDir(repoPath), pushURL, stale, m.gitEnv)
+	if err != nil {
+		return fmt.Errorf("%s 删除目标仓库引用失败: %s\n %s", repoPath, err, output)
+	}
+	if reportErr := m.writePruneReport(repoPath, stale, true); reportErr != nil {
+		logger.Repo(repoPath).Warnf("%s 写入引用删除报告失败: %s", repoPath, reportErr)
+	}
+	return nil
+}
+
+// excludeSubmoduleRewriteBranches 子模块改写分支只存在于 CNB 侧，不能作为源仓库已删除的引用删除
+func (m *Migrator) excludeSubmoduleRewriteBranches(refs []string) []string {
+	var result []string
+	for _, ref := range refs {
+		if !m.isSubmoduleRewriteBranch(ref) {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+// checkPruneThreshold 检查待删除引用占目标仓库引用总数的比例是否超过阈值
+func checkPruneThreshold(staleCount, total, maxPercent int) error {
+	if total == 0 || staleCount*100 <= total*maxPercent {
+		return nil
+	}
+	return fmt.Errorf("待删除引用 %d/%d 超过阈值 %d%%", staleCount, total, maxPercent)
+}
+
+// writePruneReport 将删除的引用追加写入报告文件
+func (m *Migrator) writePruneReport(repoPath string, refs []string, deleted bool) error {
+	return m.appendReport(PruneReportName, formatPruneReport(repoPath, refs, deleted, time.Now()))
+}
+
+// formatPruneReport 生成单个仓库的引用删除报告内容
+func formatPruneReport(repoPath string, refs []string, deleted bool, now time.Time) string {
+	status := "已删除"
+	if !deleted {
+		status = "超过阈值未删除"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s 引用数: %d 状态: %s\n", now.Format("2006-01-02 15:04:05"), repoPath, len(refs), status)
+	for _, ref := range refs {
+		fmt.Fprintf(&b, "  %s\n", ref)
+	}
+	return b.String()
+}
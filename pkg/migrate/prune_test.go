@@ -0,0 +1,59 @@
+package migrate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckPruneThreshold(t *testing.T) {
+	tests := []struct {
+		name       string
+		staleCount int
+		total      int
+		maxPercent int
+		wantErr    bool
+	}{
+		{name: "未超过阈值", staleCount: 1, total: 10, maxPercent: 20, wantErr: false},
+		{name: "等于阈值", staleCount: 2, total: 10, maxPercent: 20, wantErr: false},
+		{name: "超过阈值", staleCount: 3, total: 10, maxPercent: 20, wantErr: true},
+		{name: "阈值为100", staleCount: 10, total: 10, maxPercent: 100, wantErr: false},
+		{name: "阈值为0", staleCount: 1, total: 10, maxPercent: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPruneThreshold(tt.staleCount, tt.total, tt.maxPercent)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkPruneThreshold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatPruneReport(t *testing.T) {
+	refs := []string{"refs/heads/old", "refs/tags/v1"}
+	tests := []struct {
+		name    string
+		deleted bool
+		want    []string
+	}{
+		{
+			name:    "已删除",
+			deleted: true,
+			want:    []string{"group/repo 引用数: 2 状态: 已删除", "  refs/heads/old", "  refs/tags/v1"},
+		},
+		{
+			name:    "超过阈值",
+			deleted: false,
+			want:    []string{"group/repo 引用数: 2 状态: 超过阈值未删除", "  refs/heads/old", "  refs/tags/v1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := reportLines(t, formatPruneReport("group/repo", refs, tt.deleted, time.Now()))
+			if !reflect.DeepEqual(lines, tt.want) {
+				t.Errorf("formatPruneReport() = %q, 期望 %q", lines, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package migrate
+
+import (
+	"ccrctl/pkg/logger"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// reportMutex 并发迁移时串行追加写入报告文件
+var reportMutex sync.Mutex
+
+// appendReport 将内容追加写入 reportDir 下的报告文件
+func (m *Migrator) appendReport(name, content string) error {
+	reportMutex.Lock()
+	defer reportMutex.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(m.reportDir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(content)
+	return err
+}
+
+// successfulLogPath successful.log 路径
+func (m *Migrator) successfulLogPath() string {
+	return filepath.Join(m.reportDir, SuccessfulLogName)
+}
+
+// recordSuccessfulRepo 将迁移成功的仓库追加写入 successful.log
+func (m *Migrator) recordSuccessfulRepo(repoPath string) {
+	if err := m.appendReport(SuccessfulLogName, time.Now().Format("2006-01-02 15:04:05")+" "+repoPath+"\n"); err != nil {
+		logger.Repo(repoPath).Errorf("%s 写入 %s 失败: %s", repoPath, SuccessfulLogName, err)
+	}
+}
@@ -0,0 +1,65 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reportLines 校验单个仓库的报告内容以换行结尾且首行以时间开头，返回去掉时间后的各行
+func reportLines(t *testing.T, report string) []string {
+	t.Helper()
+	const layout = "2006-01-02 15:04:05"
+	if !strings.HasSuffix(report, "\n") {
+		t.Fatalf("报告应以换行结尾: %q", report)
+	}
+	lines := strings.Split(strings.TrimSuffix(report, "\n"), "\n")
+	if len(lines[0]) <= len(layout) || lines[0][len(layout)] != ' ' {
+		t.Fatalf("报告首行应以时间开头: %q", lines[0])
+	}
+	if _, err := time.ParseInLocation(layout, lines[0][:len(layout)], time.Local); err != nil {
+		t.Fatalf("报告首行时间格式错误: %v", err)
+	}
+	lines[0] = lines[0][len(layout)+1:]
+	return lines
+}
+
+func TestAppendReport(t *testing.T) {
+	dir := t.TempDir()
+	m := NewMigrator(Options{ReportDir: dir})
+
+	for _, content := range []string{"a\n", "b\n"} {
+		if err := m.appendReport(PruneReportName, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, PruneReportName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a\nb\n" {
+		t.Errorf("报告内容 = %q, 期望追加写入 %q", data, "a\nb\n")
+	}
+	if path := m.StateFilePath(); path != filepath.Join(dir, StateFileName) {
+		t.Errorf("StateFilePath() = %s, 期望位于 %s", path, dir)
+	}
+}
+
+// TestRecordSuccessfulRepo 测试不同 ReportDir 的迁移器分别记录迁移成功的仓库
+func TestRecordSuccessfulRepo(t *testing.T) {
+	first := NewMigrator(Options{ReportDir: t.TempDir()})
+	second := NewMigrator(Options{ReportDir: t.TempDir()})
+
+	if err, migrated := isMigrated("group/repo", first.successfulLogPath()); err != nil || migrated {
+		t.Fatalf("successful.log 不存在时 isMigrated() = %v, %v, 期望 nil, false", err, migrated)
+	}
+	first.recordSuccessfulRepo("group/repo")
+	if err, migrated := isMigrated("group/repo", first.successfulLogPath()); err != nil || !migrated {
+		t.Errorf("isMigrated() = %v, %v, 期望记录在 %s", err, migrated, first.successfulLogPath())
+	}
+	if err, migrated := isMigrated("group/repo", second.successfulLogPath()); err != nil || migrated {
+		t.Errorf("isMigrated() = %v, %v, 不应记录在 %s", err, migrated, second.successfulLogPath())
+	}
+}
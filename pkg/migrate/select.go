@@ -0,0 +1,96 @@
+package migrate
+
+import (
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/picker"
+	"ccrctl/pkg/vcs"
+	"os"
+	"sort"
+)
+
+// Select 获取源平台仓库列表并按配置过滤后，在终端中交互选择需要迁移的仓库，结果保存到 repo-path.txt
+// 已存在的 repo-path.txt 中的仓库默认选中，其中被过滤掉、未在列表中显示的仓库保存时保留
+func (m *Migrator) Select() int {
+	sourceVcsList, err := vcs.New(m.opts.SourcePlatform, m.opts.Vcs)
+	if err != nil {
+		logger.Logger.Errorf("获取源平台仓库列表失败，请检查配置参数: %s", err)
+		return 1
+	}
+	depotList, _, err := m.filterCandidates(sourceVcsList)
+	if err != nil {
+		logger.Logger.Errorf("%s", err)
+		return 1
+	}
+	if len(depotList) == 0 {
+		logger.Logger.Warnf("源平台仓库列表为空，无需选择")
+		return 0
+	}
+
+	var preselected map[string]bool
+	if _, err := os.Stat(RepoPathFile); err == nil {
+		if preselected, err = ReadSelectedRepos(); err != nil {
+			logger.Logger.Errorf("%s", err)
+			return 1
+		}
+	}
+
+	items := pickerItems(depotList)
+	selected, save, err := picker.Run(items, preselected)
+	if err != nil {
+		logger.Logger.Errorf("%s", err)
+		return 1
+	}
+	if !save {
+		logger.Logger.Infof("已取消选择，%s 未修改", RepoPathFile)
+		return 0
+	}
+	selected, hidden := keepHiddenSelections(selected, preselected, items)
+	if hidden > 0 {
+		logger.Logger.Warnf("%s 中有 %d 个仓库未在选择列表中显示（被过滤或源平台已不存在），已保留", RepoPathFile, hidden)
+	}
+	if err := WriteSelectedRepos(selected); err != nil {
+		logger.Logger.Errorf("%s", err)
+		return 1
+	}
+	logger.Logger.Infof("已将选择的 %d 个仓库写入 %s", len(selected), RepoPathFile)
+	if !m.opts.AllowSelectRepos {
+		logger.Logger.Warnf("migrate.allow_select_repos 未开启，迁移时不会按 %s 过滤仓库", RepoPathFile)
+	}
+	return 0
+}
+
+// keepHiddenSelections 将已选中但未在选择列表中显示的仓库追加到选择结果中，返回合并后的结果及保留的仓库数
+func keepHiddenSelections(selected []string, preselected map[string]bool, items []picker.Item) ([]string, int) {
+	shown := make(map[string]bool, len(items))
+	for _, item := range items {
+		shown[item.Path] = true
+	}
+	var hidden []string
+	for repoPath := range preselected {
+		if !shown[repoPath] {
+			hidden = append(hidden, repoPath)
+		}
+	}
+	sort.Strings(hidden)
+	return append(selected, hidden...), len(hidden)
+}
+
+// pickerItems 生成仓库选择列表，按仓库所属的源平台子组分组，没有子组的仓库不分组
+func pickerItems(depotList []vcs.VCS) []picker.Item {
+	items := make([]picker.Item, 0, len(depotList))
+	for _, depot := range depotList {
+		repoPath := depot.GetRepoPath()
+		group := ""
+		if subGroup := depot.GetSubGroup(); subGroup != nil {
+			group = subGroup.Name
+		}
+		metadata := depot.GetMetadata()
+		items = append(items, picker.Item{
+			Path:     repoPath,
+			Group:    group,
+			SizeKB:   metadata.SizeKB,
+			PushedAt: metadata.PushedAt,
+		})
+	}
+	return items
+}
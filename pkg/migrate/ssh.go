@@ -0,0 +1,110 @@
+package migrate
+
+import (
+	"ccrctl/pkg/git"
+	"ccrctl/pkg/vcs"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultSSHKeyFile 未配置 source.ssh_private_key 时读取的私钥文件，位于当前工作目录
+const DefaultSSHKeyFile = "ssh.key"
+
+// setupSSH 为本次运行准备 SSH 环境：私钥加载到临时目录，通过 GIT_SSH_COMMAND 生效，不修改用户 ~/.ssh 目录
+// 未开启 migrate.ssh 与 cnb.ssh 时返回 nil
+func (m *Migrator) setupSSH(depotList []vcs.VCS) (*git.SSHEnv, error) {
+	sourceSSH := m.opts.Vcs.SSH
+	cnbSSH := m.opts.CnbSSH
+	if !sourceSSH && !cnbSSH {
+		return nil, nil
+	}
+
+	var opts git.SSHOptions
+	var urls []string
+	if sourceSSH {
+		content, err := readSSHKey(m.opts.SourceSSHPrivateKey, DefaultSSHKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		opts.Keys = append(opts.Keys, git.SSHKey{Name: "source", Content: content, Passphrase: m.opts.SourceSSHPassphrase})
+		for _, depot := range depotList {
+			urls = append(urls, depot.GetCloneUrl())
+		}
+	}
+	if cnbSSH {
+		// 未单独配置 CNB 私钥时复用源平台私钥
+		if value := m.opts.CnbSSHPrivateKey; value != "" || !sourceSSH {
+			content, err := readSSHKey(value, "")
+			if err != nil {
+				return nil, err
+			}
+			opts.Keys = append(opts.Keys, git.SSHKey{Name: "cnb", Content: content, Passphrase: m.opts.CnbSSHPassphrase})
+		}
+	}
+
+	// 源平台与 CNB 分别配置 known_hosts，未配置的一方首次信任
+	sourceKnownHosts, err := readFileOrValue(m.opts.SourceSSHKnownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("读取 source.ssh_known_hosts 失败: %w", err)
+	}
+	cnbKnownHosts, err := readFileOrValue(m.opts.CnbSSHKnownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("读取 cnb.ssh_known_hosts 失败: %w", err)
+	}
+	if sourceKnownHosts == "" {
+		opts.Hosts = git.SSHHostsFromURLs(urls)
+	}
+	if host := m.target.SSHHost(); cnbSSH && cnbKnownHosts == "" && host != "" && !containsHost(opts.Hosts, host) {
+		opts.Hosts = append(opts.Hosts, host)
+	}
+	opts.KnownHosts = strings.TrimSpace(sourceKnownHosts + "\n" + cnbKnownHosts)
+	return git.SetupSSH(opts)
+}
+
+func containsHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
+
+// readSSHKey 读取私钥，value 可以是私钥内容或私钥文件路径，为空时读取 defaultFile
+func readSSHKey(value, defaultFile string) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		if defaultFile == "" {
+			return "", fmt.Errorf("未配置SSH私钥")
+		}
+		if _, err := os.Stat(defaultFile); os.IsNotExist(err) {
+			return "", fmt.Errorf("SSH私钥文件 %s 不存在于当前目录", defaultFile)
+		}
+		value = defaultFile
+	}
+	content, err := readFileOrValue(value)
+	if err != nil {
+		return "", fmt.Errorf("读取SSH私钥文件失败: %v", err)
+	}
+	if !strings.Contains(content, "PRIVATE KEY") {
+		return "", fmt.Errorf("SSH私钥格式错误")
+	}
+	return content, nil
+}
+
+// readFileOrValue value 为已存在的文件路径时返回文件内容，否则原样返回
+func readFileOrValue(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.Contains(value, "\n") || strings.Contains(value, `\n`) {
+		return value, nil
+	}
+	st, err := os.Stat(value)
+	if err != nil || st.IsDir() {
+		return value, nil
+	}
+	data, err := os.ReadFile(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,117 @@
+package migrate
+
+import (
+	"ccrctl/pkg/report"
+	"ccrctl/pkg/vcs"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const StateFileName = "migrate-state.json"
+
+// ReasonPermission retry-failed --reason 中 auth 的别名
+const ReasonPermission = "permission"
+
+// State 迁移状态，每次迁移结束后写入，retry-failed 据此只重新迁移失败的仓库
+type State struct {
+	SourcePlatform string      `json:"source_platform"`
+	SourceURL      string      `json:"source_url,omitempty"`
+	FinishedAt     time.Time   `json:"finished_at"`
+	Repos          []StateRepo `json:"repos"`
+}
+
+// StateRepo 单个仓库的迁移结果及恢复仓库所需的快照
+type StateRepo struct {
+	Result report.Repo `json:"result"`
+	// Repo 仓库快照，源平台未找到的仓库为空
+	Repo *vcs.Snapshot `json:"repo,omitempty"`
+}
+
+// StateFilePath 迁移状态文件路径
+func (m *Migrator) StateFilePath() string {
+	return filepath.Join(m.reportDir, StateFileName)
+}
+
+// ReadState 读取迁移状态文件
+func ReadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移状态文件失败: %v", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("解析迁移状态文件 %s 失败: %v", path, err)
+	}
+	return &state, nil
+}
+
+// Write 写入迁移状态文件
+func (s *State) Write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入迁移状态文件失败: %v", err)
+	}
+	return nil
+}
+
+// Failed 返回迁移失败的仓库，reasons 不为空时只返回失败原因在其中的仓库
+func (s *State) Failed(reasons []string) []StateRepo {
+	reasonSet := make(map[string]bool, len(reasons))
+	for _, reason := range reasons {
+		reason = strings.TrimSpace(reason)
+		if reason == ReasonPermission {
+			reason = report.ReasonAuth
+		}
+		if reason != "" {
+			reasonSet[reason] = true
+		}
+	}
+	var failed []StateRepo
+	for _, repo := range s.Repos {
+		if repo.Result.Status != report.StatusFailed {
+			continue
+		}
+		if len(reasonSet) > 0 && !reasonSet[repo.Result.Reason] {
+			continue
+		}
+		failed = append(failed, repo)
+	}
+	return failed
+}
+
+// Merge 用本次迁移结果替换同名仓库的记录，返回合并后的状态
+func (s *State) Merge(current *State) *State {
+	merged := *current
+	seen := make(map[string]bool, len(current.Repos))
+	for _, repo := range current.Repos {
+		seen[repo.Result.Source] = true
+	}
+	merged.Repos = append([]StateRepo(nil), current.Repos...)
+	for _, repo := range s.Repos {
+		if !seen[repo.Result.Source] {
+			merged.Repos = append(merged.Repos, repo)
+		}
+	}
+	sort.Slice(merged.Repos, func(i, j int) bool {
+		return merged.Repos[i].Result.Source < merged.Repos[j].Result.Source
+	})
+	return &merged
+}
+
+// ValidReason 判断 retry-failed --reason 取值是否合法
+func ValidReason(reason string) bool {
+	switch reason {
+	case ReasonPermission, report.ReasonAuth, report.ReasonNotFound, report.ReasonNetwork,
+		report.ReasonSizeLimit, report.ReasonLFS, report.ReasonRejected, report.ReasonOther:
+		return true
+	}
+	return false
+}
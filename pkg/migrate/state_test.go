@@ -0,0 +1,100 @@
+package migrate
+
+import (
+	"ccrctl/pkg/report"
+	"ccrctl/pkg/vcs"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTestState() *State {
+	return &State{
+		SourcePlatform: "github",
+		Repos: []StateRepo{
+			{Result: report.Repo{Source: "org/a", Status: report.StatusSuccess}},
+			{Result: report.Repo{Source: "org/b", Status: report.StatusFailed, Reason: report.ReasonNetwork}},
+			{Result: report.Repo{Source: "org/c", Status: report.StatusFailed, Reason: report.ReasonAuth}},
+			{Result: report.Repo{Source: "org/d", Status: report.StatusSkipped}},
+			{Result: report.Repo{Source: "org/e", Status: report.StatusFailed, Reason: report.ReasonLFS}},
+		},
+	}
+}
+
+func TestStateFailed(t *testing.T) {
+	tests := []struct {
+		name    string
+		reasons []string
+		want    []string
+	}{
+		{name: "不过滤", reasons: nil, want: []string{"org/b", "org/c", "org/e"}},
+		{name: "按原因过滤", reasons: []string{"network", "lfs"}, want: []string{"org/b", "org/e"}},
+		{name: "permission为auth别名", reasons: []string{"permission"}, want: []string{"org/c"}},
+		{name: "无匹配", reasons: []string{"size_limit"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, repo := range newTestState().Failed(tt.reasons) {
+				got = append(got, repo.Result.Source)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Failed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateMerge(t *testing.T) {
+	current := &State{
+		SourcePlatform: "github",
+		Repos: []StateRepo{
+			{Result: report.Repo{Source: "org/b", Status: report.StatusSuccess}},
+			{Result: report.Repo{Source: "org/c", Status: report.StatusFailed, Reason: report.ReasonAuth}},
+		},
+	}
+	merged := newTestState().Merge(current)
+	want := map[string]report.Status{
+		"org/a": report.StatusSuccess,
+		"org/b": report.StatusSuccess,
+		"org/c": report.StatusFailed,
+		"org/d": report.StatusSkipped,
+		"org/e": report.StatusFailed,
+	}
+	if len(merged.Repos) != len(want) {
+		t.Fatalf("合并后仓库数 = %d, want %d", len(merged.Repos), len(want))
+	}
+	for _, repo := range merged.Repos {
+		if repo.Result.Status != want[repo.Result.Source] {
+			t.Errorf("%s 状态 = %s, want %s", repo.Result.Source, repo.Result.Status, want[repo.Result.Source])
+		}
+	}
+}
+
+func TestStateReadWrite(t *testing.T) {
+	snapshot, err := vcs.NewSnapshot("local", &vcs.LocalVcs{RepoPath: "org/b", RepoName: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := newTestState()
+	state.Repos[1].Repo = snapshot
+	path := filepath.Join(t.TempDir(), StateFileName)
+	if err := state.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := got.Failed([]string{"network"})
+	if len(failed) != 1 || failed[0].Repo == nil {
+		t.Fatalf("Failed() = %+v, 期望包含仓库快照", failed)
+	}
+	if !json.Valid(failed[0].Repo.Repo) {
+		t.Errorf("仓库快照格式错误: %s", failed[0].Repo.Repo)
+	}
+	if _, err := ReadState(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("状态文件不存在时期望返回错误")
+	}
+}
@@ -0,0 +1,111 @@
+package migrate
+
+import (
+	"ccrctl/pkg/git"
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/vcs"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	SubmoduleReportName = "submodule-report.log"
+
+	// 子模块地址改写模式
+	SubmoduleRewriteOff    = "off"    // 不处理
+	SubmoduleRewriteReport = "report" // 只在报告中列出需要修改的子模块地址
+	SubmoduleRewriteBranch = "branch" // 在单独的分支上提交修改后的 .gitmodules，原分支不变
+)
+
+// buildSubmoduleURLMap 根据本次迁移的仓库列表生成 源仓库地址 → CNB 仓库地址 映射
+func (m *Migrator) buildSubmoduleURLMap(depotList []vcs.VCS) map[string]string {
+	urlMap := make(map[string]string, len(depotList))
+	for _, depot := range depotList {
+		key := git.NormalizeRepoURL(depot.GetCloneUrl())
+		if key == "" {
+			continue
+		}
+		cnbRepoPath, _ := m.cnbRepoPathAndGroup(depot)
+		urlMap[key] = strings.TrimSuffix(m.opts.CnbURL, "/") + cnbRepoPath + ".git"
+	}
+	return urlMap
+}
+
+// submoduleRewriteBranchRef 改写后的 .gitmodules 所在分支，如 refs/heads/main → refs/heads/cnb-submodule/main
+func (m *Migrator) submoduleRewriteBranchRef(branchRef string) string {
+	return "refs/heads/" + m.opts.SubmoduleBranchPrefix + strings.TrimPrefix(branchRef, "refs/heads/")
+}
+
+// isSubmoduleRewriteBranch 判断是否为本工具创建的子模块改写分支
+func (m *Migrator) isSubmoduleRewriteBranch(ref string) bool {
+	if m.opts.SubmoduleRewrite != SubmoduleRewriteBranch {
+		return false
+	}
+	return strings.HasPrefix(ref, "refs/heads/"+m.opts.SubmoduleBranchPrefix)
+}
+
+// rewriteSubmodules 检查各分支最新提交的 .gitmodules，将指向本次迁移仓库的子模块地址改写为 CNB 地址
+// report 模式只记录需要的修改；branch 模式在单独分支上提交修改，不改写任何已有提交
+func (m *Migrator) rewriteSubmodules(repoPath, pushURL string) error {
+	mode := m.opts.SubmoduleRewrite
+	if mode == "" || mode == SubmoduleRewriteOff || len(m.submoduleURLMap) == 0 {
+		return nil
+	}
+	dir := m.repoDir(repoPath)
+	branches, err := git.SelectedBranches(dir, m.opts.RefFilter)
+	if err != nil {
+		return err
+	}
+	for _, branch := range branches {
+		if m.isSubmoduleRewriteBranch(branch) {
+			continue
+		}
+		submodules, err := git.ListSubmodules(dir, branch)
+		if err != nil {
+			return err
+		}
+		rewrites := git.PlanSubmoduleRewrites(submodules, m.submoduleURLMap)
+		if len(rewrites) == 0 {
+			continue
+		}
+		rewriteBranch := ""
+		if mode == SubmoduleRewriteBranch {
+			rewriteBranch = m.submoduleRewriteBranchRef(branch)
+			message := fmt.Sprintf("chore: 子模块地址改写为 CNB 仓库地址\n\n基于 %s", strings.TrimPrefix(branch, "refs/heads/"))
+			commit, err := git.CommitGitmodulesRewrite(dir, branch, rewrites, message)
+			if err != nil {
+				return err
+			}
+			if output, err := git.PushCommit(dir, pushURL, commit, rewriteBranch, m.gitEnv); err != nil {
+				return fmt.Errorf("%s 推送子模块改写分支 %s 失败: %s\n %s", repoPath, rewriteBranch, err, output)
+			}
+			logger.Repo(repoPath).Infof("%s 分支 %s 的 %d 个子模块地址已改写并推送到 %s", repoPath, branch, len(rewrites), rewriteBranch)
+		} else {
+			logger.Repo(repoPath).Warnf("%s 分支 %s 有 %d 个子模块指向源平台，详见 %s", repoPath, branch, len(rewrites), SubmoduleReportName)
+		}
+		if err := m.writeSubmoduleReport(repoPath, branch, rewriteBranch, rewrites); err != nil {
+			logger.Repo(repoPath).Warnf("%s 写入子模块报告失败: %s", repoPath, err)
+		}
+	}
+	return nil
+}
+
+// writeSubmoduleReport 将子模块地址改写追加写入报告文件
+func (m *Migrator) writeSubmoduleReport(repoPath, branch, rewriteBranch string, rewrites []git.SubmoduleRewrite) error {
+	return m.appendReport(SubmoduleReportName, formatSubmoduleReport(repoPath, branch, rewriteBranch, rewrites, time.Now()))
+}
+
+// formatSubmoduleReport 生成单个分支的子模块报告内容，rewriteBranch 为空表示只报告未提交
+func formatSubmoduleReport(repoPath, branch, rewriteBranch string, rewrites []git.SubmoduleRewrite, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s 分支: %s 子模块数: %d", now.Format("2006-01-02 15:04:05"), repoPath, branch, len(rewrites))
+	if rewriteBranch != "" {
+		fmt.Fprintf(&b, " 已提交到: %s", rewriteBranch)
+	}
+	b.WriteString("\n")
+	for _, r := range rewrites {
+		fmt.Fprintf(&b, "  %s: %s -> %s\n", r.Name, r.OldURL, r.NewURL)
+	}
+	return b.String()
+}
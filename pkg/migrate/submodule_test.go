@@ -0,0 +1,46 @@
+package migrate
+
+import (
+	"ccrctl/pkg/git"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFormatSubmoduleReport(t *testing.T) {
+	rewrites := []git.SubmoduleRewrite{
+		{Name: "lib", OldURL: "https://gitlab.com/group/lib.git", NewURL: "https://cnb.cool/org/group/lib.git"},
+		{Name: "vendor/sdk", OldURL: "git@gitlab.com:group/sdk.git", NewURL: "https://cnb.cool/org/group/sdk.git"},
+	}
+	tests := []struct {
+		name          string
+		rewriteBranch string
+		want          []string
+	}{
+		{
+			name:          "branch",
+			rewriteBranch: "refs/heads/cnb-submodule/main",
+			want: []string{
+				"group/repo 分支: refs/heads/main 子模块数: 2 已提交到: refs/heads/cnb-submodule/main",
+				"  lib: https://gitlab.com/group/lib.git -> https://cnb.cool/org/group/lib.git",
+				"  vendor/sdk: git@gitlab.com:group/sdk.git -> https://cnb.cool/org/group/sdk.git",
+			},
+		},
+		{
+			name: "report",
+			want: []string{
+				"group/repo 分支: refs/heads/main 子模块数: 2",
+				"  lib: https://gitlab.com/group/lib.git -> https://cnb.cool/org/group/lib.git",
+				"  vendor/sdk: git@gitlab.com:group/sdk.git -> https://cnb.cool/org/group/sdk.git",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := reportLines(t, formatSubmoduleReport("group/repo", "refs/heads/main", tt.rewriteBranch, rewrites, time.Now()))
+			if !reflect.DeepEqual(lines, tt.want) {
+				t.Errorf("formatSubmoduleReport() = %q, 期望 %q", lines, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package naming
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MappingFileRule 目标路径由映射文件决定时 Map 返回的规则
+const MappingFileRule = "target_mapping_file"
+
+// RuleSeparator 重写规则中正则表达式与替换内容的分隔符，如 ^team-(\w+)/(.*)$ => $1/$2
+const RuleSeparator = "=>"
+
+// Source 源仓库信息，同时作为 migrate.target_template 模板的数据
+type Source struct {
+	// Path 源仓库完整路径，如 group/sub/repo
+	Path string
+	// SubGroup 源仓库所属子组织，如 group/sub
+	SubGroup string
+	// Name 源仓库名称
+	Name string
+}
+
+// Target CNB 目标仓库，路径相对于根组织
+type Target struct {
+	SubGroup string
+	Name     string
+}
+
+// ParseTarget 解析根组织下的仓库路径，如 team/backend/api
+func ParseTarget(targetPath string) Target {
+	targetPath = cleanPath(targetPath)
+	dir, name := path.Split(targetPath)
+	return Target{SubGroup: strings.TrimSuffix(dir, "/"), Name: name}
+}
+
+// Path 返回根组织下的仓库路径
+func (t Target) Path() string {
+	if t.SubGroup == "" {
+		return t.Name
+	}
+	return t.SubGroup + "/" + t.Name
+}
+
+type rewriteRule struct {
+	raw         string
+	re          *regexp.Regexp
+	replacement string
+}
+
+// Mapper 按映射文件、重写规则、模板的顺序计算目标仓库路径
+type Mapper struct {
+	mapping map[string]string
+	rules   []rewriteRule
+	tmpl    *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"base":       path.Base,
+}
+
+// New 解析 migrate.target_rules、migrate.target_template 及 migrate.target_mapping_file，忽略空白项
+func New(rules []string, tmpl, mappingFile string) (*Mapper, error) {
+	m := &Mapper{}
+	for _, rule := range rules {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+		r, err := parseRewriteRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("migrate.target_rules %v", err)
+		}
+		m.rules = append(m.rules, r)
+	}
+	if tmpl = strings.TrimSpace(tmpl); tmpl != "" {
+		t, err := template.New("target").Funcs(templateFuncs).Option("missingkey=error").Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("migrate.target_template 格式错误: %v", err)
+		}
+		m.tmpl = t
+	}
+	if mappingFile = strings.TrimSpace(mappingFile); mappingFile != "" {
+		mapping, err := ReadMappingFile(mappingFile)
+		if err != nil {
+			return nil, err
+		}
+		m.mapping = mapping
+	}
+	return m, nil
+}
+
+func parseRewriteRule(rule string) (rewriteRule, error) {
+	expr, replacement, ok := strings.Cut(rule, RuleSeparator)
+	expr, replacement = strings.TrimSpace(expr), strings.TrimSpace(replacement)
+	if !ok || expr == "" || replacement == "" {
+		return rewriteRule{}, fmt.Errorf("规则 %s 格式错误，应为 <正则表达式> %s <目标路径>", rule, RuleSeparator)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return rewriteRule{}, fmt.Errorf("规则 %s 格式错误: %v", rule, err)
+	}
+	return rewriteRule{raw: strings.TrimSpace(rule), re: re, replacement: replacement}, nil
+}
+
+// ReadMappingFile 读取源仓库路径 → 目标仓库路径的映射文件
+// .yaml/.yml 文件为 源仓库路径: 目标仓库路径 的映射，其他文件按 CSV 解析，每行为 源仓库路径,目标仓库路径，# 开头的行为注释
+func ReadMappingFile(file string) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取 migrate.target_mapping_file 失败: %v", err)
+	}
+	defer f.Close()
+	var mapping map[string]string
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		mapping, err = parseYAMLMapping(f)
+	default:
+		mapping, err = parseCSVMapping(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析 migrate.target_mapping_file %s 失败: %v", file, err)
+	}
+	return mapping, nil
+}
+
+func parseYAMLMapping(r io.Reader) (map[string]string, error) {
+	raw := make(map[string]string)
+	if err := yaml.NewDecoder(r).Decode(&raw); err != nil && err != io.EOF {
+		return nil, err
+	}
+	mapping := make(map[string]string, len(raw))
+	for source, target := range raw {
+		if err := addMapping(mapping, source, target); err != nil {
+			return nil, err
+		}
+	}
+	return mapping, nil
+}
+
+func parseCSVMapping(r io.Reader) (map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	mapping := make(map[string]string, len(records))
+	for i, record := range records {
+		// 允许首行为 source,target 表头
+		if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "source") && strings.EqualFold(strings.TrimSpace(record[1]), "target") {
+			continue
+		}
+		if err := addMapping(mapping, record[0], record[1]); err != nil {
+			return nil, err
+		}
+	}
+	return mapping, nil
+}
+
+func addMapping(mapping map[string]string, source, target string) error {
+	source, target = cleanPath(source), cleanPath(target)
+	if source == "" || target == "" {
+		return fmt.Errorf("源仓库路径和目标仓库路径不能为空")
+	}
+	if _, exists := mapping[source]; exists {
+		return fmt.Errorf("源仓库 %s 重复配置", source)
+	}
+	mapping[source] = target
+	return nil
+}
+
+// Active 是否配置了映射文件、重写规则或模板
+func (m *Mapper) Active() bool {
+	return len(m.mapping) > 0 || len(m.rules) > 0 || m.tmpl != nil
+}
+
+// Map 计算源仓库在根组织下的目标路径，返回目标路径及命中的规则，未命中任何规则时目标路径为空
+func (m *Mapper) Map(src Source) (string, string, error) {
+	if target, ok := m.mapping[src.Path]; ok {
+		return target, MappingFileRule, nil
+	}
+	for _, rule := range m.rules {
+		match := rule.re.FindStringSubmatchIndex(src.Path)
+		if match == nil {
+			continue
+		}
+		target := rule.re.ExpandString(nil, rule.replacement, src.Path, match)
+		return cleanPath(string(target)), "target_rules: " + rule.raw, nil
+	}
+	if m.tmpl != nil {
+		var b strings.Builder
+		if err := m.tmpl.Execute(&b, src); err != nil {
+			return "", "", fmt.Errorf("%s 执行 migrate.target_template 失败: %v", src.Path, err)
+		}
+		return cleanPath(b.String()), "target_template", nil
+	}
+	return "", "", nil
+}
+
+// cleanPath 去掉首尾空白及斜杠，合并重复的斜杠，如模板中子组织为空时生成的 /repo
+func cleanPath(p string) string {
+	var parts []string
+	for _, part := range strings.Split(strings.TrimSpace(p), "/") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,131 @@
+package naming
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMapperMap(t *testing.T) {
+	dir := t.TempDir()
+	mappingFile := filepath.Join(dir, "mapping.csv")
+	content := "source,target\n# 注释\nteam-a/legacy,archive/legacy\n"
+	if err := os.WriteFile(mappingFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := New([]string{`^team-(\w+)/(.*)$ => teams/$1/$2`, " "}, "{{.SubGroup | lower}}/{{.Name}}", mappingFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		src      Source
+		wantPath string
+		wantRule string
+	}{
+		{src: Source{Path: "team-a/legacy", SubGroup: "team-a", Name: "legacy"}, wantPath: "archive/legacy", wantRule: "target_mapping_file"},
+		{src: Source{Path: "team-a/api", SubGroup: "team-a", Name: "api"}, wantPath: "teams/a/api", wantRule: `target_rules: ^team-(\w+)/(.*)$ => teams/$1/$2`},
+		{src: Source{Path: "Ops/Tools/cli", SubGroup: "Ops/Tools", Name: "cli"}, wantPath: "ops/tools/cli", wantRule: "target_template"},
+		{src: Source{Path: "cli", Name: "cli"}, wantPath: "cli", wantRule: "target_template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src.Path, func(t *testing.T) {
+			gotPath, gotRule, err := m.Map(tt.src)
+			if err != nil {
+				t.Fatalf("Map() error = %v", err)
+			}
+			if gotPath != tt.wantPath || gotRule != tt.wantRule {
+				t.Errorf("Map() = %q, %q, want %q, %q", gotPath, gotRule, tt.wantPath, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestMapperNotActive(t *testing.T) {
+	m, err := New(nil, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Active() {
+		t.Error("Active() = true, want false")
+	}
+	if path, rule, err := m.Map(Source{Path: "team/api"}); path != "" || rule != "" || err != nil {
+		t.Errorf("Map() = %q, %q, %v", path, rule, err)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []string
+		tmpl  string
+	}{
+		{name: "缺少分隔符", rules: []string{"^team/(.*)$"}},
+		{name: "缺少目标路径", rules: []string{"^team/(.*)$ =>"}},
+		{name: "正则错误", rules: []string{"^team/(.*$ => $1"}},
+		{name: "模板错误", tmpl: "{{.Name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.rules, tt.tmpl, ""); err == nil {
+				t.Error("New() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestReadMappingFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		file    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{file: "mapping.yaml", content: "team/api: /backend/api/\n\"team/web\": frontend/web\n", want: map[string]string{"team/api": "backend/api", "team/web": "frontend/web"}},
+		{file: "mapping.csv", content: "team/api, backend/api\n", want: map[string]string{"team/api": "backend/api"}},
+		{file: "empty.yml", content: "", want: map[string]string{}},
+		{file: "duplicate.csv", content: "team/api,a\nteam/api,b\n", wantErr: true},
+		{file: "columns.csv", content: "team/api\n", wantErr: true},
+		{file: "empty-target.csv", content: "team/api,\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			file := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadMappingFile(file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadMappingFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ReadMappingFile() = %v, want %v", got, tt.want)
+			}
+			for source, target := range tt.want {
+				if got[source] != target {
+					t.Errorf("ReadMappingFile()[%s] = %q, want %q", source, got[source], target)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		path string
+		want Target
+	}{
+		{path: "team/backend/api", want: Target{SubGroup: "team/backend", Name: "api"}},
+		{path: "/api/", want: Target{Name: "api"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := ParseTarget(tt.path); got != tt.want {
+				t.Errorf("ParseTarget() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package picker
+
+import "unicode/utf8"
+
+type key int
+
+const (
+	keyRune key = iota
+	keyUp
+	keyDown
+	keyPgUp
+	keyPgDn
+	keySpace
+	keyEnter
+	keyEsc
+	keyBackspace
+	keyCtrlC
+)
+
+// input 一次按键，key 为 keyRune 时 r 为输入的字符
+type input struct {
+	key key
+	r   rune
+}
+
+// escapeSequences 终端方向键、翻页键的转义序列
+var escapeSequences = map[string]key{
+	"\x1b[A":  keyUp,
+	"\x1bOA":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1bOB":  keyDown,
+	"\x1b[5~": keyPgUp,
+	"\x1b[6~": keyPgDn,
+}
+
+// parseInput 解析一次从终端读取的字节，可能包含多个按键，无法识别的转义序列忽略
+func parseInput(b []byte) []input {
+	var inputs []input
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			if len(b) == 1 {
+				inputs = append(inputs, input{key: keyEsc})
+				return inputs
+			}
+			matched := false
+			for seq, k := range escapeSequences {
+				if len(b) >= len(seq) && string(b[:len(seq)]) == seq {
+					inputs = append(inputs, input{key: k})
+					b = b[len(seq):]
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				// 未识别的转义序列：跳过至序列结束字符
+				i := 1
+				if i < len(b) && (b[i] == '[' || b[i] == 'O') {
+					i++
+					for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+						i++
+					}
+					i++
+				}
+				if i > len(b) {
+					i = len(b)
+				}
+				if i == 1 {
+					inputs = append(inputs, input{key: keyEsc})
+				}
+				b = b[i:]
+			}
+			continue
+		}
+		switch b[0] {
+		case 0x03:
+			inputs = append(inputs, input{key: keyCtrlC})
+		case '\r', '\n':
+			inputs = append(inputs, input{key: keyEnter})
+		case ' ':
+			inputs = append(inputs, input{key: keySpace})
+		case 0x7f, 0x08:
+			inputs = append(inputs, input{key: keyBackspace})
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r >= 0x20 && r != utf8.RuneError {
+				inputs = append(inputs, input{key: keyRune, r: r})
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return inputs
+}
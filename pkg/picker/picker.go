@@ -0,0 +1,331 @@
+package picker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Item 可选择的仓库
+type Item struct {
+	Path string
+	// Group 仓库所属子组织，用于分组显示及整组选择
+	Group string
+	// SizeKB 仓库大小，0 表示未知
+	SizeKB int64
+	// PushedAt 最后推送时间，零值表示未知
+	PushedAt time.Time
+}
+
+// row 列表中的一行，item 为 -1 时为分组标题行
+type row struct {
+	group string
+	item  int
+}
+
+// model 仓库选择界面状态，不依赖终端，便于测试
+type model struct {
+	items     []Item
+	selected  map[int]bool
+	query     string
+	searching bool
+	cursor    int
+	offset    int
+	rows      []row
+}
+
+func newModel(items []Item, preselected map[string]bool) *model {
+	items = append([]Item(nil), items...)
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Group != items[j].Group {
+			return items[i].Group < items[j].Group
+		}
+		return items[i].Path < items[j].Path
+	})
+	m := &model{items: items, selected: make(map[int]bool)}
+	for i, item := range items {
+		if preselected[item.Path] {
+			m.selected[i] = true
+		}
+	}
+	m.refresh()
+	return m
+}
+
+// refresh 按搜索条件重新生成列表
+func (m *model) refresh() {
+	m.rows = m.rows[:0]
+	query := strings.ToLower(m.query)
+	lastGroup := ""
+	for i, item := range m.items {
+		if query != "" && !strings.Contains(strings.ToLower(item.Path), query) {
+			continue
+		}
+		if len(m.rows) == 0 || item.Group != lastGroup {
+			m.rows = append(m.rows, row{group: item.Group, item: -1})
+			lastGroup = item.Group
+		}
+		m.rows = append(m.rows, row{group: item.Group, item: i})
+	}
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// Selected 返回已选择的仓库路径，按列表顺序排列
+func (m *model) Selected() []string {
+	var paths []string
+	for i, item := range m.items {
+		if m.selected[i] {
+			paths = append(paths, item.Path)
+		}
+	}
+	return paths
+}
+
+// groupItems 返回当前列表中属于 group 的仓库
+func (m *model) groupItems(group string) []int {
+	var items []int
+	for _, r := range m.rows {
+		if r.item >= 0 && r.group == group {
+			items = append(items, r.item)
+		}
+	}
+	return items
+}
+
+// visibleItems 返回当前列表中的所有仓库
+func (m *model) visibleItems() []int {
+	var items []int
+	for _, r := range m.rows {
+		if r.item >= 0 {
+			items = append(items, r.item)
+		}
+	}
+	return items
+}
+
+// toggleAll 全部已选择时取消选择，否则全部选择
+func (m *model) toggleAll(items []int) {
+	all := true
+	for _, i := range items {
+		if !m.selected[i] {
+			all = false
+			break
+		}
+	}
+	for _, i := range items {
+		if all {
+			delete(m.selected, i)
+		} else {
+			m.selected[i] = true
+		}
+	}
+}
+
+func (m *model) toggle() {
+	if len(m.rows) == 0 {
+		return
+	}
+	r := m.rows[m.cursor]
+	if r.item < 0 {
+		m.toggleAll(m.groupItems(r.group))
+		return
+	}
+	if m.selected[r.item] {
+		delete(m.selected, r.item)
+	} else {
+		m.selected[r.item] = true
+	}
+}
+
+func (m *model) move(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// handle 处理按键，pageSize 为列表可显示的行数
+// 返回值: 是否结束选择, 是否保存选择结果
+func (m *model) handle(in input, pageSize int) (done, save bool) {
+	switch in.key {
+	case keyCtrlC:
+		return true, false
+	case keyUp:
+		m.move(-1)
+		return false, false
+	case keyDown:
+		m.move(1)
+		return false, false
+	case keyPgUp:
+		m.move(-pageSize)
+		return false, false
+	case keyPgDn:
+		m.move(pageSize)
+		return false, false
+	}
+
+	if m.searching {
+		switch in.key {
+		case keyRune:
+			m.query += string(in.r)
+			m.refresh()
+		case keySpace:
+			m.query += " "
+			m.refresh()
+		case keyBackspace:
+			if runes := []rune(m.query); len(runes) > 0 {
+				m.query = string(runes[:len(runes)-1])
+				m.refresh()
+			}
+		case keyEnter:
+			m.searching = false
+		case keyEsc:
+			m.searching = false
+			m.query = ""
+			m.refresh()
+		}
+		return false, false
+	}
+
+	switch in.key {
+	case keySpace:
+		m.toggle()
+	case keyEnter:
+		return true, true
+	case keyEsc:
+		if m.query == "" {
+			return true, false
+		}
+		m.query = ""
+		m.refresh()
+	case keyRune:
+		switch in.r {
+		case 'k':
+			m.move(-1)
+		case 'j':
+			m.move(1)
+		case 'g':
+			m.cursor = 0
+		case 'G':
+			m.move(len(m.rows))
+		case 'a':
+			m.toggleAll(m.visibleItems())
+		case '/':
+			m.searching = true
+		case 'q':
+			return true, false
+		}
+	}
+	return false, false
+}
+
+const helpLine = "↑/↓ 移动  空格 选择（分组行选择整组）  a 全选可见仓库  / 搜索  回车 保存  q 退出"
+
+// render 生成界面内容，height 为终端行数
+func (m *model) render(width, height int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "选择需要迁移的仓库  已选 %d/%d\r\n", len(m.selected), len(m.items))
+	switch {
+	case m.searching:
+		fmt.Fprintf(&b, "搜索: %s_\r\n", m.query)
+	case m.query != "":
+		fmt.Fprintf(&b, "搜索: %s（Esc 清除）\r\n", m.query)
+	default:
+		b.WriteString("\r\n")
+	}
+
+	pageSize := listHeight(height)
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+pageSize {
+		m.offset = m.cursor - pageSize + 1
+	}
+	pathWidth := 0
+	for _, r := range m.rows {
+		if r.item >= 0 && len(m.items[r.item].Path) > pathWidth {
+			pathWidth = len(m.items[r.item].Path)
+		}
+	}
+	for i := m.offset; i < len(m.rows) && i < m.offset+pageSize; i++ {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		line := cursor + m.renderRow(m.rows[i], pathWidth)
+		if runes := []rune(line); width > 0 && len(runes) > width {
+			line = string(runes[:width])
+		}
+		b.WriteString(line + "\x1b[K\r\n")
+	}
+	if len(m.rows) == 0 {
+		b.WriteString("  没有匹配的仓库\x1b[K\r\n")
+	}
+	b.WriteString("\x1b[J\r\n" + helpLine)
+	return b.String()
+}
+
+func (m *model) renderRow(r row, pathWidth int) string {
+	if r.item < 0 {
+		items := m.groupItems(r.group)
+		count := 0
+		for _, i := range items {
+			if m.selected[i] {
+				count++
+			}
+		}
+		mark := "[ ]"
+		if count == len(items) {
+			mark = "[x]"
+		} else if count > 0 {
+			mark = "[-]"
+		}
+		group := r.group
+		if group == "" {
+			group = "/"
+		}
+		return fmt.Sprintf("%s %s (%d/%d)", mark, group, count, len(items))
+	}
+	item := m.items[r.item]
+	mark := "[ ]"
+	if m.selected[r.item] {
+		mark = "[x]"
+	}
+	pushedAt := "-"
+	if !item.PushedAt.IsZero() {
+		pushedAt = item.PushedAt.Local().Format(time.DateOnly)
+	}
+	return fmt.Sprintf("    %s %-*s  %9s  %s", mark, pathWidth, item.Path, formatSize(item.SizeKB), pushedAt)
+}
+
+// listHeight 列表可显示的行数，去掉标题、搜索栏及帮助信息
+func listHeight(height int) int {
+	if height <= 5 {
+		return 1
+	}
+	return height - 4
+}
+
+// formatSize 格式化仓库大小，未知时返回 -
+func formatSize(kb int64) string {
+	if kb <= 0 {
+		return "-"
+	}
+	size := float64(kb)
+	for _, unit := range []string{"KB", "MB", "GB"} {
+		if size < 1024 {
+			return fmt.Sprintf("%.1f %s", size, unit)
+		}
+		size /= 1024
+	}
+	return fmt.Sprintf("%.1f TB", size)
+}
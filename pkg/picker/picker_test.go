@@ -0,0 +1,124 @@
+package picker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testItems() []Item {
+	return []Item{
+		{Path: "team-b/web", Group: "team-b"},
+		{Path: "team-a/api", Group: "team-a", SizeKB: 2048},
+		{Path: "team-a/worker", Group: "team-a"},
+	}
+}
+
+func press(m *model, inputs ...input) (done, save bool) {
+	for _, in := range inputs {
+		if done, save = m.handle(in, 10); done {
+			return
+		}
+	}
+	return
+}
+
+func runes(s string) []input {
+	var inputs []input
+	for _, r := range s {
+		inputs = append(inputs, input{key: keyRune, r: r})
+	}
+	return inputs
+}
+
+func TestModelGroupSelect(t *testing.T) {
+	m := newModel(testItems(), map[string]bool{"team-b/web": true})
+	// 第一行为 team-a 分组标题，选择整组
+	press(m, input{key: keySpace})
+	if got, want := m.Selected(), []string{"team-a/api", "team-a/worker", "team-b/web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Selected() = %v, want %v", got, want)
+	}
+	// 再次选择分组标题取消整组
+	press(m, input{key: keySpace})
+	if got, want := m.Selected(), []string{"team-b/web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Selected() = %v, want %v", got, want)
+	}
+	// 移动到 team-a/worker 单独选择
+	press(m, input{key: keyDown}, input{key: keyDown}, input{key: keySpace})
+	if got, want := m.Selected(), []string{"team-a/worker", "team-b/web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Selected() = %v, want %v", got, want)
+	}
+}
+
+func TestModelSearch(t *testing.T) {
+	m := newModel(testItems(), nil)
+	inputs := append([]input{{key: keyRune, r: '/'}}, runes("API")...)
+	inputs = append(inputs, input{key: keyEnter}, input{key: keyRune, r: 'a'})
+	if done, _ := press(m, inputs...); done {
+		t.Fatal("搜索时回车不应结束选择")
+	}
+	if got, want := m.Selected(), []string{"team-a/api"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("全选可见仓库后 Selected() = %v, want %v", got, want)
+	}
+	if len(m.rows) != 2 {
+		t.Errorf("搜索后列表行数 = %d, want 2", len(m.rows))
+	}
+	// Esc 清除搜索条件
+	press(m, input{key: keyEsc})
+	if m.query != "" || len(m.rows) != 5 {
+		t.Errorf("清除搜索后 query = %q, 行数 = %d", m.query, len(m.rows))
+	}
+}
+
+func TestModelQuit(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       input
+		wantSave bool
+	}{
+		{name: "回车保存", in: input{key: keyEnter}, wantSave: true},
+		{name: "q退出", in: input{key: keyRune, r: 'q'}, wantSave: false},
+		{name: "Ctrl-C退出", in: input{key: keyCtrlC}, wantSave: false},
+		{name: "Esc退出", in: input{key: keyEsc}, wantSave: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done, save := press(newModel(testItems(), nil), tt.in)
+			if !done || save != tt.wantSave {
+				t.Errorf("handle() = %v, %v, want true, %v", done, save, tt.wantSave)
+			}
+		})
+	}
+}
+
+func TestModelRender(t *testing.T) {
+	m := newModel(testItems(), map[string]bool{"team-a/api": true})
+	out := m.render(120, 24)
+	for _, want := range []string{"已选 1/3", "[-] team-a (1/2)", "[x] team-a/api", "2.0 MB", "[ ] team-b/web"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("界面缺少 %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestParseInput(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []input
+	}{
+		{name: "方向键", in: "\x1b[A\x1b[B", want: []input{{key: keyUp}, {key: keyDown}}},
+		{name: "翻页", in: "\x1b[5~\x1b[6~", want: []input{{key: keyPgUp}, {key: keyPgDn}}},
+		{name: "Esc", in: "\x1b", want: []input{{key: keyEsc}}},
+		{name: "未识别的转义序列", in: "\x1b[1;5Cx", want: []input{{key: keyRune, r: 'x'}}},
+		{name: "字符", in: "a 中\r", want: []input{{key: keyRune, r: 'a'}, {key: keySpace}, {key: keyRune, r: '中'}, {key: keyEnter}}},
+		{name: "控制键", in: "\x7f\x03", want: []input{{key: keyBackspace}, {key: keyCtrlC}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseInput([]byte(tt.in)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseInput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// ErrNotTerminal 标准输入或输出不是终端
+var ErrNotTerminal = errors.New("仓库选择需要在交互式终端中运行")
+
+// Run 在终端中显示仓库列表供用户选择，preselected 中的仓库默认选中
+// 返回值: 已选择的仓库路径, 用户是否确认保存, 错误信息
+func Run(items []Item, preselected map[string]bool) ([]string, bool, error) {
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	state, err := unix.IoctlGetTermios(in, ioctlReadTermios)
+	if err != nil {
+		return nil, false, ErrNotTerminal
+	}
+	if _, err := unix.IoctlGetTermios(out, ioctlReadTermios); err != nil {
+		return nil, false, ErrNotTerminal
+	}
+	if err := makeRaw(in, *state); err != nil {
+		return nil, false, fmt.Errorf("设置终端模式失败: %v", err)
+	}
+	// 切换到备用屏幕并隐藏光标，结束时恢复
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[?1049l")
+		_ = unix.IoctlSetTermios(in, ioctlWriteTermios, state)
+	}()
+
+	m := newModel(items, preselected)
+	buf := make([]byte, 64)
+	for {
+		width, height := terminalSize(out)
+		fmt.Fprint(os.Stdout, "\x1b[H"+m.render(width, height))
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, false, nil
+			}
+			return nil, false, err
+		}
+		for _, in := range parseInput(buf[:n]) {
+			if done, save := m.handle(in, listHeight(height)); done {
+				return m.Selected(), save, nil
+			}
+		}
+	}
+}
+
+// makeRaw 关闭回显及行缓冲，保留输出处理
+func makeRaw(fd int, termios unix.Termios) error {
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB
+	termios.Cflag |= unix.CS8
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	return unix.IoctlSetTermios(fd, ioctlWriteTermios, &termios)
+}
+
+// terminalSize 终端列数及行数，获取失败时返回 80x24
+func terminalSize(fd int) (int, int) {
+	ws, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 80, 24
+	}
+	return int(ws.Col), int(ws.Row)
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"bytes":    formatBytes,
+	"duration": formatDuration,
+	"time":     func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>ccrctl 迁移报告</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; margin: 24px; color: #222; }
+h1 { font-size: 22px; }
+.meta td { padding: 2px 16px 2px 0; color: #555; }
+.summary { display: flex; gap: 12px; margin: 16px 0; }
+.summary div { padding: 10px 16px; border-radius: 6px; background: #f4f5f7; min-width: 80px; }
+.summary b { display: block; font-size: 22px; }
+table.repos { border-collapse: collapse; width: 100%; font-size: 13px; }
+table.repos th, table.repos td { border: 1px solid #e1e4e8; padding: 6px 8px; text-align: left; vertical-align: top; }
+table.repos th { background: #f6f8fa; }
+.success { color: #1a7f37; }
+.skipped { color: #9a6700; }
+.failed { color: #cf222e; }
+pre { margin: 0; white-space: pre-wrap; word-break: break-all; max-height: 160px; overflow: auto; }
+</style>
+</head>
+<body>
+<h1>ccrctl 迁移报告</h1>
+<table class="meta">
+<tr><td>源平台</td><td>{{.SourcePlatform}} {{.SourceURL}}</td></tr>
+{{if .CnbURL}}<tr><td>CNB</td><td>{{.CnbURL}}</td></tr>{{end}}
+<tr><td>开始时间</td><td>{{time .StartedAt}}</td></tr>
+<tr><td>结束时间</td><td>{{time .FinishedAt}}</td></tr>
+</table>
+<div class="summary">
+<div>仓库总数<b>{{.Summary.Total}}</b></div>
+<div class="success">成功<b>{{.Summary.Success}}</b></div>
+<div class="skipped">忽略<b>{{.Summary.Skipped}}</b></div>
+<div class="failed">失败<b>{{.Summary.Failed}}</b></div>
+</div>
+<table class="repos">
+<tr><th>源仓库</th><th>CNB 仓库</th><th>状态</th><th>耗时</th><th>大小</th><th>LFS 对象</th><th>Release</th><th>阶段</th><th>原因</th><th>详情</th></tr>
+{{range .Repos}}<tr>
+<td>{{.Source}}</td><td{{if .TargetRule}} title="{{.TargetRule}}"{{end}}>{{.Target}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{duration .Duration}}</td>
+<td>{{bytes .Bytes}}</td><td>{{.LFSObjects}}</td><td>{{.Releases}}</td><td>{{.Phase}}</td><td>{{.Reason}}</td>
+<td>{{if .Message}}<pre>{{.Message}}</pre>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteHTML 输出独立的 HTML 格式报告，不依赖外部资源
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,97 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit 输出 JUnit XML 格式报告，每个仓库为一个用例，便于 CNB 流水线展示测试报告
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:      "ccrctl." + r.SourcePlatform,
+		Tests:     r.Summary.Total,
+		Failures:  r.Summary.Failed,
+		Skipped:   r.Summary.Skipped,
+		Time:      seconds(r.FinishedAt.Sub(r.StartedAt).Seconds()),
+		Timestamp: r.StartedAt.Format("2006-01-02T15:04:05"),
+	}
+	for _, repo := range r.Repos {
+		c := junitTestCase{
+			Name:      repo.Source,
+			ClassName: "ccrctl." + r.SourcePlatform,
+			Time:      seconds(repo.Duration.Seconds()),
+		}
+		if repo.Target != "" {
+			c.SystemOut = fmt.Sprintf("CNB 仓库: %s", repo.Target)
+		}
+		switch repo.Status {
+		case StatusFailed:
+			c.Failure = &junitMessage{Message: repo.Message, Type: repo.Reason, Text: failureText(repo)}
+		case StatusSkipped:
+			c.Skipped = &junitMessage{Message: repo.Message}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	suites := junitTestSuites{
+		Name:     "ccrctl",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func failureText(repo Repo) string {
+	return fmt.Sprintf("阶段: %s\n原因: %s\n%s", repo.Phase, repo.Reason, repo.Message)
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
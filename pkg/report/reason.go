@@ -0,0 +1,81 @@
+package report
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 失败原因分类
+const (
+	ReasonAuth      = "auth"
+	ReasonNotFound  = "not_found"
+	ReasonNetwork   = "network"
+	ReasonSizeLimit = "size_limit"
+	ReasonLFS       = "lfs"
+	ReasonRejected  = "rejected"
+	ReasonOther     = "other"
+)
+
+type reasonPattern struct {
+	reason   string
+	patterns []string
+}
+
+// priorityPatterns 优先于 HTTP 状态码匹配，如 LFS 对象 404 归为 lfs 而不是 not_found
+var priorityPatterns = []reasonPattern{
+	{ReasonSizeLimit, []string{"exceeded limit", "too large", "大文件", "大小超过"}},
+	{ReasonLFS, []string{"lfs"}},
+}
+
+var reasonPatterns = []reasonPattern{
+	{ReasonAuth, []string{"authentication failed", "could not read username", "invalid username or password",
+		"permission denied", "access denied", "unauthorized", "forbidden", "无权限", "没有权限"}},
+	{ReasonNotFound, []string{"not found", "does not exist", "未找到", "不存在"}},
+	{ReasonRejected, []string{"non-fast-forward", "[rejected]", "fetch first", "pre-receive hook declined"}},
+	{ReasonNetwork, []string{"timeout", "timed out", "connection reset", "connection refused", "could not resolve host",
+		"early eof", "rpc failed", "unexpected disconnect", "tls handshake", "no route to host", "broken pipe"}},
+}
+
+// statusCodeRegex 匹配 API 及 git 错误信息中的 HTTP 状态码
+var statusCodeRegex = regexp.MustCompile(`(?:status code:?|returned error:|状态码:?)\s*(\d{3})\b`)
+
+// Categorize 根据错误信息归类失败原因，无法归类时返回 other
+// paths 为错误信息中可能出现的仓库路径，匹配前移除，避免路径中的 lfs、too-large 等字样影响归类
+func Categorize(message string, paths ...string) string {
+	message = strings.ToLower(message)
+	for _, p := range paths {
+		if p != "" {
+			message = strings.ReplaceAll(message, strings.ToLower(p), "")
+		}
+	}
+	if reason := matchPatterns(message, priorityPatterns); reason != "" {
+		return reason
+	}
+	if match := statusCodeRegex.FindStringSubmatch(message); match != nil {
+		switch {
+		case match[1] == "401" || match[1] == "403":
+			return ReasonAuth
+		case match[1] == "404":
+			return ReasonNotFound
+		case match[1] == "413":
+			return ReasonSizeLimit
+		case match[1][0] == '5':
+			return ReasonNetwork
+		}
+	}
+	if reason := matchPatterns(message, reasonPatterns); reason != "" {
+		return reason
+	}
+	return ReasonOther
+}
+
+func matchPatterns(message string, patterns []reasonPattern) string {
+	for _, item := range patterns {
+		for _, pattern := range item.patterns {
+			if strings.Contains(message, pattern) {
+				return item.reason
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 报告文件名，写入日志目录
+const (
+	JSONFileName  = "migrate-report.json"
+	JUnitFileName = "migrate-report.xml"
+	HTMLFileName  = "migrate-report.html"
+)
+
+// 报告格式
+const (
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+	FormatHTML  = "html"
+)
+
+// Status 仓库迁移结果
+type Status string
+
+const (
+	StatusSuccess Status = "success"
+	StatusSkipped Status = "skipped"
+	StatusFailed  Status = "failed"
+)
+
+// Repo 单个仓库的迁移结果
+type Repo struct {
+	// Source 源仓库路径
+	Source string `json:"source"`
+	// Target CNB 仓库路径，只下载模式时为空
+	Target string `json:"target,omitempty"`
+	// TargetRule 决定 CNB 仓库路径的规则，如 target_rules: ...、collision_strategy: suffix，按 organization_mapping_level 计算时为空
+	TargetRule string `json:"target_rule,omitempty"`
+	Status     Status `json:"status"`
+	// Duration 迁移耗时
+	Duration time.Duration `json:"-"`
+	// Bytes 克隆到本地的仓库大小
+	Bytes int64 `json:"bytes"`
+	// LFSObjects 克隆到本地的 LFS 对象数
+	LFSObjects int `json:"lfs_objects"`
+	// Releases 迁移成功的 release 数
+	Releases int `json:"releases"`
+	// Phase 失败时所处的迁移阶段
+	Phase string `json:"phase,omitempty"`
+	// Reason 失败原因分类，见 Categorize
+	Reason string `json:"reason,omitempty"`
+	// Message 失败或忽略的原因
+	Message string `json:"message,omitempty"`
+}
+
+// MarshalJSON 耗时以秒输出
+func (r Repo) MarshalJSON() ([]byte, error) {
+	type repo Repo
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(struct {
+		repo
+		DurationSeconds float64 `json:"duration_seconds"`
+	}{repo(r), r.Duration.Seconds()})
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), err
+}
+
+// Summary 迁移结果汇总
+type Summary struct {
+	Total   int `json:"total"`
+	Success int `json:"success"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+// Report 一次迁移的报告
+type Report struct {
+	SourcePlatform string    `json:"source_platform"`
+	SourceURL      string    `json:"source_url"`
+	CnbURL         string    `json:"cnb_url,omitempty"`
+	DownloadOnly   bool      `json:"download_only"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	Summary        Summary   `json:"summary"`
+	Repos          []Repo    `json:"repos"`
+}
+
+// New 生成报告，仓库按源仓库路径排序并统计汇总
+func New(report Report) *Report {
+	sort.Slice(report.Repos, func(i, j int) bool { return report.Repos[i].Source < report.Repos[j].Source })
+	report.Summary = Summary{Total: len(report.Repos)}
+	for _, repo := range report.Repos {
+		switch repo.Status {
+		case StatusSuccess:
+			report.Summary.Success++
+		case StatusSkipped:
+			report.Summary.Skipped++
+		default:
+			report.Summary.Failed++
+		}
+	}
+	return &report
+}
+
+// WriteJSON 输出 JSON 格式报告
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
+
+// WriteFiles 按格式将报告写入 dir，返回写入的文件路径
+func (r *Report) WriteFiles(dir string, formats []string) ([]string, error) {
+	var files []string
+	for _, format := range formats {
+		var name string
+		var write func(io.Writer) error
+		switch strings.TrimSpace(format) {
+		case FormatJSON:
+			name, write = JSONFileName, r.WriteJSON
+		case FormatJUnit:
+			name, write = JUnitFileName, r.WriteJUnit
+		case FormatHTML:
+			name, write = HTMLFileName, r.WriteHTML
+		default:
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if err := writeFile(path, write); err != nil {
+			return files, fmt.Errorf("写入迁移报告 %s 失败: %v", path, err)
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
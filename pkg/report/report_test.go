@@ -0,0 +1,142 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReport() *Report {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return New(Report{
+		SourcePlatform: "github",
+		SourceURL:      "https://github.com",
+		CnbURL:         "https://cnb.cool",
+		StartedAt:      start,
+		FinishedAt:     start.Add(time.Minute),
+		Repos: []Repo{
+			{Source: "org/c", Status: StatusFailed, Phase: "push", Reason: ReasonRejected, Message: "! [rejected] main -> main (fetch first) <b>"},
+			{Source: "org/a", Target: "root/org/a", Status: StatusSuccess, Duration: 1500 * time.Millisecond, Bytes: 2048, LFSObjects: 3, Releases: 1},
+			{Source: "org/b", Status: StatusSkipped, Message: "已迁移"},
+		},
+	})
+}
+
+// TestNew 测试仓库排序及汇总
+func TestNew(t *testing.T) {
+	r := testReport()
+	if r.Summary != (Summary{Total: 3, Success: 1, Skipped: 1, Failed: 1}) {
+		t.Errorf("Summary = %+v", r.Summary)
+	}
+	if r.Repos[0].Source != "org/a" || r.Repos[2].Source != "org/c" {
+		t.Errorf("仓库应按源仓库路径排序: %v", r.Repos)
+	}
+}
+
+// TestWriteJSON 测试 JSON 报告字段
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport().WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Summary Summary `json:"summary"`
+		Repos   []map[string]interface{}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("JSON 解析失败: %v\n%s", err, buf.String())
+	}
+	repo := got.Repos[0]
+	expected := map[string]interface{}{
+		"source": "org/a", "target": "root/org/a", "status": "success",
+		"duration_seconds": 1.5, "bytes": float64(2048), "lfs_objects": float64(3), "releases": float64(1),
+	}
+	for key, value := range expected {
+		if repo[key] != value {
+			t.Errorf("%s = %v, 期望 %v", key, repo[key], value)
+		}
+	}
+	if !strings.Contains(buf.String(), "<b>") {
+		t.Errorf("JSON 报告不应转义 HTML 字符: %s", buf.String())
+	}
+}
+
+// TestWriteJUnit 测试 JUnit 报告中失败、忽略用例
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport().WriteJUnit(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("XML 解析失败: %v\n%s", err, buf.String())
+	}
+	if got.Tests != 3 || got.Failures != 1 || got.Skipped != 1 || len(got.Suites) != 1 {
+		t.Fatalf("testsuites = %+v", got)
+	}
+	cases := got.Suites[0].Cases
+	if cases[0].Failure != nil || cases[0].Time != "1.500" {
+		t.Errorf("成功用例 = %+v", cases[0])
+	}
+	if cases[1].Skipped == nil || cases[1].Skipped.Message != "已迁移" {
+		t.Errorf("忽略用例 = %+v", cases[1])
+	}
+	if cases[2].Failure == nil || cases[2].Failure.Type != ReasonRejected {
+		t.Errorf("失败用例 = %+v", cases[2])
+	}
+}
+
+// TestWriteFiles 测试按格式写入报告文件，HTML 报告转义错误信息
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	files, err := testReport().WriteFiles(dir, []string{FormatJSON, FormatJUnit, FormatHTML})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{filepath.Join(dir, JSONFileName), filepath.Join(dir, JUnitFileName), filepath.Join(dir, HTMLFileName)}
+	if strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Errorf("WriteFiles() = %v, 期望 %v", files, expected)
+	}
+	html, err := os.ReadFile(filepath.Join(dir, HTMLFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"root/org/a", "2.0KiB", "&lt;b&gt;"} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("HTML 报告缺少 %s", want)
+		}
+	}
+}
+
+// TestCategorize 测试失败原因分类
+func TestCategorize(t *testing.T) {
+	tests := map[string]string{
+		"fatal: Authentication failed for 'https://github.com/o/r.git/'":                  ReasonAuth,
+		"request failed with status code 403: forbidden":                                  ReasonAuth,
+		"The requested URL returned error: 404":                                           ReasonNotFound,
+		"fatal: repository 'https://github.com/o/r.git/' not found":                       ReasonNotFound,
+		"Error downloading object: LFS: Repository or object not found":                   ReasonLFS,
+		"remote: error: file a.bin is 300 MB; this exceeded limit":                        ReasonSizeLimit,
+		"! [rejected]        main -> main (fetch first)":                                  ReasonRejected,
+		"fatal: unable to access: Could not resolve host: github.com":                     ReasonNetwork,
+		"request failed with status code 502: bad gateway":                                ReasonNetwork,
+		"error: RPC failed; curl 18 transfer closed with outstanding read data remaining": ReasonNetwork,
+		"unknown error": ReasonOther,
+	}
+	for message, expected := range tests {
+		if got := Categorize(message); got != expected {
+			t.Errorf("Categorize(%q) = %s, 期望 %s", message, got, expected)
+		}
+	}
+
+	// 仓库路径中的关键字不参与归类
+	message := "fatal: Authentication failed for 'https://github.com/group/lfs-too-large.git/'"
+	if got := Categorize(message, "group/lfs-too-large", "org/group/lfs-too-large"); got != ReasonAuth {
+		t.Errorf("Categorize(%q) = %s, 期望 %s", message, got, ReasonAuth)
+	}
+}
@@ -0,0 +1,133 @@
+package selector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RegexPrefix 正则表达式规则前缀，未带前缀的规则为通配符
+const RegexPrefix = "re:"
+
+// Rule 仓库路径匹配规则
+// 通配符规则中 * 匹配除 / 以外的任意字符，** 可匹配包括 / 在内的任意字符，? 匹配除 / 以外的单个字符，
+// 需匹配完整仓库路径；re: 前缀的规则为正则表达式，未使用 ^$ 时匹配仓库路径的任意部分
+type Rule struct {
+	raw string
+	re  *regexp.Regexp
+}
+
+// ParseRule 解析仓库路径匹配规则
+func ParseRule(rule string) (Rule, error) {
+	rule = strings.TrimSpace(rule)
+	if rule == "" {
+		return Rule{}, fmt.Errorf("规则不能为空")
+	}
+	expr := globToRegexp(rule)
+	if strings.HasPrefix(rule, RegexPrefix) {
+		expr = strings.TrimPrefix(rule, RegexPrefix)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return Rule{}, fmt.Errorf("规则 %s 格式错误: %v", rule, err)
+	}
+	return Rule{raw: rule, re: re}, nil
+}
+
+// Match 判断仓库路径是否匹配规则
+func (r Rule) Match(repoPath string) bool {
+	return r.re.MatchString(repoPath)
+}
+
+// String 返回配置中的原始规则
+func (r Rule) String() string {
+	return r.raw
+}
+
+// globToRegexp 将通配符转换为匹配完整路径的正则表达式
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// Filter 按 source.include / source.exclude 选择仓库，Exclude 优先于 Include，
+// 配置了 Include 时只保留匹配任一 Include 规则的仓库
+type Filter struct {
+	Include []Rule
+	Exclude []Rule
+}
+
+// New 解析 include / exclude 规则，忽略空白项
+func New(include, exclude []string) (*Filter, error) {
+	f := &Filter{}
+	var err error
+	if f.Include, err = parseRules("source.include", include); err != nil {
+		return nil, err
+	}
+	if f.Exclude, err = parseRules("source.exclude", exclude); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func parseRules(key string, rules []string) ([]Rule, error) {
+	var result []Rule
+	for _, rule := range rules {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+		r, err := ParseRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("%s %v", key, err)
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// Active 是否配置了规则
+func (f *Filter) Active() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0
+}
+
+// Decision 仓库的选择结果
+type Decision struct {
+	Selected bool
+	// Rule 决定选择结果的规则，如 "include: team/*"、"exclude: re:-archive$"，未配置 include 且未被排除时为空
+	Rule string
+}
+
+// Match 按规则判断仓库是否需要迁移
+func (f *Filter) Match(repoPath string) Decision {
+	for _, rule := range f.Exclude {
+		if rule.Match(repoPath) {
+			return Decision{Selected: false, Rule: "exclude: " + rule.String()}
+		}
+	}
+	if len(f.Include) == 0 {
+		return Decision{Selected: true}
+	}
+	for _, rule := range f.Include {
+		if rule.Match(repoPath) {
+			return Decision{Selected: true, Rule: "include: " + rule.String()}
+		}
+	}
+	return Decision{Selected: false, Rule: "include: 未匹配任何规则"}
+}
@@ -0,0 +1,63 @@
+package selector
+
+import "testing"
+
+func TestRuleMatch(t *testing.T) {
+	tests := []struct {
+		rule     string
+		repoPath string
+		want     bool
+	}{
+		{rule: "team/*/backend-*", repoPath: "team/a/backend-api", want: true},
+		{rule: "team/*/backend-*", repoPath: "team/a/b/backend-api", want: false},
+		{rule: "team/*/backend-*", repoPath: "team/a/frontend", want: false},
+		{rule: "team/**", repoPath: "team/a/b/c", want: true},
+		{rule: "team/repo-?", repoPath: "team/repo-1", want: true},
+		{rule: "team/repo-?", repoPath: "team/repo-10", want: false},
+		{rule: "team/repo.x", repoPath: "team/repoax", want: false},
+		{rule: "re:-archive$", repoPath: "team/old-archive", want: true},
+		{rule: "re:^team/(api|web)$", repoPath: "team/api", want: true},
+		{rule: "re:^team/(api|web)$", repoPath: "team/apis", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule+" "+tt.repoPath, func(t *testing.T) {
+			rule, err := ParseRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRule() error = %v", err)
+			}
+			if got := rule.Match(tt.repoPath); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	f, err := New([]string{"team/**", " "}, []string{"re:-archive$", "team/tmp-*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		repoPath string
+		want     Decision
+	}{
+		{repoPath: "team/a/api", want: Decision{Selected: true, Rule: "include: team/**"}},
+		{repoPath: "team/old-archive", want: Decision{Selected: false, Rule: "exclude: re:-archive$"}},
+		{repoPath: "team/tmp-1", want: Decision{Selected: false, Rule: "exclude: team/tmp-*"}},
+		{repoPath: "other/api", want: Decision{Selected: false, Rule: "include: 未匹配任何规则"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repoPath, func(t *testing.T) {
+			if got := f.Match(tt.repoPath); got != tt.want {
+				t.Errorf("Match() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := (&Filter{}).Match("any/repo"); !got.Selected || got.Rule != "" {
+		t.Errorf("未配置规则时 Match() = %+v", got)
+	}
+	if _, err := New(nil, []string{"re:("}); err == nil {
+		t.Error("正则表达式错误时期望返回错误")
+	}
+}
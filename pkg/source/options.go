@@ -0,0 +1,36 @@
+package source
+
+// Options 源平台地址及认证信息，由调用方从配置读取后传入 api 客户端及 vcs 实现
+type Options struct {
+	// Platform 源平台类型，与 source.platform 取值相同
+	Platform string
+	URL      string
+	// APIURL GitHub Enterprise Server、GitLab 等平台的 API 地址，为空时由 URL 推导
+	APIURL   string
+	Token    string
+	Username string
+	Password string
+	// Projects CODING 只迁移指定项目的仓库
+	Projects []string
+	// Repos common 平台迁移的仓库路径
+	Repos []string
+	// Group CNB、GitLab 只迁移该组织下的仓库
+	Group string
+	// OrganizationID 云效组织 ID
+	OrganizationID string
+	// Organizations、Users 只迁移指定组织、用户的仓库
+	Organizations []string
+	Users         []string
+	// Enterprise Gitee 企业版企业路径
+	Enterprise string
+	// AK、SK、Region 华为云认证信息及区域
+	AK     string
+	SK     string
+	Region string
+	// GitlabProjectsOwned GitLab 只迁移 token 用户拥有的项目
+	GitlabProjectsOwned bool
+	// ExcludeGithubFork 不迁移 GitHub fork 的仓库
+	ExcludeGithubFork bool
+	// Dir local 平台待迁移仓库所在目录，为空时使用当前目录下的 source_git_dir
+	Dir string
+}
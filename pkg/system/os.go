@@ -7,6 +7,8 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync"
 	"syscall"
 )
 
@@ -48,28 +50,91 @@ func RunCommand(command, workDir string, args ...string) (string, error) {
 	return string(output), err
 }
 
-func ExecCommand(command, workDir string) (string, error) {
-	cmd := exec.Command("sh", "-c", command)
+// RunCommandWithEnv 执行命令，env 追加到当前进程的环境变量之后
+func RunCommandWithEnv(env []string, command, workDir string, args ...string) (string, error) {
+	cmd := exec.Command(command, args...)
 	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(), env...)
 	output, err := cmd.CombinedOutput()
 	return string(output), err
 }
 
-func HandleInterrupt(path string) {
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+// RunCommandWithInput 执行命令并通过标准输入传入 input
+func RunCommandWithInput(input, command, workDir string, args ...string) (string, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(input)
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
 
-	go func() {
-		<-ch
+// RunCommandOutput 执行命令并只返回标准输出，用于读取文件内容等不能混入标准错误输出的场景，失败时返回标准错误输出
+func RunCommandOutput(command, workDir string, args ...string) (string, string, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Dir = workDir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	return string(output), stderr.String(), err
+}
 
-		logger.Logger.Infof("收到中断信号，程序即将终止")
+func ExecCommand(command, workDir string) (string, error) {
+	return ExecCommandWithEnv(nil, command, workDir)
+}
 
-		err := os.RemoveAll(path)
-		if err != nil {
-			panic(err)
-		}
-		os.Exit(0)
-	}()
+// ExecCommandWithEnv 通过 sh -c 执行命令，env 追加到当前进程环境变量之后
+func ExecCommandWithEnv(env []string, command, workDir string) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = workDir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+var (
+	interruptMu    sync.Mutex
+	interruptOnce  sync.Once
+	interruptPaths = make(map[string]int)
+)
+
+// HandleInterrupt 登记收到中断信号时需要删除的目录，返回取消登记的函数
+// 收到 SIGINT、SIGTERM 时删除所有仍在登记中的目录后退出，多个迁移同时进行时互不覆盖
+func HandleInterrupt(path string) func() {
+	interruptMu.Lock()
+	interruptPaths[path]++
+	interruptMu.Unlock()
+
+	interruptOnce.Do(func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+
+		go func() {
+			<-ch
+
+			logger.Logger.Infof("收到中断信号，程序即将终止")
+
+			interruptMu.Lock()
+			for path := range interruptPaths {
+				if err := os.RemoveAll(path); err != nil {
+					logger.Logger.Errorf("%s 删除失败: %s", path, err)
+				}
+			}
+			os.Exit(0)
+		}()
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			interruptMu.Lock()
+			defer interruptMu.Unlock()
+			if interruptPaths[path]--; interruptPaths[path] <= 0 {
+				delete(interruptPaths, path)
+			}
+		})
+	}
 }
 
 func CreateDirIfNotExists(dirPath string) error {
@@ -43,3 +43,18 @@ func copyFile(src, dst string) error {
 	}
 	return nil
 }
+
+// DirSize 统计目录下所有文件的大小之和
+func DirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -29,6 +29,15 @@ func ConvertUrlWithAuth(url, username, password string) string {
 	return URL
 }
 
+// ConvertHTTPToSSHURL 将仓库 HTTP 地址转换为 SSH 地址
+// 示例转换: http://example.com/group/repo.git -> git@example.com:group/repo.git
+func ConvertHTTPToSSHURL(httpURL string) string {
+	sshURL := strings.Replace(httpURL, "http://", "", 1)
+	sshURL = strings.Replace(sshURL, "https://", "", 1)
+	sshURL = strings.Replace(sshURL, "/", ":", 1)
+	return "git@" + sshURL
+}
+
 func RemoveHostFromURL(rawURL string) (string, error) {
 	// 解析原始 URL
 	parsedURL, err := url.Parse(rawURL)
@@ -341,3 +350,14 @@ func DeduplicateStringSlice(slice []string) DeduplicationResult {
 
 	return result
 }
+
+// ConvertToApiURL 将 CNB 地址转换为 API 地址，如 https://cnb.cool 转换为 https://api.cnb.cool
+func ConvertToApiURL(baseUrl string) (apiUrl string) {
+	parts := strings.Split(baseUrl, "://")
+	if len(parts) == 2 {
+		apiUrl = parts[0] + "://" + "api." + parts[1]
+	} else {
+		apiUrl = baseUrl
+	}
+	return apiUrl
+}
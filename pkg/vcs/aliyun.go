@@ -2,14 +2,16 @@ package vcs
 
 import (
 	api "ccrctl/pkg/api/aliyun"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/git"
-	"ccrctl/pkg/util"
+	"ccrctl/pkg/source"
 	"strings"
 )
 
 type AliyunVcs struct {
+	options
+	Metadata
 	httpURL           string
+	sshURL            string
 	PathWithNamespace string
 	RepoName          string
 	RepoType          string
@@ -43,7 +45,7 @@ func (c *AliyunVcs) GetRepoType() string {
 }
 
 func (c *AliyunVcs) GetCloneUrl() string {
-	return util.ConvertUrlWithAuth(c.httpURL, c.GetUserName(), c.GetToken()) + ".git"
+	return c.cloneURL(c.httpURL+".git", c.sshURL, c.GetUserName(), c.GetToken())
 }
 
 func (c *AliyunVcs) GetUserName() string {
@@ -51,11 +53,11 @@ func (c *AliyunVcs) GetUserName() string {
 }
 
 func (c *AliyunVcs) GetToken() string {
-	return config.Cfg.GetString("source.token")
+	return c.opts.Source.Token
 }
 
-func (c *AliyunVcs) Clone() error {
-	err := git.Clone(c.GetCloneUrl(), c.GetRepoPath(), allowIncompletePush)
+func (c *AliyunVcs) Clone(dir string, env []string) error {
+	err := git.Clone(c.GetCloneUrl(), dir, c.opts.AllowIncompletePush, env)
 	if err != nil {
 		return err
 	}
@@ -74,8 +76,8 @@ func (c *AliyunVcs) GetProjectID() string {
 	return ""
 }
 
-func newAliyunRepo() ([]VCS, error) {
-	repoList, err := api.GetAllRepositories()
+func newAliyunRepo(opts source.Options) ([]VCS, error) {
+	repoList, err := api.GetAllRepositories(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +88,13 @@ func aliyunCovertToVcs(repoList []api.Repository) []VCS {
 	var VCS []VCS
 	for _, repo := range repoList {
 		VCS = append(VCS, &AliyunVcs{
+			Metadata: Metadata{
+				Archived:   repo.Archived,
+				PushedAt:   parseMetadataTime(repo.LastActivityAt),
+				Visibility: repo.Visibility,
+			},
 			httpURL:           repo.WebUrl,
+			sshURL:            repo.SshUrlToRepo,
 			PathWithNamespace: repo.PathWithNamespace,
 			RepoName:          repo.Path,
 			RepoType:          Git,
@@ -106,5 +114,5 @@ func (c *AliyunVcs) GetRepoDescription() string {
 }
 
 func (c *AliyunVcs) ListRepos() ([]VCS, error) {
-	return newAliyunRepo()
+	return newAliyunRepo(c.opts.Source)
 }
@@ -2,8 +2,8 @@ package vcs
 
 import (
 	api "ccrctl/pkg/api/cnb"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/git"
+	"ccrctl/pkg/source"
 	"ccrctl/pkg/util"
 	"strconv"
 	"strings"
@@ -13,16 +13,15 @@ const (
 	CNBUserName = "cnb"
 )
 
-func newCnbRepo() ([]VCS, error) {
-	sourceGroup := config.Cfg.GetString("source.group")
-	if sourceGroup != "" {
-		repos, err := api.GetReposByGroup(config.Cfg.GetString("source.group"))
+func newCnbRepo(opts source.Options) ([]VCS, error) {
+	if opts.Group != "" {
+		repos, err := api.GetReposByGroup(opts, opts.Group)
 		if err != nil {
 			return nil, err
 		}
 		return CNBCovertToVcs(repos), nil
 	} else {
-		repos, err := api.GetUserRepos()
+		repos, err := api.GetUserRepos(opts)
 		if err != nil {
 			return nil, err
 		}
@@ -34,6 +33,11 @@ func CNBCovertToVcs(repoList []api.Repos) []VCS {
 	var VCS []VCS
 	for _, repo := range repoList {
 		VCS = append(VCS, &CNBVcs{
+			Metadata: Metadata{
+				Archived:   repo.Freeze,
+				PushedAt:   repo.UpdatedAt,
+				Visibility: visibility(isPrivate(repo.VisibilityLevel), false),
+			},
 			httpURL:  repo.WebUrl,
 			RepoPath: repo.Path,
 			RepoName: repo.Name,
@@ -55,6 +59,8 @@ func isPrivate(visibilityLevel string) bool {
 }
 
 type CNBVcs struct {
+	options
+	Metadata
 	httpURL  string
 	RepoPath string
 	RepoName string
@@ -102,11 +108,11 @@ func (c *CNBVcs) GetUserName() string {
 }
 
 func (c *CNBVcs) GetToken() string {
-	return config.Cfg.GetString("source.token")
+	return c.opts.Source.Token
 }
 
-func (c *CNBVcs) Clone() error {
-	err := git.Clone(c.GetCloneUrl(), c.GetRepoPath(), allowIncompletePush)
+func (c *CNBVcs) Clone(dir string, env []string) error {
+	err := git.Clone(c.GetCloneUrl(), dir, c.opts.AllowIncompletePush, env)
 	if err != nil {
 		return err
 	}
@@ -134,5 +140,5 @@ func (c *CNBVcs) GetRepoDescription() string {
 }
 
 func (c *CNBVcs) ListRepos() ([]VCS, error) {
-	return newCnbRepo()
+	return newCnbRepo(c.opts.Source)
 }
@@ -2,10 +2,10 @@ package vcs
 
 import (
 	"ccrctl/pkg/api/coding"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/git"
 	"ccrctl/pkg/http_client"
 	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
 	"ccrctl/pkg/util"
 	"fmt"
 	"strconv"
@@ -18,6 +18,8 @@ const (
 )
 
 type CodingVcs struct {
+	options
+	Metadata
 	httpURL      string
 	sshURL       string
 	RepoPath     string
@@ -46,7 +48,7 @@ func (c *CodingVcs) GetSubGroup() *SubGroup {
 
 	// 重试循环:最多尝试3次
 	for i, interval := range retryIntervals {
-		project, err = coding.GetProjectByName(config.Cfg.GetString("source.url"), c.GetToken(), c.SubGroupName)
+		project, err = coding.GetProjectByName(c.opts.Source.URL, c.GetToken(), c.SubGroupName)
 		if err == nil {
 			// 成功,跳出重试循环
 			break
@@ -74,11 +76,11 @@ func (c *CodingVcs) GetSubGroup() *SubGroup {
 	// 初始化描述和备注字段
 	var desc, remark string
 	// 如果配置了映射 Coding 项目描述,则使用项目的描述作为子组织描述
-	if config.Cfg.GetBool("migrate.map_coding_description") {
+	if c.opts.MapCodingDescription {
 		desc = strings.TrimSpace(project.Description)
 	}
 	// 如果配置了映射 Coding 项目显示名称,则使用项目的显示名称作为子组织别名
-	if config.Cfg.GetBool("migrate.map_coding_display_name") {
+	if c.opts.MapCodingDisplayName {
 		remark = strings.TrimSpace(project.DisplayName)
 	}
 	return &SubGroup{
@@ -93,8 +95,7 @@ func (c *CodingVcs) GetRepoType() string {
 }
 
 func (c *CodingVcs) GetCloneUrl() string {
-	ssh := config.Cfg.GetBool("migrate.ssh")
-	if ssh {
+	if c.opts.SSH {
 		return c.sshURL
 	}
 	return util.ConvertUrlWithAuth(c.httpURL, CodingUserName, c.GetToken())
@@ -104,8 +105,8 @@ func (c *CodingVcs) GetUserName() string {
 	return CodingUserName
 }
 
-func (c *CodingVcs) Clone() error {
-	err := git.Clone(c.GetCloneUrl(), c.GetRepoPath(), allowIncompletePush)
+func (c *CodingVcs) Clone(dir string, env []string) error {
+	err := git.Clone(c.GetCloneUrl(), dir, c.opts.AllowIncompletePush, env)
 	if err != nil {
 		return err
 	}
@@ -113,7 +114,7 @@ func (c *CodingVcs) Clone() error {
 }
 
 func (c *CodingVcs) GetToken() string {
-	return config.Cfg.GetString("source.token")
+	return c.opts.Source.Token
 }
 
 func (c *CodingVcs) GetRepoPrivate() bool {
@@ -121,7 +122,7 @@ func (c *CodingVcs) GetRepoPrivate() bool {
 }
 
 func (c *CodingVcs) GetReleases() []Releases {
-	codingReleases, err := coding.GetReleasesList(c.id)
+	codingReleases, err := coding.GetReleasesList(c.opts.Source.URL, c.GetToken(), c.id)
 	if err != nil {
 		logger.Logger.Errorf(err.Error())
 		panic(err)
@@ -166,9 +167,9 @@ func (c *CodingVcs) GetProjectID() string {
 	return strconv.Itoa(0)
 }
 
-func newCodingRepo() ([]VCS, error) {
+func newCodingRepo(opts source.Options) ([]VCS, error) {
 	// 不再需要传入 migrate.type，由 GetDepotList 内部根据配置自动判断
-	repoList, err := coding.GetDepotList()
+	repoList, err := coding.GetDepotList(opts.URL, opts.Token, opts.Projects)
 	if err != nil {
 		return nil, err
 	}
@@ -178,7 +179,12 @@ func newCodingRepo() ([]VCS, error) {
 func CodingCovertToVcs(repoList []coding.Depots) []VCS {
 	var VCS []VCS
 	for _, repo := range repoList {
+		metadata := Metadata{Visibility: visibility(!repo.IsShared, false)}
+		if repo.LastPushAt > 0 {
+			metadata.PushedAt = time.UnixMilli(int64(repo.LastPushAt))
+		}
 		VCS = append(VCS, &CodingVcs{
+			Metadata:     metadata,
 			httpURL:      repo.HttpsUrl,
 			sshURL:       repo.SshUrl,
 			RepoPath:     repo.GetRepoPath(),
@@ -237,5 +243,5 @@ func (c *CodingVcs) GetRepoDescription() string {
 }
 
 func (c *CodingVcs) ListRepos() ([]VCS, error) {
-	return newCodingRepo()
+	return newCodingRepo(c.opts.Source)
 }
@@ -2,7 +2,7 @@ package vcs
 
 import (
 	"ccrctl/pkg/api/coding"
-	"ccrctl/pkg/config"
+	"ccrctl/pkg/source"
 	"testing"
 )
 
@@ -42,8 +42,7 @@ func TestCodingVcs_GetCloneUrl(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// 设置配置
-			config.Cfg.Set("migrate.ssh", tt.sshMode)
-			config.Cfg.Set("source.token", "test-token")
+			codingVcs.setOptions(Options{SSH: tt.sshMode, Source: source.Options{Token: "test-token"}})
 
 			result := codingVcs.GetCloneUrl()
 
@@ -1,16 +1,16 @@
 package vcs
 
 import (
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/git"
 	"ccrctl/pkg/logger"
-	"ccrctl/pkg/util"
-	"fmt"
+	"ccrctl/pkg/source"
 	"strconv"
 	"strings"
 )
 
 type CommonVcs struct {
+	options
+	Metadata
 	httpURL  string
 	RepoPath string
 	RepoName string
@@ -43,29 +43,19 @@ func (c *CommonVcs) GetRepoType() string {
 }
 
 func (c *CommonVcs) GetCloneUrl() string {
-	ssh := config.Cfg.GetBool("migrate.ssh")
-	if ssh {
-		// 将 HTTP URL 转换为 SSH 格式
-		// 示例转换: http://example.com/group/repo.git -> git@example.com:group/repo.git
-		sshURL := strings.Replace(c.httpURL, "http://", "", 1)
-		sshURL = strings.Replace(sshURL, "https://", "", 1)
-		sshURL = strings.Replace(sshURL, "/", ":", 1)
-		return fmt.Sprintf("git@%s", sshURL)
-	}
-	// 默认保持 HTTP 协议克隆
-	return util.ConvertUrlWithAuth(c.httpURL, c.GetUserName(), c.GetToken())
+	return c.cloneURL(c.httpURL, "", c.GetUserName(), c.GetToken())
 }
 
 func (c *CommonVcs) GetUserName() string {
-	return config.Cfg.GetString("source.username")
+	return c.opts.Source.Username
 }
 
 func (c *CommonVcs) GetToken() string {
-	return config.Cfg.GetString("source.password")
+	return c.opts.Source.Password
 }
 
-func (c *CommonVcs) Clone() error {
-	err := git.Clone(c.GetCloneUrl(), c.GetRepoPath(), allowIncompletePush)
+func (c *CommonVcs) Clone(dir string, env []string) error {
+	err := git.Clone(c.GetCloneUrl(), dir, c.opts.AllowIncompletePush, env)
 	if err != nil {
 		return err
 	}
@@ -84,20 +74,19 @@ func (c *CommonVcs) GetProjectID() string {
 	return strconv.Itoa(0)
 }
 
-func newCommonRepo() ([]VCS, error) {
-	VCS, err := getRepos()
+func newCommonRepo(opts source.Options) ([]VCS, error) {
+	VCS, err := getRepos(opts)
 	if err != nil {
 		return nil, err
 	}
 	return VCS, nil
 }
 
-func getRepos() ([]VCS, error) {
+func getRepos(opts source.Options) ([]VCS, error) {
 	var VCS []VCS
-	repos := config.Cfg.GetStringSlice("source.repo")
-	for _, repo := range repos {
+	for _, repo := range opts.Repos {
 		VCS = append(VCS, &CommonVcs{
-			httpURL:  config.Cfg.GetString("source.url") + "/" + repo + ".git",
+			httpURL:  opts.URL + "/" + repo + ".git",
 			RepoPath: repo,
 			RepoName: strings.Split(repo, "/")[len(strings.Split(repo, "/"))-1],
 			RepoType: Git,
@@ -116,5 +105,5 @@ func (c *CommonVcs) GetRepoDescription() string {
 }
 
 func (c *CommonVcs) ListRepos() ([]VCS, error) {
-	return newCommonRepo()
+	return newCommonRepo(c.opts.Source)
 }
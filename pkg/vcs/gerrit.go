@@ -0,0 +1,160 @@
+package vcs
+
+import (
+	api "ccrctl/pkg/api/gerrit"
+	"ccrctl/pkg/git"
+	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// GerritVcs Gerrit VCS 实现，项目名称中的层级映射为 CNB 子组织
+type GerritVcs struct {
+	options
+	Metadata
+	httpURL  string
+	sshURL   string
+	RepoPath string
+	RepoName string
+	Desc     string
+}
+
+func (c *GerritVcs) GetRepoPath() string {
+	return c.RepoPath
+}
+
+func (c *GerritVcs) GetRepoName() string {
+	return c.RepoName
+}
+
+func (c *GerritVcs) GetSubGroup() *SubGroup {
+	subGroup := path.Dir(c.RepoPath)
+	if subGroup == "." {
+		subGroup = ""
+	}
+	return &SubGroup{
+		Name:   subGroup,
+		Desc:   "",
+		Remark: "",
+	}
+}
+
+func (c *GerritVcs) GetRepoType() string {
+	return Git
+}
+
+func (c *GerritVcs) GetCloneUrl() string {
+	return c.cloneURL(c.httpURL, c.sshURL, c.GetUserName(), c.GetToken())
+}
+
+func (c *GerritVcs) GetUserName() string {
+	return c.opts.Source.Username
+}
+
+// GetToken Gerrit 使用 HTTP 密码认证
+func (c *GerritVcs) GetToken() string {
+	return c.opts.Source.Password
+}
+
+// Clone 镜像克隆仓库到 dir，配置了 migrate.gerrit_changes_namespace 时将未合入变更的补丁集复制为分支
+func (c *GerritVcs) Clone(dir string, env []string) error {
+	err := git.Clone(c.GetCloneUrl(), dir, c.opts.AllowIncompletePush, env)
+	if err != nil {
+		return err
+	}
+	if c.opts.GerritChangesNamespace == "" {
+		return nil
+	}
+	changes, err := api.GetOpenChanges(c.opts.Source, c.RepoPath)
+	if err != nil {
+		return err
+	}
+	numbers := make([]int, 0, len(changes))
+	for _, change := range changes {
+		numbers = append(numbers, change.Number)
+	}
+	_, err = git.MirrorChangeRefs(dir, c.opts.GerritChangesNamespace, numbers)
+	return err
+}
+
+func (c *GerritVcs) GetRepoPrivate() bool {
+	return true
+}
+
+// GetReleases Gerrit 没有 Release
+func (c *GerritVcs) GetReleases() []Releases {
+	return nil
+}
+
+func (c *GerritVcs) GetProjectID() string {
+	return strconv.Itoa(0)
+}
+
+func (c *GerritVcs) GetReleaseAttachments(desc string, repoPath string, projectID string) ([]Attachment, error) {
+	return nil, nil
+}
+
+func (c *GerritVcs) GetRepoDescription() string {
+	return c.Desc
+}
+
+func (c *GerritVcs) ListRepos() ([]VCS, error) {
+	return newGerritRepo(c.opts.Source)
+}
+
+func newGerritRepo(opts source.Options) ([]VCS, error) {
+	projects, err := api.GetProjects(opts)
+	if err != nil {
+		return nil, err
+	}
+	sourceURL := strings.TrimSuffix(opts.URL, "/")
+	return GerritCovertToVcs(projects, sourceURL, GerritSSHAddress(opts), opts.Username), nil
+}
+
+// GerritSSHAddress 返回 Gerrit SSH 服务地址，优先使用 /ssh_info 返回的主机及端口，获取失败时使用 source.url 的主机及默认端口 29418
+func GerritSSHAddress(opts source.Options) string {
+	host, port, err := api.GetSSHInfo(opts)
+	if err == nil {
+		return net.JoinHostPort(host, port)
+	}
+	logger.Logger.Debugf("获取 Gerrit SSH 信息失败，使用默认端口 %s: %v", api.DefaultSSHPort, err)
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return ""
+	}
+	return net.JoinHostPort(u.Hostname(), api.DefaultSSHPort)
+}
+
+// GerritCovertToVcs 将 Gerrit 项目转换为 VCS 接口，配置了用户名时通过 /a/ 认证地址克隆
+func GerritCovertToVcs(projects []api.Project, sourceURL, sshAddress, username string) []VCS {
+	httpBase := sourceURL
+	if username != "" {
+		httpBase += "/a"
+	}
+	sshUser := ""
+	if username != "" {
+		sshUser = url.User(username).String() + "@"
+	}
+	var VCS []VCS
+	for _, project := range projects {
+		repo := &GerritVcs{
+			Metadata: Metadata{
+				Archived: project.State == "READ_ONLY",
+			},
+			httpURL:  fmt.Sprintf("%s/%s", httpBase, project.Name),
+			RepoPath: project.Name,
+			RepoName: path.Base(project.Name),
+			Desc:     project.Description,
+		}
+		if sshAddress != "" {
+			repo.sshURL = fmt.Sprintf("ssh://%s%s/%s", sshUser, sshAddress, project.Name)
+		}
+		VCS = append(VCS, repo)
+	}
+	return VCS
+}
@@ -0,0 +1,42 @@
+package vcs
+
+import (
+	api "ccrctl/pkg/api/gerrit"
+	"testing"
+)
+
+// TestGerritCovertToVcs 测试 Gerrit 项目层级映射为子组织及克隆地址
+func TestGerritCovertToVcs(t *testing.T) {
+	projects := []api.Project{
+		{Name: "platform/build/tools", Description: "构建工具"},
+		{Name: "docs", State: "READ_ONLY"},
+	}
+	tests := []struct {
+		name     string
+		username string
+		wantHTTP string
+		wantSSH  string
+	}{
+		{name: "认证", username: "alice", wantHTTP: "https://review.example.com/a/platform/build/tools", wantSSH: "ssh://alice@review.example.com:29418/platform/build/tools"},
+		{name: "匿名", wantHTTP: "https://review.example.com/platform/build/tools", wantSSH: "ssh://review.example.com:29418/platform/build/tools"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := GerritCovertToVcs(projects, "https://review.example.com", "review.example.com:29418", tt.username)
+			if len(repos) != 2 {
+				t.Fatalf("GerritCovertToVcs() 返回 %d 个仓库，期望 2", len(repos))
+			}
+			repo := repos[0].(*GerritVcs)
+			if repo.httpURL != tt.wantHTTP || repo.sshURL != tt.wantSSH {
+				t.Errorf("克隆地址 = %s, %s，期望 %s, %s", repo.httpURL, repo.sshURL, tt.wantHTTP, tt.wantSSH)
+			}
+			if repo.GetRepoName() != "tools" || repo.GetSubGroup().Name != "platform/build" || repo.GetRepoDescription() != "构建工具" {
+				t.Errorf("仓库 = %s, 子组织 %s, 描述 %s", repo.GetRepoName(), repo.GetSubGroup().Name, repo.GetRepoDescription())
+			}
+			docs := repos[1].(*GerritVcs)
+			if docs.GetSubGroup().Name != "" || !docs.Archived {
+				t.Errorf("docs 子组织 = %q, Archived = %v，期望为空、true", docs.GetSubGroup().Name, docs.Archived)
+			}
+		})
+	}
+}
@@ -2,19 +2,22 @@ package vcs
 
 import (
 	api "ccrctl/pkg/api/gitea"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/git"
 	"ccrctl/pkg/http_client"
 	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
 	"ccrctl/pkg/util"
 	"fmt"
 	"strconv"
 	"strings"
 )
 
-// GiteaVcs Gitea VCS 实现
+// GiteaVcs Gitea VCS 实现，同时用于 Forgejo、Gogs
 type GiteaVcs struct {
+	options
+	Metadata
 	httpURL  string
+	sshURL   string
 	RepoPath string
 	RepoName string
 	RepoType string
@@ -49,20 +52,20 @@ func (c *GiteaVcs) GetRepoType() string {
 }
 
 func (c *GiteaVcs) GetCloneUrl() string {
-	return util.ConvertUrlWithAuth(c.httpURL, c.GetUserName(), c.GetToken())
+	return c.cloneURL(c.httpURL, c.sshURL, c.GetUserName(), c.GetToken())
 }
 
 func (c *GiteaVcs) GetUserName() string {
-	name, _ := api.GetUserName()
+	name, _ := api.GetUserName(c.opts.Source)
 	return name
 }
 
 func (c *GiteaVcs) GetToken() string {
-	return config.Cfg.GetString("source.token")
+	return c.opts.Source.Token
 }
 
-func (c *GiteaVcs) Clone() error {
-	err := git.Clone(c.GetCloneUrl(), c.GetRepoPath(), allowIncompletePush)
+func (c *GiteaVcs) Clone(dir string, env []string) error {
+	err := git.Clone(c.GetCloneUrl(), dir, c.opts.AllowIncompletePush, env)
 	if err != nil {
 		return err
 	}
@@ -75,7 +78,7 @@ func (c *GiteaVcs) GetRepoPrivate() bool {
 }
 
 func (c *GiteaVcs) GetReleases() (cnbReleases []Releases) {
-	releases, err := api.GetReleases(c.RepoPath)
+	releases, err := api.GetReleases(c.opts.Source, c.RepoPath)
 	if err != nil {
 		logger.Logger.Errorf("获取 Gitea Release 失败: %v", err)
 		return nil
@@ -107,8 +110,8 @@ func (c *GiteaVcs) GetProjectID() string {
 }
 
 // newGiteaRepo 创建 Gitea 仓库列表
-func newGiteaRepo() ([]VCS, error) {
-	repoList, err := api.GetRepoList()
+func newGiteaRepo(opts source.Options) ([]VCS, error) {
+	repoList, err := api.GetRepoList(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +123,15 @@ func GiteaCovertToVcs(repoList []api.Repo) []VCS {
 	var VCS []VCS
 	for _, repo := range repoList {
 		VCS = append(VCS, &GiteaVcs{
+			Metadata: Metadata{
+				Archived:   repo.Archived,
+				Fork:       repo.Fork,
+				SizeKB:     int64(repo.Size),
+				PushedAt:   repo.UpdatedAt,
+				Visibility: visibility(repo.Private, repo.Internal),
+			},
 			httpURL:  repo.CloneUrl,
+			sshURL:   repo.SshUrl,
 			RepoPath: repo.FullName,
 			RepoName: repo.Name,
 			RepoType: Git,
@@ -183,5 +194,5 @@ func (c *GiteaVcs) GetRepoDescription() string {
 }
 
 func (c *GiteaVcs) ListRepos() ([]VCS, error) {
-	return newGiteaRepo()
+	return newGiteaRepo(c.opts.Source)
 }
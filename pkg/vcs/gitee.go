@@ -2,10 +2,10 @@ package vcs
 
 import (
 	api "ccrctl/pkg/api/gitee"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/git"
 	"ccrctl/pkg/http_client"
 	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
 	"ccrctl/pkg/util"
 	"fmt"
 	"strconv"
@@ -13,12 +13,17 @@ import (
 )
 
 type GiteeVcs struct {
+	options
+	Metadata
 	httpURL  string
+	sshURL   string
 	RepoPath string
 	RepoName string
 	RepoType string
 	Private  bool
 	Desc     string
+	// Program 配置了 source.enterprise 时仓库所属的企业项目，作为 CNB 子组织，导出以便写入仓库快照
+	Program *SubGroup `json:",omitempty"`
 }
 
 func (c *GiteeVcs) GetRepoPath() string {
@@ -30,6 +35,9 @@ func (c *GiteeVcs) GetRepoName() string {
 }
 
 func (c *GiteeVcs) GetSubGroup() *SubGroup {
+	if c.Program != nil {
+		return c.Program
+	}
 	parts := strings.Split(c.GetRepoPath(), "/")
 	if len(parts) > 0 {
 		parts = parts[:len(parts)-1] // 去掉仓库名
@@ -47,20 +55,20 @@ func (c *GiteeVcs) GetRepoType() string {
 }
 
 func (c *GiteeVcs) GetCloneUrl() string {
-	return util.ConvertUrlWithAuth(c.httpURL, c.GetUserName(), c.GetToken())
+	return c.cloneURL(c.httpURL, c.sshURL, c.GetUserName(), c.GetToken())
 }
 
 func (c *GiteeVcs) GetUserName() string {
-	name, _ := api.GetUserName()
+	name, _ := api.GetUserName(c.opts.Source)
 	return name
 }
 
 func (c *GiteeVcs) GetToken() string {
-	return config.Cfg.GetString("source.token")
+	return c.opts.Source.Token
 }
 
-func (c *GiteeVcs) Clone() error {
-	err := git.Clone(c.GetCloneUrl(), c.GetRepoPath(), allowIncompletePush)
+func (c *GiteeVcs) Clone(dir string, env []string) error {
+	err := git.Clone(c.GetCloneUrl(), dir, c.opts.AllowIncompletePush, env)
 	if err != nil {
 		return err
 	}
@@ -72,7 +80,7 @@ func (c *GiteeVcs) GetRepoPrivate() bool {
 }
 
 func (c *GiteeVcs) GetReleases() (cnbReleases []Releases) {
-	releases, err := api.GetReleases(c.RepoPath)
+	releases, err := api.GetReleases(c.opts.Source, c.RepoPath)
 	if err != nil {
 		panic(err)
 	}
@@ -106,32 +114,51 @@ func (c *GiteeVcs) GetProjectID() string {
 	return strconv.Itoa(0)
 }
 
-func newGiteeRepo() ([]VCS, error) {
-	repoList, err := api.GetRepoList()
+func newGiteeRepo(opts source.Options) ([]VCS, error) {
+	repoList, err := api.GetRepoList(opts)
 	if err != nil {
 		return nil, err
 	}
-	return GiteeCovertToVcs(repoList), nil
+	return GiteeCovertToVcs(repoList, strings.TrimSpace(opts.Enterprise) != ""), nil
 }
 
-func GiteeCovertToVcs(repoList []api.Repo) []VCS {
+// GiteeCovertToVcs 将 Gitee 仓库转换为 VCS 接口，enterprise 为 true 时仓库所属的企业项目映射为子组织
+func GiteeCovertToVcs(repoList []api.Repo, enterprise bool) []VCS {
 	var VCS []VCS
 	for _, repo := range repoList {
 		// 当 repo.Internal 为 true 时，自动将 Private 也设置为 true
 		// 确保内部仓库被正确标记为私有仓库
 		isPrivate := repo.Private || repo.Internal
 		VCS = append(VCS, &GiteeVcs{
+			Metadata: Metadata{
+				Fork:       repo.Fork,
+				PushedAt:   repo.PushedAt,
+				Visibility: visibility(repo.Private, repo.Internal),
+			},
 			httpURL:  repo.HtmlUrl,
+			sshURL:   repo.SshUrl,
 			RepoPath: repo.FullName,
 			RepoName: repo.Name,
 			RepoType: Git,
 			Private:  isPrivate,
 			Desc:     repo.Description,
+			Program:  giteeProgram(enterprise, repo.Programs),
 		})
 	}
 	return VCS
 }
 
+// giteeProgram 企业版仓库所属项目对应的子组织，仓库属于多个项目时取第一个，不属于任何项目时返回 nil
+func giteeProgram(enterprise bool, programs []api.Program) *SubGroup {
+	if !enterprise || len(programs) == 0 || strings.TrimSpace(programs[0].Name) == "" {
+		return nil
+	}
+	return &SubGroup{
+		Name: strings.TrimSpace(programs[0].Name),
+		Desc: programs[0].Description,
+	}
+}
+
 func (c *GiteeVcs) GetReleaseAttachments(desc string, repoPath string, projectID string) ([]Attachment, error) {
 	// 转换release描述中的附件链接为cnb附件链接
 	attachments, images, exists := util.GiteeExtractAttachments(desc)
@@ -183,5 +210,5 @@ func (c *GiteeVcs) GetRepoDescription() string {
 }
 
 func (c *GiteeVcs) ListRepos() ([]VCS, error) {
-	return newGiteeRepo()
+	return newGiteeRepo(c.opts.Source)
 }
@@ -83,7 +83,7 @@ func TestGiteeCovertToVcs_InternalRepoHandling(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repoList := []api.Repo{tt.repo}
-			vcsList := GiteeCovertToVcs(repoList)
+			vcsList := GiteeCovertToVcs(repoList, false)
 
 			if len(vcsList) != 1 {
 				t.Fatalf("期望返回1个VCS对象，实际返回%d个", len(vcsList))
@@ -150,7 +150,7 @@ func TestGiteeCovertToVcs_MultipleRepos(t *testing.T) {
 		},
 	}
 
-	vcsList := GiteeCovertToVcs(repoList)
+	vcsList := GiteeCovertToVcs(repoList, false)
 
 	if len(vcsList) != 3 {
 		t.Fatalf("期望返回3个VCS对象，实际返回%d个", len(vcsList))
@@ -178,7 +178,7 @@ func TestGiteeCovertToVcs_MultipleRepos(t *testing.T) {
 func TestGiteeCovertToVcs_EmptyRepoList(t *testing.T) {
 	// 测试空仓库列表
 	repoList := []api.Repo{}
-	vcsList := GiteeCovertToVcs(repoList)
+	vcsList := GiteeCovertToVcs(repoList, false)
 
 	if len(vcsList) != 0 {
 		t.Errorf("空仓库列表应该返回空VCS列表，实际返回%d个", len(vcsList))
@@ -210,7 +210,7 @@ func TestGiteeCovertToVcs_InternalPrivateLogic(t *testing.T) {
 				Description: "测试仓库",
 			}
 
-			vcsList := GiteeCovertToVcs([]api.Repo{repo})
+			vcsList := GiteeCovertToVcs([]api.Repo{repo}, false)
 			vcs := vcsList[0].(*GiteeVcs)
 
 			if vcs.Private != tc.expectedPrivate {
@@ -238,7 +238,7 @@ func BenchmarkGiteeCovertToVcs(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		GiteeCovertToVcs(repoList)
+		GiteeCovertToVcs(repoList, false)
 	}
 }
 
@@ -253,7 +253,7 @@ func TestGiteeVcs_Methods(t *testing.T) {
 		Description: "测试仓库描述",
 	}
 
-	vcsList := GiteeCovertToVcs([]api.Repo{repo})
+	vcsList := GiteeCovertToVcs([]api.Repo{repo}, false)
 	vcs := vcsList[0]
 
 	// 测试 GetRepoPrivate 方法
@@ -280,4 +280,27 @@ func TestGiteeVcs_Methods(t *testing.T) {
 	if vcs.GetRepoDescription() != "测试仓库描述" {
 		t.Errorf("GetRepoDescription() 期望 '测试仓库描述'，实际 '%s'", vcs.GetRepoDescription())
 	}
-}
\ No newline at end of file
+}
+func TestGiteeCovertToVcs_EnterpriseProgram(t *testing.T) {
+	repoList := []api.Repo{
+		{FullName: "ent/api", Name: "api", Programs: []api.Program{{Name: "backend", Description: "后端项目"}, {Name: "shared"}}},
+		{FullName: "ent/web", Name: "web"},
+	}
+	tests := []struct {
+		name       string
+		enterprise bool
+		want       []SubGroup
+	}{
+		{name: "未配置企业", want: []SubGroup{{Name: "ent"}, {Name: "ent"}}},
+		{name: "企业项目映射为子组织", enterprise: true, want: []SubGroup{{Name: "backend", Desc: "后端项目"}, {Name: "ent"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, depot := range GiteeCovertToVcs(repoList, tt.enterprise) {
+				if got := *depot.GetSubGroup(); got != tt.want[i] {
+					t.Errorf("%s GetSubGroup() = %+v, want %+v", depot.GetRepoPath(), got, tt.want[i])
+				}
+			}
+		})
+	}
+}
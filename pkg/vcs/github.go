@@ -2,9 +2,8 @@ package vcs
 
 import (
 	api "ccrctl/pkg/api/github"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/git"
-	"ccrctl/pkg/util"
+	"ccrctl/pkg/source"
 	"strconv"
 	"strings"
 
@@ -16,7 +15,10 @@ const (
 )
 
 type GithubVcs struct {
+	options
+	Metadata
 	httpURL   string
+	sshURL    string
 	RepoPath  string
 	RepoName  string
 	RepoType  string
@@ -51,7 +53,7 @@ func (c *GithubVcs) GetRepoType() string {
 }
 
 func (c *GithubVcs) GetCloneUrl() string {
-	return util.ConvertUrlWithAuth(c.httpURL, c.GetUserName(), c.GetToken())
+	return c.cloneURL(c.httpURL, c.sshURL, c.GetUserName(), c.GetToken())
 }
 
 func (c *GithubVcs) GetUserName() string {
@@ -59,11 +61,11 @@ func (c *GithubVcs) GetUserName() string {
 }
 
 func (c *GithubVcs) GetToken() string {
-	return config.Cfg.GetString("source.token")
+	return c.opts.Source.Token
 }
 
-func (c *GithubVcs) Clone() error {
-	err := git.Clone(c.GetCloneUrl(), c.GetRepoPath(), allowIncompletePush)
+func (c *GithubVcs) Clone(dir string, env []string) error {
+	err := git.Clone(c.GetCloneUrl(), dir, c.opts.AllowIncompletePush, env)
 	if err != nil {
 		return err
 	}
@@ -82,13 +84,15 @@ func (c *GithubVcs) GetReleases() (cnbReleases []Releases) {
 	owner := parts[0]
 	repo := parts[1]
 
-	githubReleases, err := api.GetReleases(owner, repo)
+	githubReleases, err := api.GetReleases(c.opts.Source, owner, repo)
 	if err != nil {
 		panic(err)
 	}
 	for _, githubRelease := range githubReleases {
 		var assets []Asset
-		if !c.Private {
+		// GitHub Enterprise Server 的附件通过 API 下载，私有仓库的附件同样可以迁移
+		enterprise := api.IsEnterprise(c.opts.Source)
+		if !c.Private || enterprise {
 			for _, asset := range githubRelease.Assets {
 				assetName := ""
 				if asset.Name != nil {
@@ -98,10 +102,17 @@ func (c *GithubVcs) GetReleases() (cnbReleases []Releases) {
 				if asset.BrowserDownloadURL != nil {
 					assetURL = *asset.BrowserDownloadURL
 				}
-				assets = append(assets, Asset{
+				a := Asset{
 					Name: assetName,
 					Url:  assetURL,
-				})
+				}
+				if enterprise && asset.ID != nil {
+					assetID := *asset.ID
+					a.Download = func() ([]byte, error) {
+						return api.DownloadReleaseAsset(c.opts.Source, owner, repo, assetID)
+					}
+				}
+				assets = append(assets, a)
 			}
 		}
 		tagName := ""
@@ -137,8 +148,8 @@ func (c *GithubVcs) GetProjectID() string {
 	return strconv.Itoa(c.ProjectId)
 }
 
-func newGithubRepo() ([]VCS, error) {
-	repoList, err := api.GetRepos()
+func newGithubRepo(opts source.Options) ([]VCS, error) {
+	repoList, err := api.GetRepos(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -152,8 +163,20 @@ func GithubCovertToVcs(repoList []*github.Repository) []VCS {
 		if repo.Description != nil {
 			desc = *repo.Description
 		}
+		repoVisibility := repo.GetVisibility()
+		if repoVisibility == "" {
+			repoVisibility = visibility(repo.GetPrivate(), false)
+		}
 		VCS = append(VCS, &GithubVcs{
+			Metadata: Metadata{
+				Archived:   repo.GetArchived(),
+				Fork:       repo.GetFork(),
+				SizeKB:     int64(repo.GetSize()),
+				PushedAt:   repo.GetPushedAt().Time,
+				Visibility: repoVisibility,
+			},
 			httpURL:   *repo.CloneURL,
+			sshURL:    repo.GetSSHURL(),
 			RepoPath:  *repo.FullName,
 			RepoName:  *repo.Name,
 			RepoType:  Git,
@@ -175,5 +198,5 @@ func (c *GithubVcs) GetRepoDescription() string {
 }
 
 func (c *GithubVcs) ListRepos() ([]VCS, error) {
-	return newGithubRepo()
+	return newGithubRepo(c.opts.Source)
 }
@@ -2,9 +2,9 @@ package vcs
 
 import (
 	api "ccrctl/pkg/api/gitlab"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/git"
 	"ccrctl/pkg/logger"
+	"ccrctl/pkg/source"
 	"ccrctl/pkg/util"
 	"strconv"
 	"strings"
@@ -18,7 +18,10 @@ const (
 )
 
 type GitlabVcs struct {
+	options
+	Metadata
 	httpURL         string
+	sshURL          string
 	RepoPath        string
 	RepoName        string
 	RepoDisplayName string
@@ -54,7 +57,7 @@ func (c *GitlabVcs) GetRepoType() string {
 }
 
 func (c *GitlabVcs) GetCloneUrl() string {
-	return util.ConvertUrlWithAuth(c.httpURL, GitlabUserName, c.GetToken())
+	return c.cloneURL(c.httpURL, c.sshURL, GitlabUserName, c.GetToken())
 }
 
 func (c *GitlabVcs) GetUserName() string {
@@ -62,11 +65,11 @@ func (c *GitlabVcs) GetUserName() string {
 }
 
 func (c *GitlabVcs) GetToken() string {
-	return config.Cfg.GetString("source.token")
+	return c.opts.Source.Token
 }
 
-func (c *GitlabVcs) Clone() error {
-	err := git.Clone(c.GetCloneUrl(), c.GetRepoPath(), allowIncompletePush)
+func (c *GitlabVcs) Clone(dir string, env []string) error {
+	err := git.Clone(c.GetCloneUrl(), dir, c.opts.AllowIncompletePush, env)
 	if err != nil {
 		return err
 	}
@@ -84,7 +87,7 @@ func (c *GitlabVcs) GetRepoPrivate() bool {
 }
 
 func (c *GitlabVcs) GetReleases() (cnbReleases []Releases) {
-	gitlabReleases, err := api.GetReleases(c.ProjectId)
+	gitlabReleases, err := api.GetReleases(c.opts.Source, c.ProjectId)
 	if err != nil {
 		panic(err)
 	}
@@ -111,8 +114,8 @@ func (c *GitlabVcs) GetProjectID() string {
 	return strconv.Itoa(c.ProjectId)
 }
 
-func newGitlabRepo() ([]VCS, error) {
-	repoList, err := api.GetProjects()
+func newGitlabRepo(opts source.Options) ([]VCS, error) {
+	repoList, err := api.GetProjects(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -122,8 +125,21 @@ func newGitlabRepo() ([]VCS, error) {
 func GitlabCovertToVcs(repoList []*gitlab.Project) []VCS {
 	var VCS []VCS
 	for _, repo := range repoList {
+		metadata := Metadata{
+			Archived:   repo.Archived,
+			Fork:       repo.ForkedFromProject != nil,
+			Visibility: string(repo.Visibility),
+		}
+		if repo.Statistics != nil {
+			metadata.SizeKB = repo.Statistics.RepositorySize / 1024
+		}
+		if repo.LastActivityAt != nil {
+			metadata.PushedAt = *repo.LastActivityAt
+		}
 		VCS = append(VCS, &GitlabVcs{
+			Metadata:        metadata,
 			httpURL:         repo.HTTPURLToRepo,
+			sshURL:          repo.SSHURLToRepo,
 			RepoPath:        repo.PathWithNamespace,
 			RepoName:        repo.Path,
 			RepoDisplayName: repo.Name,
@@ -144,16 +160,16 @@ func (c *GitlabVcs) GetReleaseAttachments(desc string, repoPath string, projectI
 	}
 	var attachmentsList []Attachment
 	for attachmentName, attachmentUrl := range attachments {
-		uploadFiles, err := api.ListUploads(projectID)
+		uploadFiles, err := api.ListUploads(c.opts.Source, projectID)
 		if err != nil {
 			return nil, err
 		}
 		fileID, ok := uploadFiles[attachmentName]
 		if !ok {
-			logger.Logger.Warnf("%s 附件 %s 不存在", repoPath, attachmentName)
+			logger.Repo(repoPath).Warnf("%s 附件 %s 不存在", repoPath, attachmentName)
 			continue
 		}
-		data, err := api.DownloadFile(projectID, fileID)
+		data, err := api.DownloadFile(c.opts.Source, projectID, fileID)
 		if err != nil {
 			logger.Logger.Errorf("%s 下载release asset %s 失败: %s", attachmentUrl, attachmentName, err)
 			return nil, err
@@ -168,16 +184,16 @@ func (c *GitlabVcs) GetReleaseAttachments(desc string, repoPath string, projectI
 		})
 	}
 	for imageName, imageUrl := range images {
-		uploadFiles, err := api.ListUploads(projectID)
+		uploadFiles, err := api.ListUploads(c.opts.Source, projectID)
 		if err != nil {
 			return nil, err
 		}
 		fileID, ok := uploadFiles[imageName]
 		if !ok {
-			logger.Logger.Warnf("%s 附件 %s 不存在", repoPath, imageName)
+			logger.Repo(repoPath).Warnf("%s 附件 %s 不存在", repoPath, imageName)
 			continue
 		}
-		data, err := api.DownloadFile(projectID, fileID)
+		data, err := api.DownloadFile(c.opts.Source, projectID, fileID)
 		if err != nil {
 			logger.Logger.Errorf("%s 下载release asset %s 失败: %s", imageUrl, imageName, err)
 			return nil, err
@@ -200,5 +216,5 @@ func (c *GitlabVcs) GetRepoDescription() string {
 }
 
 func (c *GitlabVcs) ListRepos() ([]VCS, error) {
-	return newGitlabRepo()
+	return newGitlabRepo(c.opts.Source)
 }
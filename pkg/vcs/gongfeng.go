@@ -2,9 +2,8 @@ package vcs
 
 import (
 	"ccrctl/pkg/api/gongfeng"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/git"
-	"ccrctl/pkg/util"
+	"ccrctl/pkg/source"
 	"strconv"
 	"strings"
 )
@@ -14,7 +13,10 @@ const (
 )
 
 type GongfengVcs struct {
+	options
+	Metadata
 	httpURL   string
+	sshURL    string
 	RepoPath  string
 	RepoName  string
 	RepoType  string
@@ -54,7 +56,7 @@ func (c *GongfengVcs) GetRepoType() string {
 
 // GetCloneUrl 返回克隆 URL
 func (c *GongfengVcs) GetCloneUrl() string {
-	return util.ConvertUrlWithAuth(c.httpURL, GongfengUserName, c.GetToken())
+	return c.cloneURL(c.httpURL, c.sshURL, GongfengUserName, c.GetToken())
 }
 
 // GetUserName 返回用户名
@@ -64,12 +66,12 @@ func (c *GongfengVcs) GetUserName() string {
 
 // GetToken 返回访问令牌
 func (c *GongfengVcs) GetToken() string {
-	return config.Cfg.GetString("source.token")
+	return c.opts.Source.Token
 }
 
 // Clone 克隆仓库
-func (c *GongfengVcs) Clone() error {
-	err := git.Clone(c.GetCloneUrl(), c.GetRepoPath(), allowIncompletePush)
+func (c *GongfengVcs) Clone(dir string, env []string) error {
+	err := git.Clone(c.GetCloneUrl(), dir, c.opts.AllowIncompletePush, env)
 	if err != nil {
 		return err
 	}
@@ -103,12 +105,12 @@ func (c *GongfengVcs) GetRepoDescription() string {
 
 // ListRepos 列出所有仓库
 func (c *GongfengVcs) ListRepos() ([]VCS, error) {
-	return newGongfengRepo()
+	return newGongfengRepo(c.opts.Source)
 }
 
 // newGongfengRepo 创建工蜂仓库实例
-func newGongfengRepo() ([]VCS, error) {
-	projects, err := gongfeng.GetProjects()
+func newGongfengRepo(opts source.Options) ([]VCS, error) {
+	projects, err := gongfeng.GetProjects(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +122,13 @@ func GongfengConvertToVcs(projects []gongfeng.Project) []VCS {
 	var vcsList []VCS
 	for _, project := range projects {
 		vcsList = append(vcsList, &GongfengVcs{
+			Metadata: Metadata{
+				Archived:   project.Archived,
+				PushedAt:   parseMetadataTime(project.LastActivityAt),
+				Visibility: project.Visibility(),
+			},
 			httpURL:   project.HTTPURL,
+			sshURL:    project.SSHURL,
 			RepoPath:  project.PathWithNS,
 			RepoName:  project.Name,
 			RepoType:  Git,
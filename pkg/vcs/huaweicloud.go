@@ -3,9 +3,8 @@ package vcs
 
 import (
 	"ccrctl/pkg/api/huaweicloud"
-	"ccrctl/pkg/config"
 	"ccrctl/pkg/git"
-	"ccrctl/pkg/util"
+	"ccrctl/pkg/source"
 	"fmt"
 	"path"
 	"strings"
@@ -17,6 +16,8 @@ const (
 
 // HuaweiCloudVcs 华为云CodeArts VCS实现
 type HuaweiCloudVcs struct {
+	options
+	Metadata
 	ID          int32  `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
@@ -33,12 +34,12 @@ type HuaweiCloudVcs struct {
 
 // ListRepos 获取华为云CodeArts仓库列表
 func (c *HuaweiCloudVcs) ListRepos() ([]VCS, error) {
-	return newHuaweiCloudRepo()
+	return newHuaweiCloudRepo(c.opts.Source)
 }
 
 // GetCloneUrl 获取克隆URL
 func (c *HuaweiCloudVcs) GetCloneUrl() string {
-	return util.ConvertUrlWithAuth(c.CloneURL, huaweiUserName, c.GetToken())
+	return c.cloneURL(c.CloneURL, c.SSHURL, huaweiUserName, c.GetToken())
 }
 
 // GetRepoName 获取仓库名称
@@ -101,14 +102,14 @@ func (c *HuaweiCloudVcs) GetReleaseAttachments(desc string, repoPath string, pro
 // GetToken 获取访问令牌
 func (c *HuaweiCloudVcs) GetToken() string {
 	// 华为云使用AK/SK认证，这里返回空字符串
-	return config.Cfg.GetString("source.token")
+	return c.opts.Source.Token
 }
 
 // Clone 克隆仓库（VCS接口要求的方法）
-func (c *HuaweiCloudVcs) Clone() error {
+func (c *HuaweiCloudVcs) Clone(dir string, env []string) error {
 	// 这里可以实现具体的克隆逻辑
 	// 目前返回 nil 表示成功
-	return git.Clone(c.GetCloneUrl(), c.GetRepoPath(), allowIncompletePush)
+	return git.Clone(c.GetCloneUrl(), dir, c.opts.AllowIncompletePush, env)
 }
 
 func (c *HuaweiCloudVcs) GetProjectID() string {
@@ -116,9 +117,9 @@ func (c *HuaweiCloudVcs) GetProjectID() string {
 }
 
 // newHuaweiCloudRepo 创建华为云CodeArts仓库客户端并获取仓库列表
-func newHuaweiCloudRepo() ([]VCS, error) {
+func newHuaweiCloudRepo(opts source.Options) ([]VCS, error) {
 	// 调用华为云API获取仓库列表
-	response, err := huaweicloud.GetRepositories()
+	response, err := huaweicloud.GetRepositories(opts)
 	if err != nil {
 		return nil, fmt.Errorf("获取华为云CodeArts仓库列表失败: %w", err)
 	}
@@ -129,7 +130,7 @@ func newHuaweiCloudRepo() ([]VCS, error) {
 	}
 
 	var repos []VCS
-	projectsMap, err := huaweicloud.GetProjects()
+	projectsMap, err := huaweicloud.GetProjects(opts)
 	if err != nil {
 		return nil, fmt.Errorf("获取华为云CodeArts项目列表失败: %w", err)
 	}
@@ -145,9 +146,19 @@ func newHuaweiCloudRepo() ([]VCS, error) {
 			}
 			projectDisplayName := projectsMap[*repo.ProjectUuid]
 			subGroup := path.Join(projectDisplayName, covertSubGroup(*repo.GroupName))
+			sshURL := ""
+			if repo.SshUrl != nil {
+				sshURL = *repo.SshUrl
+			}
+			metadata := Metadata{Visibility: visibility(isPrivate, false)}
+			if repo.UpdatedAt != nil {
+				metadata.PushedAt = parseMetadataTime(*repo.UpdatedAt)
+			}
 			vcsRepo := &HuaweiCloudVcs{
+				Metadata:    metadata,
 				Name:        *repo.RepositoryName,
 				CloneURL:    *repo.HttpsUrl,
+				SSHURL:      sshURL,
 				Private:     isPrivate,
 				RepoPath:    path.Join(subGroup, *repo.RepositoryName),
 				SubGroup:    subGroup,
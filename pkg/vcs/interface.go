@@ -1,13 +1,48 @@
 package vcs
 
 import (
-	"ccrctl/pkg/config"
+	"ccrctl/pkg/source"
+	"ccrctl/pkg/util"
 	"fmt"
 )
 
-var (
-	allowIncompletePush = config.Cfg.GetBool("migrate.allow_incomplete_push")
-)
+// Options 源平台仓库选项，由 New 设置到返回的每个仓库
+type Options struct {
+	// SSH 使用 SSH 协议克隆
+	SSH bool
+	// AllowIncompletePush LFS 对象缺失时仍继续迁移
+	AllowIncompletePush bool
+	// MapCodingDescription 使用 CODING 项目描述作为子组织描述
+	MapCodingDescription bool
+	// MapCodingDisplayName 使用 CODING 项目显示名称作为子组织别名
+	MapCodingDisplayName bool
+	// GerritChangesNamespace Gerrit 未合入变更的补丁集复制为该命名空间下的分支，为空时不复制
+	GerritChangesNamespace string
+	// Source 源平台地址及认证信息
+	Source source.Options
+}
+
+// options 嵌入各平台 VCS 实现，保存 New 传入的选项
+type options struct {
+	opts Options
+}
+
+func (o *options) setOptions(opts Options) {
+	o.opts = opts
+}
+
+// cloneURL 返回克隆地址，开启 SSH 时优先使用平台 API 返回的 SSH 地址，未返回时由 HTTP 地址转换，
+// 否则使用带认证信息的 HTTP 地址
+func (o *options) cloneURL(httpURL, sshURL, username, token string) string {
+	if o.opts.SSH {
+		if sshURL != "" {
+			return sshURL
+		}
+		return util.ConvertHTTPToSSHURL(httpURL)
+	}
+	// 默认保持 HTTP 协议克隆
+	return util.ConvertUrlWithAuth(httpURL, username, token)
+}
 
 type Releases struct {
 	Body            string  `json:"body"`
@@ -23,6 +58,8 @@ type Releases struct {
 type Asset struct {
 	Name string `json:"name"`
 	Url  string `json:"url"`
+	// Download 通过源平台 API 下载附件，为空时从 Url 直接下载
+	Download func() ([]byte, error) `json:"-"`
 }
 
 type Attachment struct {
@@ -48,39 +85,56 @@ type VCS interface {
 	GetCloneUrl() string
 	GetUserName() string
 	GetToken() string
-	Clone() error
+	Clone(dir string, env []string) error // 镜像克隆仓库到本地目录 dir，env 为追加到 git 命令的环境变量
 	GetRepoPrivate() bool
 	GetReleases() []Releases
 	GetProjectID() string
 	GetReleaseAttachments(desc string, repoPath string, projectID string) ([]Attachment, error) // 获取 release 描述中的附件
 	GetRepoDescription() string
 	ListRepos() ([]VCS, error)
+	GetMetadata() Metadata
+}
+
+// New 获取源平台仓库列表，opts 作用于返回的每个仓库
+func New(sourceRepoPlatformName string, opts Options) ([]VCS, error) {
+	repos, err := listRepos(sourceRepoPlatformName, opts.Source)
+	if err != nil {
+		return nil, err
+	}
+	for _, repo := range repos {
+		if r, ok := repo.(interface{ setOptions(Options) }); ok {
+			r.setOptions(opts)
+		}
+	}
+	return repos, nil
 }
 
-func NewVcs(sourceRepoPlatformName string) ([]VCS, error) {
+func listRepos(sourceRepoPlatformName string, opts source.Options) ([]VCS, error) {
 	switch sourceRepoPlatformName {
 	case "coding":
-		return newCodingRepo()
+		return newCodingRepo(opts)
 	case "gitlab":
-		return newGitlabRepo()
+		return newGitlabRepo(opts)
 	case "github":
-		return newGithubRepo()
+		return newGithubRepo(opts)
 	case "gitee":
-		return newGiteeRepo()
-	case "gitea":
-		return newGiteaRepo()
+		return newGiteeRepo(opts)
+	case "gitea", "forgejo", "gogs":
+		return newGiteaRepo(opts)
 	case "common":
-		return newCommonRepo()
+		return newCommonRepo(opts)
 	case "aliyun":
-		return newAliyunRepo()
+		return newAliyunRepo(opts)
 	case "cnb":
-		return newCnbRepo()
+		return newCnbRepo(opts)
 	case "gongfeng":
-		return newGongfengRepo()
+		return newGongfengRepo(opts)
 	case "local":
-		return newLocalRepo()
+		return newLocalRepo(opts.Dir)
 	case "huaweicloud":
-		return newHuaweiCloudRepo()
+		return newHuaweiCloudRepo(opts)
+	case "gerrit":
+		return newGerritRepo(opts)
 	default:
 		return nil, fmt.Errorf("不支持的仓库平台: %s", sourceRepoPlatformName)
 	}
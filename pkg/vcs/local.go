@@ -13,6 +13,7 @@ const (
 )
 
 type LocalVcs struct {
+	Metadata
 	RepoPath string
 	RepoName string
 }
@@ -26,27 +27,30 @@ func (l *LocalVcs) GetSubGroup() *SubGroup {
 	}
 	return &SubGroup{Name: strings.Join(parts, "/")}
 }
-func (l *LocalVcs) GetRepoType() string     { return Git }
-func (l *LocalVcs) GetCloneUrl() string     { return "" }
-func (l *LocalVcs) GetUserName() string     { return "" }
-func (l *LocalVcs) GetToken() string        { return "" }
-func (l *LocalVcs) Clone() error            { return nil }
-func (l *LocalVcs) GetRepoPrivate() bool    { return true }
-func (l *LocalVcs) GetReleases() []Releases { return nil }
-func (l *LocalVcs) GetProjectID() string    { return "0" }
+func (l *LocalVcs) GetRepoType() string                  { return Git }
+func (l *LocalVcs) GetCloneUrl() string                  { return "" }
+func (l *LocalVcs) GetUserName() string                  { return "" }
+func (l *LocalVcs) GetToken() string                     { return "" }
+func (l *LocalVcs) Clone(dir string, env []string) error { return nil }
+func (l *LocalVcs) GetRepoPrivate() bool                 { return true }
+func (l *LocalVcs) GetReleases() []Releases              { return nil }
+func (l *LocalVcs) GetProjectID() string                 { return "0" }
 func (l *LocalVcs) GetReleaseAttachments(desc string, repoPath string, projectID string) ([]Attachment, error) {
 	return nil, nil
 }
 func (l *LocalVcs) GetRepoDescription() string { return "" }
 func (l *LocalVcs) ListRepos() ([]VCS, error)  { return nil, nil }
 
-// newLocalRepo scans ./source_git_dir directory and builds VCS list from local bare repos.
-func newLocalRepo() ([]VCS, error) {
-	pwd, err := os.Getwd()
-	if err != nil {
-		return nil, fmt.Errorf("获取当前目录失败: %v", err)
+// newLocalRepo scans dir (default ./source_git_dir) and builds VCS list from local bare repos.
+func newLocalRepo(dir string) ([]VCS, error) {
+	root := dir
+	if root == "" {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("获取当前目录失败: %v", err)
+		}
+		root = filepath.Join(pwd, "source_git_dir")
 	}
-	root := filepath.Join(pwd, "source_git_dir")
 	info, err := os.Stat(root)
 	if err != nil {
 		return nil, fmt.Errorf("本地目录 %s 不存在，请先将待迁移仓库放在该目录下", root)
@@ -0,0 +1,60 @@
+package vcs
+
+import (
+	"strings"
+	"time"
+)
+
+// 仓库可见性
+const (
+	VisibilityPublic   = "public"
+	VisibilityInternal = "internal"
+	VisibilityPrivate  = "private"
+)
+
+// Metadata 平台 API 返回的仓库元数据，嵌入各平台 VCS 实现，平台未返回的字段为零值
+type Metadata struct {
+	Archived bool
+	Fork     bool
+	// SizeKB 仓库大小，单位 KB，0 表示未知
+	SizeKB int64
+	// PushedAt 最后推送时间，平台未返回推送时间时为最后活动或更新时间，零值表示未知
+	PushedAt time.Time
+	// Visibility public/internal/private，为空表示未知
+	Visibility string
+}
+
+// GetMetadata 返回仓库元数据
+func (m *Metadata) GetMetadata() Metadata {
+	return *m
+}
+
+// metadataTimeLayouts 各平台 API 返回的时间格式
+var metadataTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000-0700",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02 15:04:05",
+}
+
+// parseMetadataTime 解析平台 API 返回的时间，无法解析时返回零值
+func parseMetadataTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range metadataTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// visibility 由是否私有、是否内部公开得到可见性
+func visibility(private, internal bool) string {
+	switch {
+	case internal:
+		return VisibilityInternal
+	case private:
+		return VisibilityPrivate
+	}
+	return VisibilityPublic
+}
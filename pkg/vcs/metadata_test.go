@@ -0,0 +1,41 @@
+package vcs
+
+import (
+	api "ccrctl/pkg/api/gitea"
+	"testing"
+	"time"
+)
+
+func TestParseMetadataTime(t *testing.T) {
+	want := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{value: "2024-05-06T07:08:09Z", want: want},
+		{value: "2024-05-06T15:08:09+08:00", want: want},
+		{value: "2024-05-06T15:08:09+0800", want: want},
+		{value: "2024-05-06T15:08:09.000+0800", want: want},
+		{value: "2024-05-06 07:08:09", want: want},
+		{value: "", want: time.Time{}},
+		{value: "invalid", want: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := parseMetadataTime(tt.value); !got.Equal(tt.want) {
+				t.Errorf("parseMetadataTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGiteaCovertToVcs_Metadata(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	repos := GiteaCovertToVcs([]api.Repo{
+		{FullName: "org/repo", Name: "repo", Internal: true, Fork: true, Archived: true, Size: 2048, UpdatedAt: updatedAt},
+	})
+	want := Metadata{Archived: true, Fork: true, SizeKB: 2048, PushedAt: updatedAt, Visibility: VisibilityInternal}
+	if got := repos[0].GetMetadata(); got != want {
+		t.Errorf("GetMetadata() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+package vcs
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Snapshot 仓库快照，记录恢复仓库所需的信息，不含认证信息
+// 用于 retry-failed 不获取源平台仓库列表，直接恢复上次迁移失败的仓库
+type Snapshot struct {
+	Platform string `json:"platform"`
+	// HTTPURL、SSHURL、ID 为各平台仓库结构中不导出的字段
+	HTTPURL string `json:"http_url,omitempty"`
+	SSHURL  string `json:"ssh_url,omitempty"`
+	ID      int    `json:"id,omitempty"`
+	// Repo 仓库结构中导出的字段
+	Repo json.RawMessage `json:"repo"`
+}
+
+// NewSnapshot 生成仓库快照
+func NewSnapshot(platform string, repo VCS) (*Snapshot, error) {
+	data, err := json.Marshal(repo)
+	if err != nil {
+		return nil, fmt.Errorf("%s 生成仓库快照失败: %v", repo.GetRepoPath(), err)
+	}
+	s := &Snapshot{Platform: platform, Repo: data}
+	switch r := repo.(type) {
+	case *AliyunVcs:
+		s.HTTPURL, s.SSHURL = r.httpURL, r.sshURL
+	case *CNBVcs:
+		s.HTTPURL = r.httpURL
+	case *CodingVcs:
+		s.HTTPURL, s.SSHURL, s.ID = r.httpURL, r.sshURL, r.id
+	case *CommonVcs:
+		s.HTTPURL = r.httpURL
+	case *GiteaVcs:
+		s.HTTPURL, s.SSHURL = r.httpURL, r.sshURL
+	case *GerritVcs:
+		s.HTTPURL, s.SSHURL = r.httpURL, r.sshURL
+	case *GiteeVcs:
+		s.HTTPURL, s.SSHURL = r.httpURL, r.sshURL
+	case *GithubVcs:
+		s.HTTPURL, s.SSHURL = r.httpURL, r.sshURL
+	case *GitlabVcs:
+		s.HTTPURL, s.SSHURL = r.httpURL, r.sshURL
+	case *GongfengVcs:
+		s.HTTPURL, s.SSHURL = r.httpURL, r.sshURL
+	}
+	return s, nil
+}
+
+// Restore 由快照恢复仓库，opts 作用于恢复的仓库
+func (s *Snapshot) Restore(opts Options) (VCS, error) {
+	var repo VCS
+	switch s.Platform {
+	case "aliyun":
+		repo = &AliyunVcs{httpURL: s.HTTPURL, sshURL: s.SSHURL}
+	case "cnb":
+		repo = &CNBVcs{httpURL: s.HTTPURL}
+	case "coding":
+		repo = &CodingVcs{httpURL: s.HTTPURL, sshURL: s.SSHURL, id: s.ID}
+	case "common":
+		repo = &CommonVcs{httpURL: s.HTTPURL}
+	case "gerrit":
+		repo = &GerritVcs{httpURL: s.HTTPURL, sshURL: s.SSHURL}
+	case "gitea", "forgejo", "gogs":
+		repo = &GiteaVcs{httpURL: s.HTTPURL, sshURL: s.SSHURL}
+	case "gitee":
+		repo = &GiteeVcs{httpURL: s.HTTPURL, sshURL: s.SSHURL}
+	case "github":
+		repo = &GithubVcs{httpURL: s.HTTPURL, sshURL: s.SSHURL}
+	case "gitlab":
+		repo = &GitlabVcs{httpURL: s.HTTPURL, sshURL: s.SSHURL}
+	case "gongfeng":
+		repo = &GongfengVcs{httpURL: s.HTTPURL, sshURL: s.SSHURL}
+	case "huaweicloud":
+		repo = &HuaweiCloudVcs{}
+	case "local":
+		repo = &LocalVcs{}
+	default:
+		return nil, fmt.Errorf("不支持的仓库平台: %s", s.Platform)
+	}
+	if err := json.Unmarshal(s.Repo, repo); err != nil {
+		return nil, fmt.Errorf("恢复仓库快照失败: %v", err)
+	}
+	if r, ok := repo.(interface{ setOptions(Options) }); ok {
+		r.setOptions(opts)
+	}
+	return repo, nil
+}
@@ -0,0 +1,67 @@
+package vcs
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSnapshotRestore 测试仓库快照恢复后字段及克隆地址与原仓库一致
+func TestSnapshotRestore(t *testing.T) {
+	tests := []struct {
+		platform string
+		repo     VCS
+	}{
+		{platform: "github", repo: &GithubVcs{httpURL: "https://github.com/org/repo.git", sshURL: "git@github.com:org/repo.git", RepoPath: "org/repo", RepoName: "repo", Private: true, ProjectId: 42, Metadata: Metadata{SizeKB: 1}}},
+		{platform: "coding", repo: &CodingVcs{httpURL: "https://e.coding.net/team/project/repo.git", sshURL: "git@e.coding.net:team/project/repo.git", RepoPath: "project/repo", SubGroupName: "project", RepoName: "repo", id: 7}},
+		{platform: "gitee", repo: &GiteeVcs{httpURL: "https://gitee.com/ent/api.git", sshURL: "git@gitee.com:ent/api.git", RepoPath: "ent/api", RepoName: "api", Program: &SubGroup{Name: "backend", Desc: "后端项目"}}},
+		{platform: "gerrit", repo: &GerritVcs{httpURL: "https://review.example.com/a/platform/build", sshURL: "ssh://alice@review.example.com:29418/platform/build", RepoPath: "platform/build", RepoName: "build", Desc: "构建脚本"}},
+		{platform: "huaweicloud", repo: &HuaweiCloudVcs{ID: 1, CloneURL: "https://codehub.example.com/group/repo.git", RepoPath: "group/repo", SubGroup: "group"}},
+		{platform: "local", repo: &LocalVcs{RepoPath: "repo", RepoName: "repo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			opts := Options{SSH: true}
+			if r, ok := tt.repo.(interface{ setOptions(Options) }); ok {
+				r.setOptions(opts)
+			}
+			snapshot, err := NewSnapshot(tt.platform, tt.repo)
+			if err != nil {
+				t.Fatalf("NewSnapshot() error = %v", err)
+			}
+			data, err := json.Marshal(snapshot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded Snapshot
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			restored, err := decoded.Restore(opts)
+			if err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+			if !reflect.DeepEqual(restored, tt.repo) {
+				t.Errorf("Restore() = %+v, 期望 %+v", restored, tt.repo)
+			}
+		})
+	}
+
+	if _, err := (&Snapshot{Platform: "svn", Repo: json.RawMessage("{}")}).Restore(Options{}); err == nil {
+		t.Error("不支持的平台期望返回错误")
+	}
+}
+
+// TestSnapshotWithoutCredentials 测试快照不包含认证信息
+func TestSnapshotWithoutCredentials(t *testing.T) {
+	repo := &GitlabVcs{httpURL: "https://gitlab.example.com/group/repo.git", RepoPath: "group/repo"}
+	snapshot, err := NewSnapshot("gitlab", repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(snapshot)
+	if strings.Contains(string(data), "@") {
+		t.Errorf("快照不应包含认证信息: %s", data)
+	}
+}